@@ -0,0 +1,231 @@
+/*
+	Helpers for checking and managing users, roles and privileges
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// HasPrivilege returns whether the current user has the privilege (e.g.
+// SELECT or INSERT) on the object which is either a schema or a
+// schema.table. The privilege may come from a grant on the object or its
+// schema (directly or via roles), owning it or an ANY system privilege.
+// If object is empty priv is checked as a system privilege (e.g. CREATE SESSION).
+func (c *Conn) HasPrivilege(object, priv string) (bool, error) {
+	priv = strings.ToUpper(strings.TrimSpace(priv))
+	if object == "" {
+		return c.Exists(
+			"SELECT 1 FROM exa_session_privs WHERE privilege = ?",
+			[]interface{}{priv},
+		)
+	}
+
+	schema, name := splitObjectName(object)
+	objPrivs := `
+		SELECT 1 FROM %s WHERE privilege = ? AND (
+			(object_schema = ? AND object_name = ?) OR
+			(object_type = 'SCHEMA' AND object_name = ?)
+		)`
+	sql := "SELECT 1 FROM dual WHERE " +
+		"EXISTS (SELECT 1 FROM exa_session_privs WHERE privilege LIKE ?) OR " +
+		"EXISTS (" + fmt.Sprintf(objPrivs, "exa_user_obj_privs") + ") OR " +
+		"EXISTS (" + fmt.Sprintf(objPrivs, "exa_role_obj_privs") + ") OR " +
+		"EXISTS (SELECT 1 FROM exa_user_objects WHERE " +
+		"(object_type = 'SCHEMA' AND object_name = ?) OR (root_name = ? AND object_name = ?))"
+	binds := []interface{}{
+		priv + " ANY %",
+		priv, schema, name, schema,
+		priv, schema, name, schema,
+		schema, schema, name,
+	}
+	return c.Exists(sql, binds)
+}
+
+// RequirePrivilege is like HasPrivilege but returns an error naming
+// the missing privilege so that callers can fail fast
+func (c *Conn) RequirePrivilege(object, priv string) error {
+	ok, err := c.HasPrivilege(object, priv)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if object == "" {
+			return fmt.Errorf("User %s lacks the %s system privilege", c.Conf.Username, priv)
+		}
+		return fmt.Errorf("User %s lacks the %s privilege on %s", c.Conf.Username, priv, object)
+	}
+	return nil
+}
+
+// CurrentRoles returns the roles the current user has
+// (directly or via other roles) including PUBLIC
+func (c *Conn) CurrentRoles() ([]string, error) {
+	rows, err := c.FetchSlice(`
+		SELECT granted_role FROM exa_user_role_privs
+		UNION SELECT granted_role FROM exa_role_role_privs
+		UNION SELECT 'PUBLIC' FROM dual
+	`)
+	if err != nil {
+		return nil, err
+	}
+	roles := make([]string, len(rows))
+	for i, row := range rows {
+		roles[i], _ = row[0].(string)
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// CreateUser creates a user identified by a password
+func (c *Conn) CreateUser(name, password string) error {
+	_, err := c.Execute(fmt.Sprintf(
+		"CREATE USER %s IDENTIFIED BY %s", c.QuoteIdent(name), quotePassword(password),
+	))
+	return err
+}
+
+// AlterUserPassword changes a user's password. Users changing their own
+// password must also provide their old one.
+func (c *Conn) AlterUserPassword(name, newPassword string, oldPassword ...string) error {
+	sql := fmt.Sprintf(
+		"ALTER USER %s IDENTIFIED BY %s", c.QuoteIdent(name), quotePassword(newPassword),
+	)
+	if len(oldPassword) > 0 {
+		sql += " REPLACE " + quotePassword(oldPassword[0])
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// DropUser drops a user. With cascade any objects it owns are dropped too.
+func (c *Conn) DropUser(name string, cascade bool) error {
+	return c.drop("USER", name, cascade)
+}
+
+func (c *Conn) CreateRole(name string) error {
+	_, err := c.Execute("CREATE ROLE " + c.QuoteIdent(name))
+	return err
+}
+
+// DropRole drops a role. With cascade it's revoked from all users/roles too.
+func (c *Conn) DropRole(name string, cascade bool) error {
+	return c.drop("ROLE", name, cascade)
+}
+
+// Grant grants the privileges (e.g. SELECT, INSERT) on the object (a
+// schema or schema.table) to the users/roles. If the object is empty the
+// privileges are granted as system privileges (e.g. CREATE SESSION).
+func (c *Conn) Grant(privs []string, object string, grantees ...string) error {
+	return c.grantOrRevoke("GRANT", "TO", privs, object, grantees, false)
+}
+
+// Revoke is the reverse of Grant
+func (c *Conn) Revoke(privs []string, object string, grantees ...string) error {
+	return c.grantOrRevoke("REVOKE", "FROM", privs, object, grantees, false)
+}
+
+// GrantRole grants a role to the users/roles
+func (c *Conn) GrantRole(role string, grantees ...string) error {
+	return c.grantOrRevoke("GRANT", "TO", nil, role, grantees, true)
+}
+
+// RevokeRole revokes a role from the users/roles
+func (c *Conn) RevokeRole(role string, grantees ...string) error {
+	return c.grantOrRevoke("REVOKE", "FROM", nil, role, grantees, true)
+}
+
+/*--- Private Routines ---*/
+
+var privilegeRE = regexp.MustCompile(`^[A-Za-z]+( [A-Za-z]+)*$`)
+
+// Passwords are quoted like identifiers (but never upper-cased)
+func quotePassword(password string) string {
+	return `"` + strings.ReplaceAll(password, `"`, `""`) + `"`
+}
+
+// Quotes each part of a schema[.name]
+func (c *Conn) quoteObject(object string) string {
+	parts := strings.SplitN(object, ".", 2)
+	for i, p := range parts {
+		parts[i] = c.QuoteIdent(strings.TrimSpace(p))
+	}
+	return strings.Join(parts, ".")
+}
+
+func (c *Conn) drop(kind, name string, cascade bool) error {
+	sql := fmt.Sprintf("DROP %s %s", kind, c.QuoteIdent(name))
+	if cascade {
+		sql += " CASCADE"
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// With isRole the object is a role being granted/revoked
+func (c *Conn) grantOrRevoke(
+	verb, prep string, privs []string, object string, grantees []string, isRole bool,
+) error {
+	if len(grantees) == 0 {
+		return c.errorf("%s requires at least one grantee", verb)
+	}
+	quoted := make([]string, len(grantees))
+	for i, g := range grantees {
+		quoted[i] = c.QuoteIdent(g)
+	}
+
+	var what string
+	if isRole {
+		if object == "" {
+			return c.errorf("%s requires a role", verb)
+		}
+		what = c.QuoteIdent(object)
+	} else {
+		if len(privs) == 0 {
+			return c.errorf("%s requires at least one privilege", verb)
+		}
+		for _, p := range privs {
+			if !privilegeRE.MatchString(strings.TrimSpace(p)) {
+				return c.errorf("Invalid privilege: %q", p)
+			}
+		}
+		what = strings.ToUpper(strings.Join(privs, ", "))
+		if object != "" {
+			what += " ON " + c.quoteObject(object)
+		}
+	}
+
+	_, err := c.Execute(fmt.Sprintf(
+		"%s %s %s %s", verb, what, prep, strings.Join(quoted, ", "),
+	))
+	return err
+}
+
+// Splits a schema[.name] into its parts as they're stored in the system
+// tables (see storedPart)
+func splitObjectName(object string) (schema, name string) {
+	parts := strings.SplitN(object, ".", 2)
+	for i, p := range parts {
+		parts[i] = storedPart(strings.TrimSpace(p))
+	}
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
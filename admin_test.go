@@ -0,0 +1,83 @@
+package exasol
+
+func (s *testSuite) TestPrivileges() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id INT )`)
+
+	ok, err := exa.HasPrivilege(s.qschema+".foo", "select")
+	s.NoError(err)
+	s.True(ok, "SYS can select")
+	ok, err = exa.HasPrivilege("", "CREATE SESSION")
+	s.NoError(err)
+	s.True(ok, "System privilege")
+	ok, err = exa.HasPrivilege(s.qschema+".foo", "ASDF")
+	s.NoError(err)
+	s.False(ok, "Bogus privilege")
+
+	s.NoError(exa.RequirePrivilege(s.qschema, "INSERT"))
+	err = exa.RequirePrivilege(s.qschema+".foo", "ASDF")
+	if s.Error(err) {
+		s.Contains(err.Error(), "lacks the ASDF privilege on")
+	}
+
+	roles, err := exa.CurrentRoles()
+	s.NoError(err)
+	s.Contains(roles, "DBA")
+	s.Contains(roles, "PUBLIC")
+
+	schema, name := splitObjectName(`[my schema].foo`)
+	s.Equal("MY SCHEMA", schema)
+	s.Equal("FOO", name)
+	schema, name = splitObjectName(`"my schema"."foo"`)
+	s.Equal("my schema", schema)
+	s.Equal("foo", name)
+	schema, name = splitObjectName(`test`)
+	s.Equal("TEST", schema)
+	s.Equal("", name)
+}
+
+func (s *testSuite) TestUserManagement() {
+	exa := s.exaConn
+	exa.Conf.SuppressError = true
+	exa.DropUser("test_user", true)
+	exa.DropRole("test_role", true)
+	defer exa.DropUser("test_user", true)
+	defer exa.DropRole("test_role", true)
+
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	password := `it's "quoted"`
+	s.NoError(exa.CreateUser("test_user", password))
+	s.NoError(exa.CreateRole("test_role"))
+	s.NoError(exa.Grant([]string{"create session"}, "", "test_user"))
+	s.NoError(exa.Grant([]string{"SELECT", "INSERT"}, s.qschema+".foo", "test_role"))
+	s.NoError(exa.GrantRole("test_role", "test_user"))
+
+	conf := s.connConf()
+	conf.Username = "test_user"
+	conf.Password = password
+	user, err := Connect(conf)
+	if s.NoError(err, "Password quoted correctly") {
+		ok, err := user.HasPrivilege(s.qschema+".foo", "INSERT")
+		s.NoError(err)
+		s.True(ok, "Via role")
+		ok, err = user.HasPrivilege(s.qschema+".foo", "DELETE")
+		s.NoError(err)
+		s.False(ok)
+		roles, _ := user.CurrentRoles()
+		s.Equal([]string{"PUBLIC", "TEST_ROLE"}, roles)
+
+		s.NoError(user.AlterUserPassword("test_user", "new", password))
+		user.Disconnect()
+	}
+
+	s.NoError(exa.Revoke([]string{"INSERT"}, s.qschema+".foo", "test_role"))
+	s.NoError(exa.RevokeRole("test_role", "test_user"))
+	err = exa.Grant([]string{"SELECT; DROP"}, "foo", "test_user")
+	if s.Error(err) {
+		s.Contains(err.Error(), "Invalid privilege")
+	}
+	s.Error(exa.Grant([]string{"SELECT"}, "foo"), "No grantees")
+
+	s.Equal(`ALTER USER u IDENTIFIED BY <redacted> REPLACE <redacted>`,
+		redactSQL(`ALTER USER u IDENTIFIED BY "a""b" REPLACE 'c''d'`), "Not logged")
+}
@@ -111,6 +111,21 @@ type AuthData struct {
 	TimeZoneBehavior      string  `json:"timeZoneBehavior"`
 }
 
+type getHostsReq struct {
+	Command string `json:"command"`
+	HostIp  string `json:"hostIp"`
+}
+
+type getHostsRes struct {
+	response
+	ResponseData *hostsData `json:"responseData"`
+}
+
+type hostsData struct {
+	NumNodes int      `json:"numNodes"`
+	Nodes    []string `json:"nodes"`
+}
+
 type execReq struct {
 	Command    string      `json:"command"`
 	Attributes *Attributes `json:"attributes,omitempty"`
@@ -130,6 +145,8 @@ type execPrepStmt struct {
 type execRes struct {
 	response
 	ResponseData *execData `json:"responseData"`
+
+	numbers bool // See decodeJSON
 }
 
 type execData struct {
@@ -150,6 +167,9 @@ type resultSet struct {
 	NumRowsInMessage int             `json:"numRowsInMessage"`
 	Columns          []column        `json:"columns"`
 	Data             [][]interface{} `json:"data"`
+
+	// Set by resultsToChan (before closing the chan) if fetching failed
+	fetchErr error
 }
 
 type column struct {
@@ -164,7 +184,7 @@ type DataType struct {
 	Precision         int    `json:"precision"`
 	Scale             int    `json:"scale"`
 	Size              int    `json:"size"`
-	CharacterSet      string `json:"characterSet,omitempty"`
+	CharacterSet      string `json:"characterSet,omitempty"` // ASCII or UTF8 for CHAR/VARCHAR
 	WithLocalTimeZone bool   `json:"withLocalTimeZone,omitempty"`
 	Fraction          int    `json:"fraction,omitempty"`
 	SRId              int    `json:"srid,omitempty"`
@@ -181,6 +201,8 @@ type fetchReq struct {
 type fetchRes struct {
 	response
 	ResponseData *fetchData `json:"responseData"`
+
+	numbers bool // See decodeJSON
 }
 
 type fetchData struct {
@@ -188,6 +210,12 @@ type fetchData struct {
 	Data    [][]interface{} `json:"data"`
 }
 
+// Result data is only decoded with numbers as json.Number when they're
+// to be converted according to their column's data type without any loss
+// of precision (see Conn.decodeData) since it's much slower than float64s
+func (r *execRes) useNumber() bool  { return r.numbers }
+func (r *fetchRes) useNumber() bool { return r.numbers }
+
 type closeResultSet struct {
 	Command          string      `json:"command"`
 	Attributes       *Attributes `json:"attributes,omitempty"`
@@ -0,0 +1,147 @@
+/*
+	Retrieval of the audit log (EXA_DBA_AUDIT_SQL & EXA_DBA_AUDIT_SESSIONS)
+
+	Auditing must be enabled for the database and the user needs
+	the SELECT ANY DICTIONARY privilege to read these views.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"iter"
+	"strings"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// AuditFilter restricts the audit records returned. Zero values are ignored.
+type AuditFilter struct {
+	From      time.Time // Inclusive
+	To        time.Time // Exclusive
+	User      string    // As stored i.e. upper-cased unless created quoted
+	SessionID uint64
+	Limit     int
+}
+
+// AuditEntry is a statement from EXA_DBA_AUDIT_SQL
+// along with who ran it from EXA_DBA_AUDIT_SESSIONS
+type AuditEntry struct {
+	SessionID    uint64
+	StmtID       uint64
+	UserName     string
+	Client       string
+	Host         string
+	OSUser       string
+	CommandName  string
+	CommandClass string
+	StartTime    time.Time
+	StopTime     time.Time
+	Duration     float64 // Seconds
+	RowCount     *int64
+	Success      bool
+	ErrorCode    *string
+	ErrorText    *string
+	ScopeSchema  *string
+	SQLText      string `exasol:"sql_text"`
+}
+
+// AuditSession is a session from EXA_DBA_AUDIT_SESSIONS
+type AuditSession struct {
+	SessionID  uint64
+	LoginTime  time.Time
+	LogoutTime *time.Time // Nil while the session is open
+	UserName   string
+	Client     string
+	Driver     string
+	Host       string
+	OSUser     string
+	Success    bool
+	ErrorCode  *string
+	ErrorText  *string
+}
+
+// AuditEntries returns the audited statements matching the filter ordered by start time
+func (c *Conn) AuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	where, binds := filter.where("q.start_time", "s.user_name", "q.session_id")
+	sql := `
+		SELECT CAST(q.session_id AS VARCHAR(20)) AS session_id, q.stmt_id,
+			s.user_name, s.client, s.host, s.os_user,
+			q.command_name, q.command_class, q.start_time, q.stop_time,
+			q.duration, q.row_count, q.success, q.error_code, q.error_text,
+			q.scope_schema, q.sql_text
+		FROM exa_dba_audit_sql q
+		JOIN exa_dba_audit_sessions s ON s.session_id = q.session_id
+	` + where + " ORDER BY q.start_time, q.session_id, q.stmt_id" + filter.limit()
+	return collect(QueryStruct[AuditEntry](c, sql, binds))
+}
+
+// AuditSessions returns the audited logins matching the filter ordered by login time
+func (c *Conn) AuditSessions(filter AuditFilter) ([]AuditSession, error) {
+	where, binds := filter.where("login_time", "user_name", "session_id")
+	sql := `
+		SELECT CAST(session_id AS VARCHAR(20)) AS session_id, login_time,
+			logout_time, user_name, client, driver, host, os_user,
+			success, error_code, error_text
+		FROM exa_dba_audit_sessions
+	` + where + " ORDER BY login_time, session_id" + filter.limit()
+	return collect(QueryStruct[AuditSession](c, sql, binds))
+}
+
+/*--- Private Routines ---*/
+
+func (f AuditFilter) where(timeCol, userCol, sessionCol string) (string, []interface{}) {
+	var conds []string
+	var binds []interface{}
+	if !f.From.IsZero() {
+		conds = append(conds, timeCol+" >= ?")
+		binds = append(binds, f.From)
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, timeCol+" < ?")
+		binds = append(binds, f.To)
+	}
+	if f.User != "" {
+		conds = append(conds, userCol+" = ?")
+		binds = append(binds, f.User)
+	}
+	if f.SessionID != 0 {
+		// Compared as strings as session ids don't fit in a float64
+		conds = append(conds, "CAST("+sessionCol+" AS VARCHAR(20)) = ?")
+		binds = append(binds, fmt.Sprint(f.SessionID))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return "WHERE " + strings.Join(conds, " AND "), binds
+}
+
+// Gathers up the rows from QueryStruct
+func collect[T any](rows iter.Seq2[T, error]) ([]T, error) {
+	var ret []T
+	for row, err := range rows {
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, row)
+	}
+	return ret, nil
+}
+
+func (f AuditFilter) limit() string {
+	if f.Limit > 0 {
+		return fmt.Sprintf(" LIMIT %d", f.Limit)
+	}
+	return ""
+}
@@ -0,0 +1,30 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestAuditEntries() {
+	exa := s.exaConn
+	start := time.Now().UTC().Add(-time.Minute)
+	s.execute("SELECT 'audit me' FROM dual")
+
+	entries, err := exa.AuditEntries(AuditFilter{
+		From:      start,
+		User:      "SYS",
+		SessionID: exa.SessionID,
+	})
+	if !s.NoError(err) {
+		return
+	}
+	// Auditing may not be enabled for the test database
+	for _, e := range entries {
+		s.Equal(exa.SessionID, e.SessionID)
+		s.Equal("SYS", e.UserName)
+		s.False(e.StartTime.Before(start.Truncate(time.Second)))
+	}
+
+	sessions, err := exa.AuditSessions(AuditFilter{SessionID: exa.SessionID, Limit: 1})
+	if s.NoError(err) && len(sessions) > 0 {
+		s.Equal(exa.SessionID, sessions[0].SessionID)
+		s.Nil(sessions[0].LogoutTime, "Still logged in")
+	}
+}
@@ -0,0 +1,37 @@
+package benchmarks
+
+import (
+	"bytes"
+	"testing"
+)
+
+func BenchmarkBulkInsert(b *testing.B) {
+	conn := setup(b)
+	b.SetBytes(int64(len(data.csv)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		truncateSink(b, conn)
+		if err := conn.BulkInsert(schema, "sink", bytes.NewBuffer(data.csv)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamSelect(b *testing.B) {
+	conn := setup(b)
+	b.SetBytes(int64(len(data.csv)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		rows := conn.StreamSelect(schema, "rows")
+		var n int
+		for chunk := range rows.Data {
+			n += len(chunk)
+			rows.Pool.Put(chunk)
+		}
+		if rows.Error != nil {
+			b.Fatal(rows.Error)
+		} else if n != len(data.csv) {
+			b.Fatalf("Exported %d bytes but expected %d", n, len(data.csv))
+		}
+	}
+}
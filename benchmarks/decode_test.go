@@ -0,0 +1,48 @@
+package benchmarks
+
+import (
+	"io"
+	"testing"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+// The cost of decoding the JSON results into Go values with each of
+// the number options compared to writing them straight out as CSV
+// (which skips the decoding). The difference is the decoding cost.
+func BenchmarkDecode(b *testing.B) {
+	for _, mode := range []struct {
+		name string
+		set  func(*exasol.ConnConf)
+	}{
+		{"Float64", func(*exasol.ConnConf) {}},
+		{"TypedInts", func(c *exasol.ConnConf) { c.TypedInts = true }},
+		{"UseNumber", func(c *exasol.ConnConf) { c.UseNumber = true }},
+	} {
+		b.Run(mode.name, func(b *testing.B) {
+			conf := connConf()
+			mode.set(&conf)
+			conn := connect(b, conf)
+			b.SetBytes(int64(len(data.csv)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ch, err := conn.FetchChan(fetchSQL)
+				if err != nil {
+					b.Fatal(err)
+				}
+				drain(b, ch)
+			}
+		})
+	}
+
+	b.Run("Undecoded", func(b *testing.B) {
+		conn := setup(b)
+		b.SetBytes(int64(len(data.csv)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := conn.FetchToCSV(fetchSQL, io.Discard, exasol.CSVOpts{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+/*
+	Package benchmarks holds reproducible benchmarks of the client's
+	fetch throughput, prepared statement inserts, bulk IMPORTs/EXPORTs and
+	the cost of decoding the JSON results so that performance-oriented
+	changes (e.g. compression, codecs or prefetching) can be evaluated.
+
+	Like the main test suite they assume there is a local Exasol instance
+	listening on port 8563 with the default sys password which you can
+	override via the --host, --port and --pass arguments. We recommend
+	using an Exasol docker container for this:
+		https://github.com/exasol/docker-db
+
+	The data is generated deterministically in the BENCH schema (which is
+	dropped and recreated) with --rows rows (100000 by default). Run them via:
+		go test ./benchmarks -run '^$' -bench . -count 10 -args -rows 100000
+
+	To guard against regressions save the output from before and after a
+	change and compare the two with benchstat (golang.org/x/perf):
+		benchstat before.txt after.txt
+
+	Benchmarks are skipped if Exasol can't be connected to.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package benchmarks
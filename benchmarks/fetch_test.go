@@ -0,0 +1,47 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+const fetchSQL = "SELECT * FROM " + schema + ".rows"
+
+func BenchmarkFetchChan(b *testing.B) {
+	for _, mb := range []int{1, 8, 64} {
+		b.Run(fmt.Sprintf("MaxFetchBytes=%dMB", mb), func(b *testing.B) {
+			conn := setup(b)
+			opts := exasol.FetchOpts{MaxFetchBytes: mb * 1024 * 1024}
+			b.SetBytes(int64(len(data.csv)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ch, err := conn.FetchChan(fetchSQL, nil, nil, opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				drain(b, ch)
+			}
+		})
+	}
+}
+
+// Prefetch starts with a small fetch for latency and grows from there
+func BenchmarkFetchPrefetch(b *testing.B) {
+	for _, kb := range []int{0, 64, 1024} {
+		b.Run(fmt.Sprintf("Prefetch=%dKB", kb), func(b *testing.B) {
+			conn := setup(b)
+			opts := exasol.FetchOpts{Prefetch: kb * 1024}
+			b.SetBytes(int64(len(data.csv)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ch, err := conn.FetchChan(fetchSQL, nil, nil, opts)
+				if err != nil {
+					b.Fatal(err)
+				}
+				drain(b, ch)
+			}
+		})
+	}
+}
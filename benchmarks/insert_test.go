@@ -0,0 +1,35 @@
+package benchmarks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+const insertSQL = "INSERT INTO " + schema + ".sink VALUES (?, ?, ?, ?, ?)"
+
+// Inserting via a prepared statement in batches of various sizes
+func BenchmarkPreparedInsert(b *testing.B) {
+	for _, size := range []int{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("Batch=%d", size), func(b *testing.B) {
+			conn := setup(b)
+			rows := make([][]interface{}, size)
+			start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+			for i := range rows {
+				rows[i] = []interface{}{
+					i, float64(i) / 100, fmt.Sprintf("name %d", i),
+					start.Add(time.Duration(i) * time.Second), i%2 == 0,
+				}
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				truncateSink(b, conn)
+				if _, err := conn.Execute(insertSQL, rows); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(size*b.N)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
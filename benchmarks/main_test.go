@@ -0,0 +1,120 @@
+/*
+	The routines in this file are shared by all the benchmark files.
+	There aren't any actual benchmarks in this file.
+*/
+
+package benchmarks
+
+import (
+	"bytes"
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+var benchHost = flag.String("host", "127.0.0.1", "Exasol hostname")
+var benchPort = flag.Int("port", 8563, "Exasol port")
+var benchPass = flag.String("pass", "exasol", "Exasol SYS password")
+var benchRows = flag.Int("rows", 100000, "Rows of generated data")
+
+const schema = "bench"
+
+// The generated data which every benchmark shares
+var data struct {
+	once  sync.Once
+	conn  *exasol.Conn
+	err   error
+	csv   []byte // The ROWS table as exported
+	count int
+}
+
+func connConf() exasol.ConnConf {
+	return exasol.ConnConf{
+		Host:          *benchHost,
+		Port:          uint16(*benchPort),
+		Username:      "SYS",
+		Password:      *benchPass,
+		TLSConfig:     &tls.Config{InsecureSkipVerify: true},
+		SuppressError: true,
+	}
+}
+
+// Returns the shared connection generating the data the first time
+// it's called. The benchmark is skipped if Exasol is unavailable.
+func setup(b *testing.B) *exasol.Conn {
+	data.once.Do(func() {
+		data.conn, data.err = exasol.Connect(connConf())
+		if data.err != nil {
+			return
+		}
+		data.err = generate(data.conn)
+	})
+	if data.conn == nil {
+		b.Skip("Unable to connect to Exasol: ", data.err)
+	} else if data.err != nil {
+		b.Fatal("Unable to generate the data: ", data.err)
+	}
+	return data.conn
+}
+
+// Connects a separate connection (e.g. with different options)
+func connect(b *testing.B, conf exasol.ConnConf) *exasol.Conn {
+	setup(b)
+	conn, err := exasol.Connect(conf)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(conn.Disconnect)
+	return conn
+}
+
+func generate(conn *exasol.Conn) error {
+	for _, sql := range []string{
+		"DROP SCHEMA IF EXISTS " + schema + " CASCADE",
+		"CREATE SCHEMA " + schema,
+		"CREATE TABLE " + schema + `.rows (
+			id DECIMAL(18,0), amount DECIMAL(12,2), name VARCHAR(50),
+			created TIMESTAMP, flag BOOLEAN
+		)`,
+		"CREATE TABLE " + schema + ".sink LIKE " + schema + ".rows",
+		fmt.Sprintf(`INSERT INTO %s.rows
+			SELECT i, i / 100, 'name ' || i,
+				ADD_SECONDS(TIMESTAMP '2020-01-01 00:00:00', i), MOD(i, 2) = 0
+			FROM VALUES BETWEEN 1 AND %d AS v(i)`, schema, *benchRows),
+	} {
+		if _, err := conn.Execute(sql); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	if err := conn.BulkSelect(schema, "rows", &buf); err != nil {
+		return err
+	}
+	data.csv = buf.Bytes()
+	data.count = *benchRows
+	return nil
+}
+
+// Empties the sink table outside of the timings
+func truncateSink(b *testing.B, conn *exasol.Conn) {
+	b.StopTimer()
+	defer b.StartTimer()
+	if _, err := conn.Execute("TRUNCATE TABLE " + schema + ".sink"); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// Drains the chan checking that all the rows arrived
+func drain(b *testing.B, ch <-chan []interface{}) {
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != data.count {
+		b.Fatalf("Fetched %d rows but expected %d", n, data.count)
+	}
+}
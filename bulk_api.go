@@ -56,27 +56,70 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// BulkResult details a bulk import
+type BulkResult struct {
+	BytesWritten int64
+	Chunks       int64 // The number of []byte chunks sent
+	Duration     time.Duration
+	Retries      int
+}
+
 func (c *Conn) BulkInsert(schema, table string, data *bytes.Buffer) (err error) {
+	_, err = c.BulkInsertResult(schema, table, data)
+	return err
+}
+
+// BulkInsertResult is the same as BulkInsert but also returns a BulkResult
+func (c *Conn) BulkInsertResult(schema, table string, data *bytes.Buffer) (*BulkResult, error) {
+	if schema == "" || table == "" {
+		return nil, fmt.Errorf("You must pass in a schema and table to BulkInsert")
+	}
 	sql := c.getTableImportSQL(schema, table)
-	return c.BulkExecute(sql, data)
+	return c.BulkExecuteResult(sql, data)
 }
 
 func (c *Conn) BulkExecute(sql string, data *bytes.Buffer) error {
+	_, err := c.BulkExecuteResult(sql, data)
+	return err
+}
+
+// BulkExecuteResult is the same as BulkExecute but also returns a BulkResult
+func (c *Conn) BulkExecuteResult(sql string, data *bytes.Buffer) (*BulkResult, error) {
 	if data == nil {
-		return fmt.Errorf("You must pass in a bytes.Buffer pointer to BulkExecute")
+		return nil, fmt.Errorf("You must pass in a bytes.Buffer pointer to BulkExecute")
+	}
+	if strings.TrimSpace(sql) == "" {
+		return nil, fmt.Errorf("You must pass in an IMPORT statement to BulkExecute")
 	}
 	dataChan := make(chan []byte, 1)
 	dataChan <- data.Bytes()
 	close(dataChan)
-	return c.StreamExecute(sql, dataChan)
+	return c.StreamExecuteResult(sql, dataChan)
+}
+
+// ExportOpts can be passed in as the optional last arg to BulkSelect/StreamSelect
+type ExportOpts struct {
+	// Include a header row of the column names (WITH COLUMN NAMES)
+	WithColumnNames bool
+	// The FILE name in the generated EXPORT. Defaults to data.csv.
+	FileName string
+	// Describe the table's columns into Rows.Columns before exporting.
+	// This is ignored by BulkSelect (see DescribeQuery).
+	Describe bool
 }
 
-func (c *Conn) BulkSelect(schema, table string, data *bytes.Buffer) (err error) {
-	sql := c.getTableExportSQL(schema, table)
+func (c *Conn) BulkSelect(schema, table string, data *bytes.Buffer, opts ...ExportOpts) (err error) {
+	if schema == "" || table == "" {
+		return fmt.Errorf("You must pass in a schema and table to BulkSelect")
+	}
+	sql := c.getTableExportSQL(schema, table, opts...)
 	return c.BulkQuery(sql, data)
 }
 
@@ -84,6 +127,9 @@ func (c *Conn) BulkQuery(sql string, data *bytes.Buffer) error {
 	if data == nil {
 		return fmt.Errorf("You must pass in a bytes.Buffer pointer to BulkQuery")
 	}
+	if strings.TrimSpace(sql) == "" {
+		return fmt.Errorf("You must pass in an EXPORT statement to BulkQuery")
+	}
 	rows := c.StreamQuery(sql)
 	for b := range rows.Data {
 		data.Write(b)
@@ -95,18 +141,44 @@ func (c *Conn) BulkQuery(sql string, data *bytes.Buffer) error {
 }
 
 func (c *Conn) StreamInsert(schema, table string, data <-chan []byte) (err error) {
+	_, err = c.StreamInsertResult(schema, table, data)
+	return err
+}
+
+// StreamInsertResult is the same as StreamInsert but also returns a BulkResult
+func (c *Conn) StreamInsertResult(schema, table string, data <-chan []byte) (*BulkResult, error) {
+	if schema == "" || table == "" {
+		return nil, fmt.Errorf("You must pass in a schema and table to StreamInsert")
+	}
 	sql := c.getTableImportSQL(schema, table)
-	return c.StreamExecute(sql, data)
+	return c.StreamExecuteResult(sql, data)
 }
 
 func (c *Conn) StreamExecute(origSQL string, data <-chan []byte) error {
+	_, err := c.StreamExecuteResult(origSQL, data)
+	return err
+}
+
+// StreamExecuteResult is the same as StreamExecute but also returns a
+// BulkResult. The result is returned (as far as it got) even upon error.
+func (c *Conn) StreamExecuteResult(origSQL string, data <-chan []byte) (*BulkResult, error) {
 	if data == nil {
-		return fmt.Errorf("You must pass in a []byte chan to StreamExecute")
+		return nil, fmt.Errorf("You must pass in a []byte chan to StreamExecute")
+	}
+	if strings.TrimSpace(origSQL) == "" {
+		return nil, fmt.Errorf("You must pass in an IMPORT statement to StreamExecute")
 	}
 
+	start := time.Now()
+	result := &BulkResult{}
+	var err error
 	// Retry twice cuz it seems we sometimes get sentient errors
-	for range []int{1, 2} {
-		bytesWritten, err := c.streamExecuteNoRetry(origSQL, data)
+	for try := range []int{1, 2} {
+		var bytesWritten, chunks int64
+		bytesWritten, chunks, err = c.streamExecuteNoRetry(origSQL, data)
+		result.BytesWritten += bytesWritten
+		result.Chunks += chunks
+		result.Retries = try
 		if err != nil {
 			if retryableError(err) {
 				if bytesWritten == 0 {
@@ -118,16 +190,280 @@ func (c *Conn) StreamExecute(origSQL string, data <-chan []byte) error {
 				c.error("Data already sent can't retry...")
 			}
 			c.error(err.Error())
-			return err
+			break
 		}
 		break
 	}
+	result.Duration = time.Since(start)
+	return result, err
+}
+
+// ImportFile is one of the files of a multi-file import
+type ImportFile struct {
+	Name string // The FILE name. Defaults to data_<n>.csv
+	Data <-chan []byte
+}
+
+// StreamInsertFiles imports the files into the table in parallel, each
+// through its own proxy, within a single IMPORT statement. Unlike
+// StreamInsert it doesn't retry.
+func (c *Conn) StreamInsertFiles(schema, table string, files []ImportFile) error {
+	if schema == "" || table == "" {
+		return fmt.Errorf("You must pass in a schema and table to StreamInsertFiles")
+	}
+	sql := fmt.Sprintf(
+		"IMPORT INTO %s.%s FROM CSV %%s",
+		c.QuoteIdent(schema), c.QuoteIdent(table),
+	)
+	return c.StreamExecuteFiles(sql, files)
+}
+
+// StreamExecuteFiles is the multi-file version of StreamExecute. Rather
+// than a single AT '%s' FILE '...' the IMPORT must contain a single %s
+// where the AT/FILE clauses for all of the files will be inserted, e.g.
+//
+//	IMPORT INTO foo FROM CSV %s COLUMN SEPARATOR = ';'
+func (c *Conn) StreamExecuteFiles(sql string, files []ImportFile) error {
+	if len(files) == 0 {
+		return fmt.Errorf("You must pass in at least one file to StreamExecuteFiles")
+	}
+	if strings.Count(sql, "%s") != 1 {
+		return fmt.Errorf("You must pass in an IMPORT statement with a single %%s to StreamExecuteFiles")
+	}
+	for i, f := range files {
+		if f.Data == nil {
+			return fmt.Errorf("You must pass in a []byte chan for file %d to StreamExecuteFiles", i)
+		}
+	}
+
+	proxies := make([]*Proxy, len(files))
+	defer func() {
+		for _, p := range proxies {
+			if p != nil {
+				c.releaseProxy(p)
+			}
+		}
+	}()
+	sources := make([]string, len(files))
+	for i, f := range files {
+		proxy, err := c.openProxy()
+		if err != nil {
+			return err
+		}
+		proxies[i] = proxy
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("data_%d.csv", i)
+		}
+		sources[i] = fmt.Sprintf("AT '%s' FILE '%s'", proxy.url(), QuoteStr(name))
+	}
+	sql = fmt.Sprintf(sql, strings.Join(sources, " "))
+
+	c.log.Debug("Stream sql: ", sql)
+	receiver, err := c.asyncSend(&execReq{Command: "execute", SqlText: c.rewriteSQL(sql)})
+	if err != nil {
+		return c.errorf("Unable to stream sql: %s %s", sql, err)
+	}
+
+	errs := make(chan error, len(files)+1)
+	for i, proxy := range proxies {
+		go func() {
+			_, err := proxy.Write(files[i].Data)
+			errs <- err
+		}()
+	}
+	go func() {
+		errs <- receiver(&response{})
+	}()
+
+	timeout := make(<-chan time.Time)
+	if c.Conf.QueryTimeout.Seconds() > 0 {
+		timeout = time.After(c.Conf.QueryTimeout)
+	}
+	for range len(files) + 1 {
+		select {
+		case err = <-errs:
+		case <-timeout:
+			err = fmt.Errorf("Timed out doing StreamExecuteFiles")
+		}
+		if err != nil {
+			return c.errorf("Unable to import data: %s\n%s", sql, err)
+		}
+	}
 	return nil
 }
 
-func (c *Conn) StreamSelect(schema, table string) *Rows {
-	sql := c.getTableExportSQL(schema, table)
-	return c.StreamQuery(sql)
+// LoadReport describes the last successful StreamExecute/BulkExecute
+// (and so StreamInsert/BulkInsert) on the Conn
+type LoadReport struct {
+	RowsRead     uint64 // CSV rows sent to the server
+	RowsInserted int64  // Rows affected as reported by the server
+	// RowsRead - RowsInserted. This is only meaningful for straight table
+	// imports where rows are rejected via REJECT LIMIT.
+	RowsRejected int64
+	// The error table from an ERRORS INTO clause, if any,
+	// where the details of the rejected rows can be found
+	ErrorTable   string
+	BytesWritten int64
+	Duration     time.Duration
+}
+
+// LastLoadReport returns the report from the last successful
+// bulk import or nil if there hasn't been one
+func (c *Conn) LastLoadReport() *LoadReport {
+	c.statsMux.Lock()
+	defer c.statsMux.Unlock()
+	return c.lastLoad
+}
+
+// The table StreamInsertOnce records its tokens in (within the target's schema)
+const LoadTokenTable = "EXA_CLIENT_LOAD_TOKENS"
+
+// StreamInsertOnce is an idempotent version of StreamInsert. The data is
+// first loaded into a staging table and only once that has fully succeeded
+// is it inserted into the target table in a single transaction. So a failed
+// call leaves the target untouched and can be safely retried.
+//
+// If a token is given it is recorded (in LoadTokenTable) in the same
+// transaction and subsequent calls with the same token skip the load
+// (returning false) which guards against double-loading when the
+// client didn't hear back about a load that actually committed.
+func (c *Conn) StreamInsertOnce(schema, table, token string, data <-chan []byte) (loaded bool, err error) {
+	if schema == "" || table == "" {
+		return false, fmt.Errorf("You must pass in a schema and table to StreamInsertOnce")
+	}
+	if data == nil {
+		return false, fmt.Errorf("You must pass in a []byte chan to StreamInsertOnce")
+	}
+	qSchema := c.QuoteIdent(schema)
+	tokenTable := qSchema + "." + LoadTokenTable
+	if token != "" {
+		_, err = c.Execute(fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s ( token VARCHAR(200), table_name VARCHAR(256), loaded_at TIMESTAMP )",
+			tokenTable,
+		))
+		if err != nil {
+			return false, err
+		}
+		exists, err := c.Exists(
+			"SELECT * FROM "+tokenTable+" WHERE token = ?", []interface{}{token},
+		)
+		if err != nil {
+			return false, err
+		}
+		if exists {
+			c.log.Infof("Load token %s already loaded, skipping", token)
+			for range data {
+			}
+			return false, nil
+		}
+	}
+
+	target := qSchema + "." + c.QuoteIdent(table)
+	stageName, err := c.CreateTempTableLike(schema, table)
+	if err != nil {
+		return false, err
+	}
+	defer c.DropTempTable(schema, stageName)
+	stage := qSchema + "." + c.QuoteIdent(stageName)
+
+	err = c.StreamExecute(fmt.Sprintf("IMPORT INTO %s FROM CSV AT '%%s' FILE 'data.csv'", stage), data)
+	if err != nil {
+		return false, err
+	}
+
+	// Record the token and move the staged rows atomically. The token is
+	// checked again within the transaction in case a concurrent call with
+	// the same token committed since the check above. (If they're both
+	// still running Exasol fails one of them with a transaction conflict.)
+	err = c.Transaction(func() error {
+		if token != "" {
+			n, err := c.Execute(fmt.Sprintf(
+				"INSERT INTO %s SELECT '%s', '%s', CURRENT_TIMESTAMP FROM DUAL"+
+					" WHERE NOT EXISTS (SELECT * FROM %s WHERE token = '%s')",
+				tokenTable, QuoteStr(token), QuoteStr(table), tokenTable, QuoteStr(token),
+			))
+			if err != nil {
+				return err
+			} else if n == 0 {
+				return errAlreadyLoaded
+			}
+		}
+		_, err := c.Execute(fmt.Sprintf("INSERT INTO %s SELECT * FROM %s", target, stage))
+		return err
+	})
+	if errors.Is(err, errAlreadyLoaded) {
+		c.log.Infof("Load token %s already loaded, skipping", token)
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (c *Conn) StreamSelect(schema, table string, opts ...ExportOpts) *Rows {
+	if schema == "" || table == "" {
+		return c.failedRows(fmt.Errorf("You must pass in a schema and table to StreamSelect"))
+	}
+	var cols []Column
+	if len(opts) > 0 && opts[0].Describe {
+		var err error
+		cols, err = c.DescribeQuery(fmt.Sprintf(
+			"SELECT * FROM %s.%s", c.QuoteIdent(schema), c.QuoteIdent(table),
+		))
+		if err != nil {
+			return c.failedRows(err)
+		}
+	}
+	sql := c.getTableExportSQL(schema, table, opts...)
+	r := c.StreamQuery(sql)
+	r.Columns = cols
+	r.header = len(opts) > 0 && opts[0].WithColumnNames
+	return r
+}
+
+// DescribeQuery returns the names and types of the columns
+// the given SELECT returns without fetching any of its rows.
+func (c *Conn) DescribeQuery(selectSQL string, args ...interface{}) ([]Column, error) {
+	rows, err := c.FetchRows("SELECT * FROM ("+selectSQL+") WHERE FALSE", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.ColumnTypes(), nil
+}
+
+// StreamSelectFrom is like StreamSelect but skips the first offset rows
+// (as sorted by orderBy) which allows an interrupted export to be resumed.
+// See Rows.Resume
+func (c *Conn) StreamSelectFrom(schema, table, orderBy string, offset uint64) *Rows {
+	if schema == "" || table == "" {
+		return c.failedRows(fmt.Errorf("You must pass in a schema and table to StreamSelectFrom"))
+	}
+	sql := fmt.Sprintf("SELECT * FROM %s.%s", c.QuoteIdent(schema), c.QuoteIdent(table))
+	return c.StreamQueryFrom(sql, orderBy, offset)
+}
+
+// StreamQueryFrom exports the results of the given SELECT statement
+// skipping the first offset rows. The orderBy clause must give the rows
+// a stable ordering so that the offset is meaningful across exports.
+func (c *Conn) StreamQueryFrom(selectSQL, orderBy string, offset uint64) *Rows {
+	if strings.TrimSpace(selectSQL) == "" || strings.TrimSpace(orderBy) == "" {
+		return c.failedRows(fmt.Errorf("You must pass in a SELECT statement and an ORDER BY to StreamQueryFrom"))
+	}
+	// OFFSET requires a LIMIT so the rows are skipped via QUALIFY instead
+	skip := ""
+	if offset > 0 {
+		skip = fmt.Sprintf(" QUALIFY ROW_NUMBER() OVER (ORDER BY %s) > %d", orderBy, offset)
+	}
+	exportSQL := fmt.Sprintf(
+		"EXPORT (SELECT * FROM (%s)%s ORDER BY %s) INTO CSV AT '%%s' FILE 'data.csv'",
+		selectSQL, skip, orderBy,
+	)
+	r := c.StreamQuery(exportSQL)
+	r.offset = offset
+	r.resume = func(offset uint64) *Rows {
+		return c.StreamQueryFrom(selectSQL, orderBy, offset)
+	}
+	return r
 }
 
 var bufPool = sync.Pool{
@@ -137,8 +473,15 @@ var bufPool = sync.Pool{
 }
 
 func (c *Conn) StreamQuery(exportSQL string) *Rows {
+	if strings.TrimSpace(exportSQL) == "" {
+		return c.failedRows(fmt.Errorf("You must pass in an EXPORT statement to StreamQuery"))
+	}
+	bufSize := c.Conf.StreamOpts.BufferSize
+	if bufSize < 1 {
+		bufSize = 1
+	}
 	r := &Rows{
-		Data: make(chan []byte, 1),
+		Data: make(chan []byte, bufSize),
 		Pool: &bufPool,
 		conn: c,
 		stop: make(chan bool, 1),
@@ -157,7 +500,7 @@ func (c *Conn) StreamQuery(exportSQL string) *Rows {
 		// errors when Exasol tries to connect to the internal proxy that it set up.
 		for i := 0; i <= 2; i++ {
 			r.Error = r.streamQuery(exportSQL)
-			if retryableError(r.Error) {
+			if retryableError(r.Error) && !r.stopping.Load() {
 				c.error("Retrying...")
 				r.Error = nil
 				continue
@@ -171,45 +514,206 @@ func (c *Conn) StreamQuery(exportSQL string) *Rows {
 
 type Rows struct {
 	BytesRead int64
+	RowsRead  uint64   // Complete CSV rows sent on Data. Only valid once Data is closed
+	Columns   []Column // Set if requested via ExportOpts.Describe
 	Data      chan []byte
 	Pool      *sync.Pool // Use this to return the []bytes
 	Error     error
 
-	conn  *Conn
-	proxy *Proxy
-	stop  chan bool
-	wg    sync.WaitGroup
+	conn     *Conn
+	counter  csvRowCounter
+	high     bool                      // Whether the high watermark has been reached
+	carry    []byte                    // The partial row held back when RowAlignedStreams
+	header   bool                      // Whether the data starts with a header row
+	offset   uint64                    // The offset this export started from
+	resume   func(offset uint64) *Rows // Set for exports that can be resumed
+	proxy    *Proxy
+	proxyMux sync.Mutex // Guards proxy which is set by the reading Go routine
+	stop     chan bool
+	stopping atomic.Bool // Suppresses errors from forcing it to stop
+	wg       sync.WaitGroup
 }
 
 func (r *Rows) Close() {
-	origCfg := r.conn.Conf.SuppressError
-	if r.proxy.IsRunning() {
-		// Suppress errors from forcing it to stop
-		r.conn.Conf.SuppressError = true
+	r.stopExport()
+	r.wg.Wait()
+}
+
+// ForEachParallel fans the chunks out to n Go routines calling fn with
+// each one and returning the chunk to the Pool afterwards (so fn must
+// copy anything it wants to retain). If fn returns an error the export is
+// stopped and that error is returned. Otherwise the export's Error is.
+func (r *Rows) ForEachParallel(n int, fn func([]byte) error) error {
+	if n < 1 {
+		n = 1
+	}
+	var firstErr error
+	var errOnce sync.Once
+	var workers sync.WaitGroup
+	var failed atomic.Bool
+	for i := 0; i < n; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for chunk := range r.Data {
+				if !failed.Load() {
+					if err := fn(chunk); err != nil {
+						errOnce.Do(func() {
+							firstErr = err
+							failed.Store(true)
+							r.stopExport()
+						})
+					}
+				}
+				// Keep draining so the reader isn't blocked
+				r.Pool.Put(chunk)
+			}
+		}()
+	}
+	workers.Wait()
+	r.wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return r.Error
+}
+
+// Resume re-issues an interrupted export (one started via StreamSelectFrom
+// or StreamQueryFrom) picking up after the last complete row received.
+// Any trailing partial row already received (i.e. after the last
+// newline) should be discarded by the caller.
+func (r *Rows) Resume() *Rows {
+	r.Close()
+	if r.resume == nil {
+		return r.conn.failedRows(fmt.Errorf("Only StreamSelectFrom and StreamQueryFrom exports can be resumed"))
+	}
+	return r.resume(r.offset + r.RowsRead)
+}
+
+/*--- Private Routines ---*/
+
+// Returned within StreamInsertOnce's transaction to roll it back
+var errAlreadyLoaded = errors.New("Already loaded")
+
+// Counts the complete CSV rows in each chunk (ignoring newlines within
+// quoted values) and, if configured, re-frames the chunks so that they
+// end on row boundaries.
+func (r *Rows) onRead(chunk []byte) []byte {
+	if chunk == nil {
+		// End of the data
+		rest := r.carry
+		r.carry = nil
+		return rest
+	}
+	r.checkWatermarks()
+	end := r.counter.count(chunk) // RowsRead is updated once it's sent (see onSent)
+	if !r.conn.Conf.RowAlignedStreams ||
+		(len(r.carry) == 0 && end == len(chunk)-1) {
+		return chunk
+	}
+
+	var out []byte
+	if end >= 0 {
+		out = r.Pool.Get().([]byte)[:0]
+		out = append(out, r.carry...)
+		out = append(out, chunk[:end+1]...)
+		r.carry = r.carry[:0]
+	}
+	r.carry = append(r.carry, chunk[end+1:]...)
+	r.Pool.Put(chunk)
+	return out
+}
+
+// Calls the StreamOpts watermark callbacks as the Data buffer fills/drains
+func (r *Rows) checkWatermarks() {
+	opts := r.conn.Conf.StreamOpts
+	if opts.OnHighWatermark == nil && opts.OnLowWatermark == nil {
+		return
+	}
+	high := opts.HighWatermark
+	if high < 1 {
+		high = cap(r.Data)
+	}
+	queued := len(r.Data)
+	if !r.high && queued >= high {
+		r.high = true
+		if opts.OnHighWatermark != nil {
+			opts.OnHighWatermark(r)
+		}
+	} else if r.high && queued <= opts.LowWatermark {
+		r.high = false
+		if opts.OnLowWatermark != nil {
+			opts.OnLowWatermark(r)
+		}
+	}
+}
+
+// Signals the reading Go routine to stop without waiting for it
+func (r *Rows) stopExport() {
+	r.proxyMux.Lock()
+	running := r.proxy != nil && r.proxy.IsRunning()
+	r.proxyMux.Unlock()
+	if running {
+		r.stopping.Store(true)
 		select {
 		case r.stop <- true:
 		default:
 		}
 	}
-	r.wg.Wait()
-	r.conn.Conf.SuppressError = origCfg
 }
 
-/*--- Private Routines ---*/
+// Counts CSV rows across a series of chunks
+type csvRowCounter struct {
+	rows    uint64
+	inQuote bool // Whether the last chunk ended within a quoted value
+}
+
+// Counts the complete rows in the chunk (ignoring newlines within quoted
+// values) and returns the index of the last row's newline (or -1 if none)
+func (rc *csvRowCounter) count(chunk []byte) int {
+	end := -1
+	for i, b := range chunk {
+		switch {
+		case b == '"':
+			rc.inQuote = !rc.inQuote
+		case b == '\n' && !rc.inQuote:
+			rc.rows++
+			end = i
+		}
+	}
+	return end
+}
+
+// Returns Rows with no data that just reports the error
+func (c *Conn) failedRows(err error) *Rows {
+	r := &Rows{
+		Data:  make(chan []byte),
+		Pool:  &bufPool,
+		Error: err,
+		conn:  c,
+		stop:  make(chan bool, 1),
+	}
+	close(r.Data)
+	return r
+}
 
 func (r *Rows) streamQuery(exportSQL string) error {
 	proxy, receiver, err := r.conn.initProxy(exportSQL)
 	if err != nil {
 		return err
 	}
+	r.proxyMux.Lock()
 	r.proxy = proxy
-	defer r.proxy.Shutdown()
+	r.proxyMux.Unlock()
+	defer r.conn.releaseProxy(proxy)
+	proxy.onRead = r.onRead
+	proxy.onSent = func() { r.RowsRead = r.counter.rows }
 
 	dataErr := make(chan error, 1)
 	respErr := make(chan error, 1)
 	go func() {
 		// This is a blocking reader of the CSV data
-		r.BytesRead, err = r.proxy.Read(r.Data, r.stop)
+		r.BytesRead, err = proxy.Read(r.Data, r.stop)
 		dataErr <- err
 	}()
 	go func() {
@@ -238,7 +742,7 @@ func (r *Rows) streamQuery(exportSQL string) error {
 
 	// If we purposefully prematurely closed the connection
 	// we don't want to raise any errors.
-	if err != nil {
+	if err != nil && !r.stopping.Load() {
 		r.conn.errorf("Unable to bulk export data: %s %s", exportSQL, err)
 	}
 
@@ -246,13 +750,20 @@ func (r *Rows) streamQuery(exportSQL string) error {
 }
 
 func (c *Conn) streamExecuteNoRetry(origSQL string, data <-chan []byte) (
-	bytesWritten int64, err error,
+	bytesWritten, chunks int64, err error,
 ) {
+	start := time.Now()
 	proxy, receiver, err := c.initProxy(origSQL)
 	if err != nil {
-		return 0, fmt.Errorf("Unable to import or export data: %s\n%s", origSQL, err)
+		return 0, 0, fmt.Errorf("Unable to import or export data: %s\n%s", origSQL, err)
+	}
+	defer c.releaseProxy(proxy)
+	var counter csvRowCounter
+	proxy.onWrite = func(chunk []byte) {
+		counter.count(chunk)
+		chunks++
 	}
-	defer proxy.Shutdown()
+	res := &execRes{}
 
 	dataErr := make(chan error, 1)
 	respErr := make(chan error, 1)
@@ -264,7 +775,7 @@ func (c *Conn) streamExecuteNoRetry(origSQL string, data <-chan []byte) (
 	}()
 	go func() {
 		// This returns the result of the IMPORT query
-		e := receiver(&response{})
+		e := receiver(res)
 		respErr <- e
 	}()
 
@@ -288,36 +799,93 @@ func (c *Conn) streamExecuteNoRetry(origSQL string, data <-chan []byte) (
 
 	if err != nil {
 		err = fmt.Errorf("Unable to import or export data: %s\n%s", origSQL, err)
+		return bytesWritten, chunks, err
 	}
 
-	return bytesWritten, err
+	report := &LoadReport{
+		RowsRead:     counter.rows,
+		BytesWritten: bytesWritten,
+		Duration:     time.Since(start),
+	}
+	if res.ResponseData != nil && len(res.ResponseData.Results) > 0 {
+		report.RowsInserted = res.ResponseData.Results[0].RowCount
+		report.RowsRejected = int64(counter.rows) - report.RowsInserted
+	}
+	if m := errorTableRE.FindStringSubmatch(origSQL); m != nil {
+		report.ErrorTable = m[1]
+	}
+	c.statsMux.Lock()
+	c.lastLoad = report
+	c.statsMux.Unlock()
+
+	return bytesWritten, chunks, nil
 }
 
 func (c *Conn) initProxy(sql string) (*Proxy, func(interface{}) error, error) {
-	proxy, err := NewProxy(c.Conf.Host, c.Conf.Port, &bufPool, c.log)
+	proxy, err := c.openProxy()
 	if err != nil {
-		c.error(err.Error())
 		return nil, nil, err
 	}
 
-	proxyURL := fmt.Sprintf("http://%s:%d", proxy.Host, proxy.Port)
-	sql = fmt.Sprintf(sql, proxyURL)
+	sql = fmt.Sprintf(sql, proxy.url())
 
+	c.log.Debug("Stream sql: ", sql)
 	req := &execReq{
 		Command: "execute",
-		SqlText: sql,
+		SqlText: c.rewriteSQL(sql),
 	}
-	c.log.Debug("Stream sql: ", sql)
 	receiver, err := c.asyncSend(req)
 	if err != nil {
 		c.errorf("Unable to stream sql: %s %s", sql, err)
-		proxy.Shutdown()
+		c.releaseProxy(proxy)
 		return nil, nil, err
 	}
 
 	return proxy, receiver, nil
 }
 
+func (c *Conn) openProxy() (*Proxy, error) {
+	// Try the node we're connected to first then fail over to the others
+	hosts := expandHostRange(c.Conf.Host)
+	if i := slices.Index(hosts, c.host); i > 0 {
+		hosts = append([]string{c.host}, slices.Delete(hosts, i, i+1)...)
+	}
+	proxy, err := newProxyFailover(c.dialer(), hosts, c.Conf.Port, &bufPool, c.log)
+	if err != nil {
+		c.error(err.Error())
+		return nil, err
+	}
+
+	c.proxyMux.Lock()
+	if c.proxies == nil {
+		c.proxies = map[*Proxy]bool{}
+	}
+	c.proxies[proxy] = true
+	c.proxyMux.Unlock()
+	if c.Conf.ProxyStatsInterval > 0 {
+		proxy.statsEvery = c.Conf.ProxyStatsInterval
+		proxy.onStats = func(st ProxyStats) {
+			c.log.Debugf(
+				"Proxy %s %s: %.0f bytes/s %.1f chunks/s (io wait %s, chan wait %s)",
+				st.Node, st.Direction, st.BytesPerSec, st.ChunksPerSec, st.IOWait, st.ChanWait,
+			)
+			c.emit(ConnEvent{Type: EventProxyStats, Host: proxy.Host, ProxyStats: &st})
+		}
+	}
+	c.emit(ConnEvent{Type: EventProxyOpened, Host: proxy.Host})
+	return proxy, nil
+}
+
+func (c *Conn) releaseProxy(proxy *Proxy) {
+	proxy.Shutdown()
+	c.proxyMux.Lock()
+	delete(c.proxies, proxy)
+	c.proxyMux.Unlock()
+	c.emit(ConnEvent{Type: EventProxyClosed, Host: proxy.Host})
+}
+
+var errorTableRE = regexp.MustCompile(`(?i)\bERRORS\s+INTO\s+((?:"[^"]*"|\[[^\]]*\]|[\w.])+)`)
+
 func retryableError(err error) bool {
 	retryableError := regexp.MustCompile(`(write: broken pipe|failed after 0 bytes.+(Connection refused|Couldn't connect to server))`)
 	if err != nil &&
@@ -334,9 +902,17 @@ func (c *Conn) getTableImportSQL(schema, table string) string {
 	)
 }
 
-func (c *Conn) getTableExportSQL(schema, table string) string {
-	return fmt.Sprintf(
-		"EXPORT %s.%s INTO CSV AT '%%s' FILE 'data.csv'",
-		c.QuoteIdent(schema), c.QuoteIdent(table),
+func (c *Conn) getTableExportSQL(schema, table string, opts ...ExportOpts) string {
+	file := "data.csv"
+	if len(opts) > 0 && opts[0].FileName != "" {
+		file = opts[0].FileName
+	}
+	sql := fmt.Sprintf(
+		"EXPORT %s.%s INTO CSV AT '%%s' FILE '%s'",
+		c.QuoteIdent(schema), c.QuoteIdent(table), QuoteStr(file),
 	)
+	if len(opts) > 0 && opts[0].WithColumnNames {
+		sql += " WITH COLUMN NAMES"
+	}
+	return sql
 }
@@ -2,7 +2,17 @@ package exasol
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
 )
 
 func (s *testSuite) TestBulkInsert() {
@@ -155,6 +165,105 @@ func (s *testSuite) TestStreamExecute() {
 	s.Equal(expect, got, "Correctly stream-inserted")
 }
 
+func (s *testSuite) TestLastLoadReport() {
+	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(10) )`)
+	s.Nil(s.exaConn.LastLoadReport(), "No loads yet")
+
+	data := bytes.NewBufferString("1,a\n2,\"b\nc\"\nx,d\n")
+	err := s.exaConn.BulkExecute(fmt.Sprintf(
+		"IMPORT INTO %s.foo FROM CSV AT '%%s' FILE 'data.csv' ERRORS INTO %s.foo_errs REJECT LIMIT UNLIMITED",
+		s.qschema, s.qschema,
+	), data)
+	s.NoError(err)
+	report := s.exaConn.LastLoadReport()
+	if s.NotNil(report) {
+		s.Equal(uint64(3), report.RowsRead)
+		s.Equal(int64(2), report.RowsInserted)
+		s.Equal(int64(1), report.RowsRejected)
+		s.Equal(s.qschema+".foo_errs", report.ErrorTable)
+		s.Equal(int64(16), report.BytesWritten)
+		s.Greater(report.Duration, time.Duration(0))
+	}
+}
+
+func (s *testSuite) TestBulkResult() {
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	data := make(chan []byte, 3)
+	data <- []byte("1\n")
+	data <- []byte("2\n3\n")
+	data <- []byte("4\n")
+	close(data)
+	res, err := s.exaConn.StreamInsertResult(s.qschema, "foo", data)
+	if s.NoError(err) {
+		s.Equal(int64(8), res.BytesWritten)
+		s.Equal(int64(3), res.Chunks)
+		s.Equal(0, res.Retries)
+		s.Greater(res.Duration, time.Duration(0))
+	}
+
+	res, err = s.exaConn.BulkInsertResult(s.qschema, "foo", bytes.NewBufferString("5\n"))
+	if s.NoError(err) {
+		s.Equal(int64(2), res.BytesWritten)
+		s.Equal(int64(1), res.Chunks)
+	}
+}
+
+func (s *testSuite) TestStreamInsertFiles() {
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	files := make([]ImportFile, 3)
+	for i := range files {
+		data := make(chan []byte, 1000)
+		for j := 0; j < 1000; j++ {
+			data <- []byte(fmt.Sprintf("%d\n", i*1000+j))
+		}
+		close(data)
+		files[i] = ImportFile{Data: data}
+	}
+	files[0].Name = "first.csv"
+	err := s.exaConn.StreamInsertFiles(s.qschema, "foo", files)
+	s.NoError(err)
+	got := s.fetch(`SELECT COUNT(DISTINCT id), MIN(id), MAX(id) FROM foo`)
+	s.Equal([][]interface{}{{float64(3000), float64(0), float64(2999)}}, got)
+
+	s.exaConn.Conf.SuppressError = true
+	err = s.exaConn.StreamExecuteFiles("IMPORT INTO foo FROM CSV AT '%s' FILE '%s'", files)
+	s.Error(err, "Only one %s allowed")
+
+	data := new(bytes.Buffer)
+	err = s.exaConn.BulkSelect(s.qschema, "foo", data, ExportOpts{FileName: "foo.csv"})
+	s.NoError(err)
+	s.Equal(3000, bytes.Count(data.Bytes(), []byte("\n")))
+}
+
+func (s *testSuite) TestStreamInsertOnce() {
+	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(10) )`)
+	mkData := func(rows string) chan []byte {
+		data := make(chan []byte, 1)
+		data <- []byte(rows)
+		close(data)
+		return data
+	}
+
+	// A failed load shouldn't leave partial rows behind
+	s.exaConn.Conf.SuppressError = true
+	loaded, err := s.exaConn.StreamInsertOnce(s.qschema, "foo", "tok1", mkData("1,'a'\nx,'b'\n"))
+	s.Error(err)
+	s.False(loaded)
+	s.Equal([][]interface{}{{float64(0)}}, s.fetch(`SELECT COUNT(*) FROM foo`))
+
+	// Retrying with the same token loads once and only once
+	loaded, err = s.exaConn.StreamInsertOnce(s.qschema, "foo", "tok1", mkData("1,'a'\n2,'b'\n"))
+	s.NoError(err)
+	s.True(loaded)
+	loaded, err = s.exaConn.StreamInsertOnce(s.qschema, "foo", "tok1", mkData("1,'a'\n2,'b'\n"))
+	s.NoError(err)
+	s.False(loaded, "Already loaded")
+	s.Equal([][]interface{}{{float64(2)}}, s.fetch(`SELECT COUNT(*) FROM foo`))
+
+	got := s.fetch(`SELECT COUNT(*) FROM exa_all_tables WHERE table_name LIKE 'TMP__FOO%'`)
+	s.Equal([][]interface{}{{float64(0)}}, got, "Staging table dropped")
+}
+
 func (s *testSuite) TestStreamSelect() {
 	s.execute(`CREATE TABLE foo ( id INT, val CLOB )`)
 	s.execute(`INSERT INTO foo VALUES (1,'a'),(2,'b'),(3,'c')`)
@@ -185,6 +294,158 @@ func (s *testSuite) TestStreamSelect() {
 	s.Equal(int64(12), rows.BytesRead)
 }
 
+func (s *testSuite) TestStreamSelectFrom() {
+	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(10) )`)
+	s.execute(`INSERT INTO foo VALUES (1,'a'),(2,'b' || CHR(10) || 'c'),(3,'d'),(4,'e')`)
+
+	// Start part way through the rows
+	rows := s.exaConn.StreamSelectFrom(s.qschema, "foo", "id", 1)
+	var csv string
+	for d := range rows.Data {
+		csv += string(d)
+	}
+	s.Nil(rows.Error)
+	s.Equal("2,\"b\nc\"\n3,d\n4,e\n", csv, "Skipped the offset")
+	s.Equal(uint64(3), rows.RowsRead, "Quoted newlines aren't rows")
+
+	rows.RowsRead = 1 // Pretend we only got one row
+	rows = rows.Resume()
+	csv = ""
+	for d := range rows.Data {
+		csv += string(d)
+	}
+	s.Nil(rows.Error)
+	s.Equal("3,d\n4,e\n", csv, "Resumed")
+
+	s.exaConn.Conf.SuppressError = true
+	rows = s.exaConn.StreamSelect(s.qschema, "foo")
+	for range rows.Data {
+	}
+	rows = rows.Resume()
+	if s.Error(rows.Error) {
+		s.Contains(rows.Error.Error(), "can be resumed")
+	}
+}
+
+func (s *testSuite) TestRowAlignedStreams() {
+	// Re-framing chunks that split rows and quoted newlines
+	r := &Rows{Pool: &bufPool, conn: &Conn{Conf: ConnConf{RowAlignedStreams: true}}}
+	var got []string
+	for _, chunk := range []string{"1,a\n2,", "\"b\n", "c\"\n3,d", "\n", "4,e"} {
+		if out := r.onRead([]byte(chunk)); out != nil {
+			got = append(got, string(out))
+		}
+	}
+	if out := r.onRead(nil); out != nil {
+		got = append(got, string(out))
+	}
+	s.Equal([]string{"1,a\n", "2,\"b\nc\"\n", "3,d\n", "4,e"}, got)
+	s.Equal(uint64(3), r.counter.rows)
+	s.Zero(r.RowsRead, "Not counted until they've been sent on")
+
+	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(100) )`)
+	s.execute(`INSERT INTO foo SELECT level, 'x' || CHR(10) || level FROM dual CONNECT BY level <= 1e5`)
+	s.exaConn.Conf.RowAlignedStreams = true
+	rows := s.exaConn.StreamSelect(s.qschema, "foo")
+	var numRows int
+	for d := range rows.Data {
+		s.Equal(byte('\n'), d[len(d)-1], "Chunk ends on a row")
+		numRows += bytes.Count(d, []byte("\"\n"))
+		rows.Pool.Put(d)
+	}
+	s.Nil(rows.Error)
+	s.Equal(100000, numRows)
+	s.Equal(uint64(100000), rows.RowsRead)
+}
+
+func (s *testSuite) TestExportOpts() {
+	s.execute(`CREATE TABLE foo ( id INT, val VARCHAR(10) )`)
+	s.execute(`INSERT INTO foo VALUES (1,'a')`)
+
+	data := new(bytes.Buffer)
+	err := s.exaConn.BulkSelect(s.qschema, "foo", data, ExportOpts{WithColumnNames: true})
+	s.NoError(err)
+	s.Equal("ID,VAL\n1,a\n", data.String(), "Header row")
+
+	rows := s.exaConn.StreamSelect(s.qschema, "foo", ExportOpts{Describe: true})
+	var csv string
+	for d := range rows.Data {
+		csv += string(d)
+	}
+	s.Nil(rows.Error)
+	s.Equal("1,a\n", csv)
+	if s.Len(rows.Columns, 2) {
+		s.Equal("ID", rows.Columns[0].Name)
+		s.Equal("DECIMAL", rows.Columns[0].DataType.Type)
+		s.Equal("VARCHAR", rows.Columns[1].DataType.Type)
+		s.Equal(10, rows.Columns[1].DataType.Size)
+	}
+
+	s.exaConn.Conf.SuppressError = true
+	rows = s.exaConn.StreamSelect(s.qschema, "asdf", ExportOpts{Describe: true})
+	for range rows.Data {
+	}
+	if s.Error(rows.Error) {
+		s.Contains(rows.Error.Error(), "not found")
+	}
+}
+
+func (s *testSuite) TestForEachParallel() {
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	s.execute(`INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 1e6`)
+
+	var mux sync.Mutex
+	var total int64
+	rows := s.exaConn.StreamSelect(s.qschema, "foo")
+	err := rows.ForEachParallel(4, func(chunk []byte) error {
+		mux.Lock()
+		total += int64(len(chunk))
+		mux.Unlock()
+		return nil
+	})
+	s.NoError(err)
+	s.Equal(rows.BytesRead, total)
+
+	var calls atomic.Int32
+	rows = s.exaConn.StreamSelect(s.qschema, "foo")
+	err = rows.ForEachParallel(4, func(chunk []byte) error {
+		calls.Add(1)
+		return errors.New("oops")
+	})
+	s.EqualError(err, "oops")
+	s.LessOrEqual(calls.Load(), int32(4), "Stopped early")
+}
+
+func (s *testSuite) TestStreamOpts() {
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	s.execute(`INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 1e6`)
+
+	var highs, lows int
+	s.exaConn.Conf.StreamOpts = StreamOpts{
+		BufferSize:      8,
+		HighWatermark:   6,
+		LowWatermark:    2,
+		OnHighWatermark: func(*Rows) { highs++ },
+		OnLowWatermark:  func(*Rows) { lows++ },
+	}
+	rows := s.exaConn.StreamSelect(s.qschema, "foo")
+	s.Equal(8, cap(rows.Data))
+	chunks := 0
+	for d := range rows.Data {
+		if chunks == 0 {
+			// Fall behind to begin with then catch up
+			time.Sleep(time.Second)
+		}
+		chunks++
+		rows.Pool.Put(d)
+	}
+	s.Nil(rows.Error)
+	s.Greater(chunks, 10)
+	s.GreaterOrEqual(highs, 1, "Slow consumer detected")
+	// The export may end before the final drain is seen
+	s.Contains([]int{highs, highs - 1}, lows, "Caught up")
+}
+
 func (s *testSuite) TestStreamQuery() {
 	s.execute(`CREATE TABLE foo ( id INT, val INT )`)
 	// Inserts 300K rows
@@ -221,3 +482,259 @@ func (s *testSuite) TestStreamQuery() {
 	s.Equal("2\x002\x00\n1\x001\x00\n", csv[len(csv)-10:], "End ok")
 	s.Equal(int64(4277790), rows.BytesRead)
 }
+
+func (s *testSuite) TestBulkInvalidInputs() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )", nil, s.schema)
+
+	// Nil data
+	s.Error(exa.BulkInsert(s.qschema, "FOO", nil))
+	s.Error(exa.BulkExecute("IMPORT INTO [test].FOO FROM CSV AT '%s' FILE 'data.csv'", nil))
+	s.Error(exa.BulkSelect(s.qschema, "FOO", nil))
+	s.Error(exa.BulkQuery("EXPORT [test].FOO INTO CSV AT '%s' FILE 'data.csv'", nil))
+	s.Error(exa.StreamInsert(s.qschema, "FOO", nil))
+	s.Error(exa.StreamExecute("IMPORT INTO [test].FOO FROM CSV AT '%s' FILE 'data.csv'", nil))
+
+	// Missing names/SQL
+	s.Error(exa.BulkInsert("", "FOO", &bytes.Buffer{}))
+	s.Error(exa.BulkExecute(" ", &bytes.Buffer{}))
+	s.Error(exa.StreamInsert(s.qschema, "", make(chan []byte)))
+	rows := exa.StreamSelect("", "")
+	for range rows.Data {
+	}
+	s.Error(rows.Error)
+	rows.Close()
+	rows = exa.StreamQuery("")
+	s.Error(rows.Error)
+	rows.Close()
+
+	// Empty buffers and closed chans are just no data
+	s.NoError(exa.BulkInsert(s.qschema, "FOO", &bytes.Buffer{}))
+	closed := make(chan []byte)
+	close(closed)
+	s.NoError(exa.StreamInsert(s.qschema, "FOO", closed))
+
+	// Empty chunks are skipped rather than ending the upload early
+	data := make(chan []byte, 3)
+	data <- []byte("1,a\n")
+	data <- []byte{}
+	data <- []byte("2,b\n")
+	close(data)
+	s.NoError(exa.StreamInsert(s.qschema, "FOO", data))
+	count, err := exa.Count("SELECT * FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.Equal(int64(2), count)
+
+	_, err = NewProxy("127.0.0.1", 1, nil, nil)
+	s.Error(err, "No panic with a nil pool or logger")
+}
+
+func (s *testSuite) TestProxyFailover() {
+	// Fakes the proxy handshake on the "up" node
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if !strings.HasPrefix(addr, "up:") {
+			return nil, errors.New("node down")
+		}
+		client, server := net.Pipe()
+		go func() {
+			io.ReadFull(server, make([]byte, 12))
+			resp := make([]byte, 24)
+			binary.LittleEndian.PutUint32(resp[4:], 1234)
+			copy(resp[8:], "10.1.1.1")
+			server.Write(resp)
+		}()
+		return client, nil
+	}
+	p, err := newProxyFailover(dial, []string{"down1", "up", "down2"}, 8563, nil, nil)
+	if s.NoError(err) {
+		s.Equal("up", p.Node())
+		s.Equal("10.1.1.1", p.Host)
+		s.Equal(uint32(1234), p.Port)
+		p.Shutdown()
+	}
+
+	_, err = newProxyFailover(dial, []string{"down1", "down2"}, 8563, nil, nil)
+	if s.Error(err) {
+		s.Contains(err.Error(), "all 2 hosts failed")
+		s.Contains(err.Error(), "down2: Unable to setup proxy (1): node down")
+	}
+	_, err = newProxyFailover(dial, []string{"down1"}, 8563, nil, nil)
+	s.EqualError(err, "Unable to setup proxy (1): node down")
+
+	proxy, err := s.exaConn.openProxy()
+	if s.NoError(err) {
+		s.Equal(s.exaConn.Host(), proxy.Node())
+		s.exaConn.releaseProxy(proxy)
+	}
+}
+
+func (s *testSuite) TestProxyStats() {
+	var mux sync.Mutex
+	var stats []ProxyStats
+	p := &Proxy{node: "n1", statsEvery: 20 * time.Millisecond, onStats: func(st ProxyStats) {
+		mux.Lock()
+		stats = append(stats, st)
+		mux.Unlock()
+	}}
+	m := p.startMeter("write")
+	m.add(100)
+	m.add(50)
+	time.Sleep(30 * time.Millisecond)
+	m.stop()
+	mux.Lock()
+	if s.Len(stats, 1) {
+		s.Equal("n1", stats[0].Node)
+		s.Equal("write", stats[0].Direction)
+		s.Equal(int64(150), stats[0].Bytes)
+		s.Equal(int64(2), stats[0].Chunks)
+		s.Equal(int64(150), stats[0].TotalBytes)
+		s.Greater(stats[0].BytesPerSec, 0.0)
+	}
+	mux.Unlock()
+	s.Nil((&Proxy{}).startMeter("read"), "No meter without onStats")
+
+	var events []ConnEvent
+	conf := s.connConf()
+	conf.ProxyStatsInterval = 50 * time.Millisecond
+	conf.OnStateChange = func(ev ConnEvent) {
+		if ev.Type == EventProxyStats {
+			mux.Lock()
+			events = append(events, ev)
+			mux.Unlock()
+		}
+	}
+	c, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer c.Disconnect()
+	c.Execute("CREATE TABLE foo (id INT)", nil, s.schema)
+	data := make(chan []byte)
+	go func() {
+		for i := range 5 {
+			data <- []byte(fmt.Sprintf("%d\n", i))
+			time.Sleep(40 * time.Millisecond)
+		}
+		close(data)
+	}()
+	s.NoError(c.StreamInsert(s.schema, "foo", data))
+	mux.Lock()
+	defer mux.Unlock()
+	if s.NotEmpty(events) {
+		st := events[len(events)-1].ProxyStats
+		s.Equal("write", st.Direction)
+		s.Equal(c.Host(), st.Node)
+		s.Greater(st.ChanWait, time.Duration(0), "Waiting on the slow producer")
+	}
+}
+
+// Counts the Writes to the underlying conn
+type writeCountingConn struct {
+	net.Conn
+	writes int
+}
+
+func (c *writeCountingConn) Write(b []byte) (int, error) {
+	c.writes++
+	return c.Conn.Write(b)
+}
+
+// Fakes Exasol's side of a proxy IMPORT returning what it received
+func fakeImportProxy() (*Proxy, *writeCountingConn, chan []byte) {
+	client, server := net.Pipe()
+	conn := &writeCountingConn{Conn: client}
+	received := make(chan []byte, 1)
+	go func() {
+		server.Write([]byte("PUT /data.csv HTTP/1.1\r\n\r\n"))
+		body, _ := io.ReadAll(server)
+		received <- body
+	}()
+	p := &Proxy{conn: conn, running: true, log: newDefaultLogger()}
+	return p, conn, received
+}
+
+func (s *testSuite) TestProxyWriteCoalescing() {
+	p, conn, received := fakeImportProxy()
+	data := make(chan []byte, 100)
+	for range 100 {
+		data <- []byte("1,a\n")
+	}
+	close(data)
+	n, err := p.Write(data)
+	s.NoError(err)
+	s.Equal(int64(400), n)
+	headerWrites := 6
+	s.Equal(headerWrites+1, conn.writes, "Chunks written at once")
+	p.Shutdown()
+	body := string(<-received)
+	s.True(strings.HasSuffix(body, "4\r\n1,a\n\r\n0\r\n\r\n"))
+	s.Equal(100, strings.Count(body, "4\r\n1,a\n\r\n"))
+
+	// Flushes whenever the producer is slow to keep latency down
+	p, conn, received = fakeImportProxy()
+	slow := make(chan []byte)
+	go func() {
+		for range 3 {
+			slow <- []byte("1,a\n")
+			time.Sleep(10 * time.Millisecond)
+		}
+		close(slow)
+	}()
+	_, err = p.Write(slow)
+	s.NoError(err)
+	s.Equal(headerWrites+4, conn.writes)
+	p.Shutdown()
+	<-received
+}
+
+func BenchmarkProxyWrite(b *testing.B) {
+	chunk := []byte("12345,some text,2019-01-01\n")
+	for range b.N {
+		p, _, received := fakeImportProxy()
+		data := make(chan []byte, 1000)
+		go func() {
+			for range 1000 {
+				data <- chunk
+			}
+			close(data)
+		}()
+		p.Write(data)
+		p.Shutdown()
+		<-received
+	}
+	b.SetBytes(int64(1000 * len(chunk)))
+}
+
+func (s *testSuite) TestProxyReadStop() {
+	// Fakes Exasol sending a chunk of an EXPORT then stalling
+	client, server := net.Pipe()
+	defer server.Close()
+	go func() {
+		go io.Copy(io.Discard, server) // The 100 Continue
+		server.Write([]byte("PUT /data.csv HTTP/1.1\r\n\r\n"))
+		server.Write([]byte("4\r\n1,a\n\r\n"))
+	}()
+	p := &Proxy{conn: client, running: true, pool: &bufPool, log: newDefaultLogger()}
+
+	data := make(chan []byte)
+	stop := make(chan bool, 1)
+	type result struct {
+		n   int64
+		err error
+	}
+	done := make(chan result)
+	go func() {
+		n, err := p.Read(data, stop)
+		done <- result{n, err}
+	}()
+	s.Equal("1,a\n", string(<-data))
+	stop <- true
+	select {
+	case res := <-done:
+		s.NoError(res.err)
+		s.Equal(int64(4), res.n)
+	case <-time.After(2 * time.Second):
+		s.Fail("Proxy.Read didn't return upon stop")
+	}
+	s.False(p.IsRunning())
+}
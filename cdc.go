@@ -0,0 +1,108 @@
+/*
+	Simple change data capture for syncing tables to other systems
+
+	Exasol doesn't track changes to individual rows so whether a table has
+	changed at all is taken from its last commit time (in EXA_ALL_OBJECTS)
+	and which rows have changed from a user-maintained column (e.g. an
+	IDENTITY or an updated_at timestamp). Deleted rows can't be detected
+	this way so tables that have rows deleted need resyncing in full.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// TableCommit is when a table was last committed to
+type TableCommit struct {
+	SchemaName string `exasol:"root_name"`
+	TableName  string `exasol:"object_name"`
+	LastCommit time.Time
+}
+
+// ChangeCheckpoint records how far a table has been synced.
+// The zero value means nothing has been synced yet.
+type ChangeCheckpoint struct {
+	LastCommit time.Time   // The table's last commit as of the sync
+	Mark       interface{} // The high-water mark of ExportIncremental
+}
+
+// ChangedTables returns the schema's tables committed to after since
+// (all of them if since is zero) ordered by their last commit
+func (c *Conn) ChangedTables(schema string, since time.Time) ([]TableCommit, error) {
+	s := c.storedName(schema)
+	sql := `
+		SELECT root_name, object_name, last_commit
+		FROM exa_all_objects
+		WHERE object_type = 'TABLE' AND root_name = ?`
+	binds := []interface{}{s}
+	if !since.IsZero() {
+		sql += " AND last_commit > ?"
+		binds = append(binds, since)
+	}
+	sql += " ORDER BY last_commit, object_name"
+	return collect(QueryStruct[TableCommit](c, sql, binds))
+}
+
+// TableLastCommit returns when the table was last committed to
+func (c *Conn) TableLastCommit(schema, table string) (time.Time, error) {
+	s, t := c.storedName(schema), c.storedName(table)
+	commits, err := collect(QueryStruct[TableCommit](c, `
+		SELECT root_name, object_name, last_commit
+		FROM exa_all_objects
+		WHERE object_type = 'TABLE' AND root_name = ? AND object_name = ?
+	`, []interface{}{s, t}))
+	if err != nil {
+		return time.Time{}, err
+	} else if len(commits) == 0 {
+		return time.Time{}, fmt.Errorf("Unable to find the table %s.%s", schema, table)
+	}
+	return commits[0].LastCommit, nil
+}
+
+// ExportChanges exports the table's rows that have changed since the
+// checkpoint returning them along with the new checkpoint, which should
+// only be saved once the Rows have been read without error.
+//
+// If the table hasn't been committed to since the checkpoint nothing is
+// exported. Otherwise, if a changeColumn is given, just the rows whose
+// value for it is beyond the checkpoint's mark are exported (see
+// ExportIncremental) and if not the whole table is. Either way the rows
+// are sorted by the changeColumn (if any). Should the table turn out to be
+// unchanged, or its last commit can't be found, the checkpoint returned
+// is the one passed in.
+func (c *Conn) ExportChanges(
+	schema, table, changeColumn string, cp ChangeCheckpoint,
+) (*Rows, ChangeCheckpoint) {
+	if schema == "" || table == "" {
+		return c.failedRows(fmt.Errorf("You must pass in a schema and table to ExportChanges")), cp
+	}
+	lastCommit, err := c.TableLastCommit(schema, table)
+	if err != nil {
+		return c.failedRows(err), cp
+	} else if !lastCommit.After(cp.LastCommit) {
+		return c.failedRows(nil), cp // Unchanged
+	}
+
+	next := ChangeCheckpoint{LastCommit: lastCommit}
+	if changeColumn == "" {
+		return c.StreamSelect(schema, table), next
+	}
+	rows, mark := c.ExportIncremental(schema, table, changeColumn, cp.Mark)
+	next.Mark = mark
+	return rows, next
+}
@@ -0,0 +1,71 @@
+package exasol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func (s *testSuite) TestChangedTables() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	s.execute(`CREATE TABLE bar ( id INT )`)
+
+	tables, err := exa.ChangedTables(s.schema, time.Time{})
+	s.NoError(err)
+	names := []string{}
+	for _, t := range tables {
+		s.Equal("TEST", t.SchemaName)
+		s.False(t.LastCommit.IsZero())
+		names = append(names, t.TableName)
+	}
+	s.ElementsMatch([]string{"FOO", "BAR"}, names)
+
+	last, err := exa.TableLastCommit(s.schema, "foo")
+	s.NoError(err)
+	tables, err = exa.ChangedTables(s.schema, last)
+	s.NoError(err)
+	for _, t := range tables {
+		s.NotEqual("FOO", t.TableName, "Not changed since")
+	}
+
+	_, err = exa.TableLastCommit(s.schema, "nope")
+	s.ErrorContains(err, "Unable to find the table")
+}
+
+func (s *testSuite) TestExportChanges() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id INT, updated_at DECIMAL(10,0) )`)
+	s.execute(`INSERT INTO foo VALUES (1, 100), (2, 200)`)
+	readAll := func(rows *Rows) string {
+		var csv string
+		for d := range rows.Data {
+			csv += string(d)
+		}
+		s.NoError(rows.Error)
+		return csv
+	}
+
+	rows, cp := exa.ExportChanges(s.schema, "foo", "updated_at", ChangeCheckpoint{})
+	s.Equal("1,100\n2,200\n", readAll(rows))
+	s.False(cp.LastCommit.IsZero())
+	s.Equal(json.Number("200"), cp.Mark)
+
+	rows, next := exa.ExportChanges(s.schema, "foo", "updated_at", cp)
+	s.Equal("", readAll(rows), "Unchanged")
+	s.Equal(cp, next)
+
+	time.Sleep(time.Second) // So the last commit moves on
+	s.execute(`UPDATE foo SET updated_at = 300 WHERE id = 1`)
+	rows, next = exa.ExportChanges(s.schema, "foo", "updated_at", cp)
+	s.Equal("1,300\n", readAll(rows))
+	s.True(next.LastCommit.After(cp.LastCommit))
+	s.Equal(json.Number("300"), next.Mark)
+
+	// Without a change column it's a full resync
+	rows, _ = exa.ExportChanges(s.schema, "foo", "", ChangeCheckpoint{})
+	s.Len(readAll(rows), len("1,300\n2,200\n"))
+
+	rows, next = exa.ExportChanges(s.schema, "nope", "updated_at", cp)
+	s.Error(rows.Error)
+	s.Equal(cp, next)
+}
@@ -0,0 +1,53 @@
+package exasol
+
+import "strings"
+
+func (s *testSuite) TestChecksumSQL() {
+	sql := checksumSQL(`"S"."T"`, []string{`"A"`, `"B"`}, ChecksumOpts{})
+	s.Contains(sql, `HASH_MD5(CASE WHEN CAST("A" AS VARCHAR(2000000)) IS NULL THEN 'N'`)
+	s.Contains(sql, ` || '|' || CASE WHEN CAST("B" AS`)
+	s.Contains(sql, "GROUP BY SUBSTR(h, 1, 3)")
+	s.NotContains(sql, "WHERE")
+
+	sql = checksumSQL(`"S"."T"`, []string{`"A"`}, ChecksumOpts{Where: "a > 1", GroupBy: "MOD(a, 10)"})
+	s.Contains(sql, `, (MOD(a, 10)) AS g FROM "S"."T" WHERE a > 1)`)
+	s.Contains(sql, "GROUP BY COALESCE(CAST(g AS VARCHAR(2000000)), 'NULL')")
+
+	a := map[string]GroupChecksum{"1": {1, "x"}, "2": {2, "y"}}
+	s.Equal(combineChecksums(a), combineChecksums(map[string]GroupChecksum{"2": {2, "y"}, "1": {1, "x"}}))
+	s.NotEqual(combineChecksums(a), combineChecksums(map[string]GroupChecksum{"1": {1, "x"}}))
+}
+
+func (s *testSuite) TestTableChecksum() {
+	s.execute("CREATE TABLE foo (id INT, a VARCHAR(10), b VARCHAR(10))")
+	s.execute("CREATE TABLE bar (b VARCHAR(10), a VARCHAR(10), id DECIMAL(30,0))")
+	s.execute("INSERT INTO foo VALUES (1, 'ab', 'c'), (2, NULL, 'x'), (3, 'y', 'z')")
+	s.execute("INSERT INTO bar VALUES ('z', 'y', 3), ('c', 'ab', 1), ('x', NULL, 2)")
+
+	foo, err := s.exaConn.TableChecksum(s.schema, "foo", ChecksumOpts{})
+	s.NoError(err)
+	s.Equal(int64(3), foo.Rows)
+	s.Len(foo.Hash, 32)
+
+	cmp, err := CompareTables(s.exaConn, s.schema, "foo", s.exaConn, s.schema, "bar", ChecksumOpts{})
+	s.NoError(err)
+	s.True(cmp.Equal, "Same rows in a different order, column order and types")
+	s.Equal(foo.Hash, cmp.B.Hash)
+
+	s.execute("UPDATE bar SET a = 'a', b = 'bc' WHERE id = 1")
+	opts := ChecksumOpts{GroupBy: "CASE WHEN id < 3 THEN 'low' ELSE 'high' END"}
+	cmp, err = CompareTables(s.exaConn, s.schema, "foo", s.exaConn, s.schema, "bar", opts)
+	s.NoError(err)
+	s.False(cmp.Equal)
+	s.Equal([]string{"low"}, cmp.DiffGroups)
+	s.Equal(int64(2), cmp.A.Groups["low"].Rows)
+
+	opts = ChecksumOpts{Columns: []string{"id"}, Where: "id > 1"}
+	cmp, err = CompareTables(s.exaConn, s.schema, "foo", s.exaConn, s.schema, "bar", opts)
+	s.NoError(err)
+	s.True(cmp.Equal)
+	s.Equal(int64(2), cmp.A.Rows)
+
+	_, err = s.exaConn.TableChecksum(s.schema, "nope", ChecksumOpts{})
+	s.True(strings.HasPrefix(err.Error(), "Unable to checksum"), err.Error())
+}
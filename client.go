@@ -21,20 +21,33 @@
 package exasol
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	mrand "math/rand/v2"
 	"net/url"
 	"os/user"
+	"reflect"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/crypto/ssh"
 )
 
 /*--- Public Interface ---*/
@@ -42,6 +55,17 @@ import (
 const ExasolAPIVersion = 3
 const DriverVersion = "2"
 
+const maxFetchBytes = 64 * 1024 * 1024 // Max allowed by Exasol per fetch
+const minFetchBytes = 64 * 1024        // Smallest retry after a ResponseTooLargeError
+
+var ErrNoRows = errors.New("Query returned no rows")
+var ErrTooManyRows = errors.New("Query returned more than one row")
+var ErrIdleTimeout = errors.New("Connection was idle for longer than MaxIdleTime")
+var ErrMaxLifetime = errors.New("Connection was open for longer than MaxLifetime")
+var ErrConnClosed = errors.New("Connection is closed")
+var ErrRetired = errors.New("Connection was retired")
+var ErrNoBindData = errors.New("Binds were passed but they're empty")
+
 type ConnConf struct {
 	Host           string
 	Port           uint16
@@ -57,10 +81,226 @@ type ConnConf struct {
 	Logger         Logger    // Optional for better control over logging
 	WSHandler      WSHandler // Optional for intercepting websocket traffic
 	CachePrepStmts bool
+	FetchOpts      FetchOpts  // Defaults for FetchChan/FetchSlice
+	StreamOpts     StreamOpts // For StreamQuery/StreamSelect exports
+	// Alternatives to Password for services using rotating secrets.
+	// PasswordFunc is called to get the password each time the
+	// connection logs in. PasswordBytes is zeroed after it's used so it
+	// can only be used for a single Connect. Neither (nor Password) are
+	// included in the output of fmt's %v etc.
+	PasswordFunc  func() (string, error)
+	PasswordBytes []byte
+	// The hostname to verify Exasol's certificate against (rather than the
+	// IP connected to) and the CAs to verify it with (rather than the
+	// system's) e.g. for clusters fronted by a load balancer. These
+	// override TLSConfig's ServerName and RootCAs. See RootCAsFromFiles.
+	TLSServerName string
+	TLSRootCAs    *x509.CertPool
+	// By default all numbers are returned as float64. With TypedInts
+	// DECIMALs with a scale of 0 and a precision <= 18 are returned as
+	// int64 instead so that IDs etc. survive round trips bit-exactly.
+	TypedInts bool
+	// Returns all numbers as json.Number (overriding TypedInts)
+	// so that you can apply your own precision policy
+	UseNumber bool
+	// By default Execute's binds are coerced to the parameter types where
+	// they'd otherwise be rejected or lose precision (e.g. large ints bound
+	// to DECIMAL(18,0)s are sent as strings). This sends them as is.
+	// []byte, time.Time and io.Reader binds are always converted.
+	RawBinds bool
+	// Return ErrNoBindData from Execute when binds are passed but they're
+	// empty (e.g. an empty slice of rows to insert) rather than executing
+	// the statement without any, which can hide bugs. Pass nil binds to
+	// execute a statement without any.
+	StrictBinds bool
+	// Rollback any open transaction upon Disconnect so that
+	// uncommitted work never leaks into a reused connection
+	RollbackOnDisconnect bool
+	// Arbitrary key/values (e.g. job id, team, environment) that are
+	// appended to the ClientName so DBAs can attribute sessions
+	// in EXA_DBA_SESSIONS to specific pipelines
+	Tags map[string]string
+	// Session attributes (e.g. DateFormat, NumericCharacters, Timezone)
+	// that are sent with the login so the session starts out with them.
+	// Autocommit is always enabled at login. Use DisableAutoCommit after
+	// connecting if you need it off. QueryTimeout takes precedence
+	// over SessionAttributes.QueryTimeout if both are set.
+	SessionAttributes *Attributes
+	// Enables timestampUtcEnabled for the session so that TIMESTAMP WITH
+	// LOCAL TIME ZONE values are sent and received in UTC. time.Time binds
+	// are converted to UTC as well so that the client's, session's and
+	// server's time zones (and their DST rules) no longer matter.
+	// Otherwise DATEs and TIMESTAMPs converted into time.Times are taken
+	// to be in the client's local time zone (and TIMESTAMP binds are
+	// converted to it). DATE binds are always the time.Time's own date.
+	TimestampUTC bool
+	// When Host is an IP range connect to all the hosts at once and keep
+	// whichever connects first rather than trying them one at a time.
+	// This cuts connect latency when some nodes are down. It is ignored
+	// when using a custom WSHandler.
+	ParallelConnect bool
+	// How the node to connect to is chosen when Host is an IP range.
+	// Defaults to HostRandom.
+	HostSelection HostSelection
+	// When Host is an IP range this node is tried first (overriding
+	// HostSelection) e.g. to keep bulk transfers node-local for an ETL
+	// agent co-located with it. See also HostLocal. Bulk proxies are
+	// always opened on the node the Conn is connected to (see Conn.Host).
+	PreferredHost string
+	// Re-frame the Rows.Data chunks from StreamQuery/StreamSelect so that
+	// they always end on a CSV row boundary (honoring quoted newlines).
+	// This lets consumers process each chunk independently.
+	RowAlignedStreams bool
+	// Tunnel the websocket and bulk proxy connections through an SSH jump host
+	SSH *SSHConfig
+	// If set the throughput of bulk IMPORTs/EXPORTs is logged (at debug
+	// level) and sent to OnStateChange as EventProxyStats at this interval
+	// so that slow multi-hour loads can be diagnosed while they run
+	ProxyStatsInterval time.Duration
+	// TCP settings for the websocket and bulk proxy connections
+	// (ignored when tunneling through SSH)
+	Socket SocketOpts
+	// Tees every JSON frame sent to and received from the server (with
+	// passwords redacted) to this writer which is handy when debugging
+	// protocol issues. Result sets are logged too so it can be verbose.
+	WireLog io.Writer
+	// Compresses the websocket messages with this (e.g. ZlibCompressor)
+	// independently of Exasol's own compression, which is useful when
+	// there's a proxy in front of the database that terminates it. The
+	// WSHandler must implement FrameWSHandler (as the default one does).
+	Compressor WSCompressor
+	// Fail responses larger than this many bytes (once decompressed) with
+	// a ResponseTooLargeError rather than reading them into memory, which
+	// could exhaust it. The rest of the response is discarded so that the
+	// connection remains usable. Zero means no limit. As with Compressor
+	// the WSHandler must implement FrameWSHandler. Fetches are retried in
+	// smaller chunks but if an execute's response (which can include the
+	// first rows of a result set) is too large then any result set handle
+	// in it is lost with it, so that result set is left open server-side
+	// until the session ends.
+	MaxResponseSize int64
+	// Return a ProtocolError if a response isn't shaped as the websocket API
+	// specifies (e.g. numResults not matching the results or a partial
+	// result set without a handle). By default such inconsistencies are
+	// just logged as warnings. Either way new fields are ignored.
+	StrictResponses bool
+	// Bounds the bytes being fetched at once across all of the connection's
+	// result sets (i.e. the fetch responses being received and decoded) so
+	// that many concurrent fetches can run in a fixed amount of memory.
+	// Fetches wait for budget to become available before requesting more
+	// rows and MaxFetchBytes is capped at it. The rows being pushed into or
+	// buffered in each chan and the initial execute response (see
+	// MaxResponseSize) aren't counted, so a FetchChan whose consumer is
+	// draining another one first doesn't hold up the rest. Zero means no
+	// budget.
+	FetchMemoryBudget int64
+	// If set the connection is disconnected in the background once it has
+	// been idle for MaxIdleTime or connected for MaxLifetime (waiting until
+	// it's not in use) so that long-running but mostly idle services don't
+	// leave sessions piling up server-side. Subsequent operations fail
+	// with ErrIdleTimeout or ErrMaxLifetime.
+	MaxIdleTime time.Duration
+	MaxLifetime time.Duration
+	// Called upon connecting, authenticating, changing session attributes,
+	// disconnecting and opening/closing bulk proxies so that applications
+	// can track health without polling. It is called synchronously so it
+	// should return quickly and must not use the Conn.
+	OnStateChange func(ConnEvent)
+	// Applied to every statement's SQL right before it's sent (after the
+	// original is logged) e.g. to substitute tenant schemas, inject hints
+	// or tag queries with comments. It must be safe for concurrent use.
+	// Prepared statements are cached by their original SQL so it should
+	// always rewrite a given statement the same way.
+	SQLRewriter func(string) string
+	// Prepends a comment with these key/values to every statement e.g.
+	// /* job=nightly_load, trace_id=4bf92f35... */ so that queries can be
+	// correlated in EXA_SQL_LAST_DAY and the audit views. QueryTagsFunc is
+	// called for each statement with the context given to SetQueryContext
+	// and its tags are added too e.g. the trace and span ids from an
+	// OpenTelemetry trace.SpanContextFromContext(ctx). The tags are added
+	// after SQLRewriter is applied. Prepared statements only get the tags
+	// of whichever statement prepared them.
+	QueryTags     map[string]string
+	QueryTagsFunc func(ctx context.Context) map[string]string
+	// A debugging aid for Conns shared across Go routines. Once the Conn
+	// has been locked (via Lock, TryLock etc.) requests sent from any Go
+	// routine but the one holding the lock are logged or panic. This
+	// catches the kind of misuse that corrupts the protocol's request /
+	// response sequence. It costs a stack trace per request so it's
+	// intended for development rather than production.
+	LockGuard LockGuard
 
 	Timeout uint32 // Deprecated - Use Query/ConnectTimeout instead
 }
 
+// String redacts the passwords so that they never end up in logs
+func (cc ConnConf) String() string {
+	type connConf ConnConf // Avoids recursing back into String
+	cp := connConf(cc)
+	if cp.Password != "" {
+		cp.Password = "<redacted>"
+	}
+	if cp.PasswordBytes != nil {
+		cp.PasswordBytes = []byte("<redacted>")
+	}
+	return fmt.Sprintf("%+v", cp)
+}
+
+func (cc ConnConf) GoString() string { return cc.String() }
+
+// FetchOpts controls how result sets larger than the initial response
+// are retrieved from Exasol. It can be set per connection via ConnConf
+// or passed in per query as the 3rd optional arg to FetchChan/FetchSlice.
+type FetchOpts struct {
+	// Prefetch is the number of bytes requested by the first fetch.
+	// Each subsequent fetch doubles the request size up to MaxFetchBytes.
+	// A small value gets the first rows to interactive consumers quickly.
+	// Zero (the default) requests MaxFetchBytes right away which gives
+	// the best throughput for batch consumers.
+	Prefetch int
+	// MaxFetchBytes caps the bytes requested per fetch.
+	// Zero (or anything above it) means the 64MB maximum the server allows.
+	MaxFetchBytes int
+	// RowPool, if set, is used to allocate the row slices sent on the chan
+	// which cuts GC pressure when streaming very large result sets.
+	// Rows must be copied if you want to retain them and should be Put
+	// back into the pool once you're done with them (like Rows.Pool).
+	RowPool *sync.Pool
+
+	keepNumbers bool // Leave numbers as json.Numbers (regardless of ConnConf)
+}
+
+// StreamOpts controls the buffering of Rows.Data for exports
+// and lets slow (or throttled) consumers be detected.
+type StreamOpts struct {
+	// The capacity of Rows.Data. Defaults to 1.
+	BufferSize int
+	// OnHighWatermark is called when the number of chunks waiting in
+	// Rows.Data reaches HighWatermark (default BufferSize) i.e. the
+	// consumer is falling behind. OnLowWatermark is then called once
+	// it has caught back up to LowWatermark (default 0) or fewer.
+	// The levels are checked as each chunk arrives and the callbacks are
+	// called from the reading Go routine so they should return quickly.
+	HighWatermark   int
+	LowWatermark    int
+	OnHighWatermark func(*Rows)
+	OnLowWatermark  func(*Rows)
+}
+
+// SocketOpts tunes the TCP connections to Exasol. Zero values leave Go's
+// defaults. A short KeepAlive stops NAT gateways and firewalls from
+// dropping connections that go quiet e.g. while a long IMPORT runs.
+type SocketOpts struct {
+	// The interval between keepalive probes. Negative disables them.
+	// Defaults to 15s.
+	KeepAlive time.Duration
+	// Go sets TCP_NODELAY by default. This re-enables Nagle's algorithm.
+	DisableNoDelay bool
+	// The socket's buffer sizes in bytes. Defaults to the OS's.
+	ReadBuffer  int
+	WriteBuffer int
+}
+
 // By default we use the gorilla/websocket implementation however you can also
 // specify a custom websocket handler which you can then use to intercept
 // API traffic. This is handy for:
@@ -74,7 +314,9 @@ type WSHandler interface {
 	// time.Duration is the connect timeout (or zero for none)
 	Connect(url.URL, *tls.Config, time.Duration) error
 	EnableCompression(bool)
-	// Write/ReadJSON will be passed structs from api.go
+	// Write/ReadJSON will be passed structs from api.go. (Custom ReadJSONs
+	// decode numbers as float64s so TypedInts is only exact via the built-in
+	// handlers.)
 	WriteJSON(interface{}) error
 	ReadJSON(interface{}) error
 	Close()
@@ -83,22 +325,64 @@ type WSHandler interface {
 type Conn struct {
 	Conf      ConnConf
 	SessionID uint64
-	Stats     map[string]int
-	Metadata  *AuthData
+	Stats     map[string]int // Use GetStats if the Conn is shared across Go routines
+	Metadata  *AuthData      // See metadata.go for helpers
+	// Cluster info as of the last RefreshMetadata (nil until then)
+	ServerInfo *ServerInfo
 
 	log           Logger
 	wsh           WSHandler
-	prepStmtCache map[string]*prepStmt
-	mux           sync.Mutex
+	host          string // The host (or IP within the range) connected to
+	prepStmtCache map[prepStmtKey]*prepStmt
+	cacheMux      sync.Mutex // Guards prepStmtCache
+	statsMux      sync.Mutex // Guards Stats and lastLoad
+	lastLoad      *LoadReport
+	ctx           context.Context
+	cancel        context.CancelCauseFunc
+	closeOnce     sync.Once
+	proxies       map[*Proxy]bool // Open bulk proxies to close upon cancellation
+	proxyMux      sync.Mutex      // Guards proxies
+	state         atomic.Int32    // A ConnState
+	lastUsed      atomic.Int64    // UnixNano of the last request/response
+	inFlight      atomic.Int32    // Requests awaiting a response
+	idleMux       sync.Mutex      // Guards inFlight increments and expiry (see tryDisconnect)
+	expiry        error           // Why tryDisconnect is disconnecting
+	expirer       int64           // The Go routine doing so
+	ssh           *ssh.Client     // See ConnConf.SSH
+	sched         scheduler
+	txDepth       int             // How many Transaction calls deep we are
+	txState       atomic.Int32    // A txState as tracked by trackTx
+	autocommit    atomic.Bool     // As last set (see trackTx)
+	tempTables    map[string]bool // From CreateTempTable to drop upon Disconnect
+	tempMux       sync.Mutex      // Guards tempTables
+	tempSeq       atomic.Uint64
+	queryCtx      atomic.Pointer[context.Context] // See SetQueryContext
+	lockOwner     atomic.Int64                    // The Go routine holding the lock (see ConnConf.LockGuard)
+	lockShared    atomic.Bool                     // Whether the lock has been used
+	compression   CompressionState                // As negotiated upon login
+	fetchBudget   *memBudget                      // See ConnConf.FetchMemoryBudget
 }
 
 func Connect(conf ConnConf) (*Conn, error) {
+	return ConnectContext(context.Background(), conf)
+}
+
+// ConnectContext is like Connect but ties the connection's lifetime to ctx.
+// Once ctx is done the websocket and any bulk proxies are closed which
+// aborts whatever is in flight and fails all subsequent operations.
+// Disconnect should still be called to release the session cleanly.
+func ConnectContext(ctx context.Context, conf ConnConf) (*Conn, error) {
 	c := &Conn{
 		Conf:          conf,
 		Stats:         map[string]int{},
 		log:           conf.Logger,
 		wsh:           conf.WSHandler,
-		prepStmtCache: map[string]*prepStmt{},
+		prepStmtCache: map[prepStmtKey]*prepStmt{},
+		proxies:       map[*Proxy]bool{},
+	}
+	c.ctx, c.cancel = context.WithCancelCause(ctx)
+	if conf.FetchMemoryBudget > 0 {
+		c.fetchBudget = newMemBudget(conf.FetchMemoryBudget)
 	}
 
 	if c.Conf.Timeout > 0 {
@@ -106,43 +390,187 @@ func Connect(conf ConnConf) (*Conn, error) {
 		c.Conf.QueryTimeout = time.Duration(c.Conf.Timeout) * time.Second
 	}
 
-	if c.Conf.TLSConfig == nil {
-		c.Conf.TLSConfig = &tls.Config{}
-	}
+	c.Conf.TLSConfig = c.Conf.tlsConfig()
 
 	if c.log == nil {
 		c.log = newDefaultLogger()
 	}
 
-	if c.wsh == nil {
-		c.wsh = newDefaultWSHandler()
+	err := c.sshConnect()
+	if err != nil {
+		c.cancel(nil)
+		return nil, c.errorf("Unable to connect to Exasol: %w", err)
 	}
 
-	err := c.wsConnect()
+	err = c.wsConnect()
 	if err != nil {
+		c.closeSSH()
+		c.cancel(nil)
 		return nil, c.errorf("Unable to connect to Exasol: %w", err)
 	}
 
+	c.emit(ConnEvent{Type: EventConnected, Host: c.host})
+
+	wsh := c.wsh
+	go func() {
+		<-c.ctx.Done()
+		if c.close(wsh) {
+			c.emit(ConnEvent{Type: EventDisconnected, Host: c.host, Err: context.Cause(c.ctx)})
+		}
+	}()
+
 	err = c.login()
 	if err != nil {
+		c.cancel(nil)
 		return nil, c.errorf("Unable to login to Exasol: %s", err)
 	}
 
+	if c.Conf.MaxIdleTime > 0 || c.Conf.MaxLifetime > 0 {
+		go c.expireConn(time.Now())
+	}
+
 	return c, nil
 }
 
-func (c *Conn) Disconnect() {
+func (c *Conn) Disconnect() { c.disconnect(nil) }
+
+// The cause (if any) is what subsequent operations fail with
+func (c *Conn) disconnect(cause error) {
 	c.log.Info("Disconnecting SessionID:", c.SessionID)
 
+	if c.Conf.RollbackOnDisconnect {
+		c.Rollback()
+	}
+	c.dropTempTables()
+
+	c.cacheMux.Lock()
 	for _, ps := range c.prepStmtCache {
 		c.closePrepStmt(ps.sth)
 	}
+	c.cacheMux.Unlock()
 	err := c.send(&request{Command: "disconnect"}, &response{})
 	if err != nil {
 		c.log.Warning("Unable to disconnect from Exasol: ", err)
 	}
-	c.wsh.Close()
-	c.wsh = nil
+	if c.close(c.wsh) {
+		c.emit(ConnEvent{Type: EventDisconnected, Host: c.host, Err: cause})
+	}
+	c.state.Store(int32(StateClosed))
+	c.cancel(cause)
+}
+
+// Host returns the host (or IP within the Host range) connected to
+func (c *Conn) Host() string { return c.host }
+
+// Returns whether the connection is still usable i.e. it
+// hasn't been disconnected, cancelled or broken
+func (c *Conn) IsAlive() bool { return c.State() == StateConnected }
+
+func (c *Conn) State() ConnState {
+	if c.ctx != nil && c.ctx.Err() != nil && ConnState(c.state.Load()) == StateConnected {
+		return StateClosed // The parent context was cancelled
+	}
+	return ConnState(c.state.Load())
+}
+
+// After a websocket error the protocol is in an unknown state so
+// the connection is closed and subsequent calls fail fast
+func (c *Conn) broken(err error) {
+	if c.cancel == nil || c.ctx.Err() != nil ||
+		!c.state.CompareAndSwap(int32(StateConnected), int32(StateBroken)) {
+		return
+	}
+	c.log.Warning("Connection is broken: ", err)
+	c.cancel(fmt.Errorf("broken by: %w", err))
+}
+
+// Disconnects the connection once it's idle or too old
+func (c *Conn) expireConn(connectedAt time.Time) {
+	interval := c.Conf.MaxIdleTime
+	if interval <= 0 || (c.Conf.MaxLifetime > 0 && c.Conf.MaxLifetime < interval) {
+		interval = c.Conf.MaxLifetime
+	}
+	ticker := time.NewTicker(interval / 4)
+	defer ticker.Stop()
+	expired := func() error {
+		if c.Conf.MaxLifetime > 0 && time.Since(connectedAt) >= c.Conf.MaxLifetime {
+			return ErrMaxLifetime
+		} else if c.Conf.MaxIdleTime > 0 &&
+			time.Since(time.Unix(0, c.lastUsed.Load())) >= c.Conf.MaxIdleTime {
+			return ErrIdleTimeout
+		}
+		return nil
+	}
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if c.tryDisconnect(expired) {
+			return
+		}
+	}
+}
+
+// Retire gracefully disconnects the connection in the background once
+// it's no longer in use (after a random delay of up to jitter).
+// Subsequent operations fail with ErrRetired, including those sent from
+// other Go routines while it's disconnecting. This is intended for when
+// credentials rotate (see PasswordFunc). Existing sessions aren't affected
+// by the rotation so retiring them with some jitter lets them be replaced
+// by connections using the new secret without a storm of reconnects.
+func (c *Conn) Retire(jitter time.Duration) {
+	go func() {
+		if jitter > 0 {
+			delay := time.Duration(mrand.Int64N(int64(jitter)))
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+		}
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		retired := func() error { return ErrRetired }
+		for !c.tryDisconnect(retired) {
+			select {
+			case <-c.ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Disconnects if the connection isn't in use and why returns an error
+// (which is then what subsequent operations fail with). The check and
+// the claiming of the connection happen under idleMux so that, even if
+// its users don't take its lock, nothing can be sent in between. Other
+// Go routines' requests fail from then on (see asyncSend).
+// Returns whether the connection is (being) disconnected.
+func (c *Conn) tryDisconnect(why func() error) bool {
+	if c.ctx.Err() != nil {
+		return true // Already gone
+	}
+	c.idleMux.Lock()
+	if c.expiry != nil {
+		c.idleMux.Unlock()
+		return true
+	}
+	cause := why()
+	if cause == nil || c.inFlight.Load() > 0 || !c.sched.tryLock() {
+		c.idleMux.Unlock()
+		return false
+	}
+	c.expiry = cause
+	c.expirer, _ = currentGoroutine()
+	c.idleMux.Unlock()
+
+	c.log.Info(cause)
+	c.disconnect(cause)
+	c.sched.unlock()
+	return true
 }
 
 func (c *Conn) GetSessionAttr() (*Attributes, error) {
@@ -155,15 +583,95 @@ func (c *Conn) GetSessionAttr() (*Attributes, error) {
 	return res.Attributes, nil
 }
 
+// SessionState is a snapshot of the session's settings
+// as captured by SaveSessionState
+type SessionState struct {
+	Attributes    Attributes
+	SuppressError bool
+}
+
+// SaveSessionState captures the current session attributes (schema,
+// autocommit, timeout, formats etc) along with any client-side settings
+// so that code which temporarily changes them can put them back via
+// RestoreSessionState.
+func (c *Conn) SaveSessionState() (*SessionState, error) {
+	attr, err := c.GetSessionAttr()
+	if err != nil {
+		return nil, err
+	}
+	state := &SessionState{SuppressError: c.Conf.SuppressError}
+	if attr != nil {
+		state.Attributes = *attr
+	}
+	return state, nil
+}
+
+func (c *Conn) RestoreSessionState(state *SessionState) error {
+	if state == nil {
+		return c.error("RestoreSessionState requires a SessionState")
+	}
+	c.Conf.SuppressError = state.SuppressError
+
+	a := state.Attributes
+	// As with DisableAutoCommit we roll our own map
+	// so that false/zero values are sent as well.
+	attrs := map[string]interface{}{
+		"autocommit":                  a.Autocommit,
+		"queryTimeout":                a.QueryTimeout,
+		"snapshotTransactionsEnabled": a.SnapshotTransactionsEnabled,
+		"timestampUtcEnabled":         a.TimestampUtcEnabled,
+	}
+	// Empty strings aren't valid values for these
+	for k, v := range map[string]string{
+		"currentSchema":              a.CurrentSchema,
+		"dateFormat":                 a.DateFormat,
+		"dateLanguage":               a.DateLanguage,
+		"datetimeFormat":             a.DatetimeFormat,
+		"defaultLikeEscapeCharacter": a.DefaultLikeEscapeCharacter,
+		"numericCharacters":          a.NumericCharacters,
+		"timezone":                   a.Timezone,
+		"timeZoneBehavior":           a.TimeZoneBehavior,
+	} {
+		if v != "" {
+			attrs[k] = v
+		}
+	}
+	if a.FeedbackInterval > 0 {
+		attrs["feedbackInterval"] = a.FeedbackInterval
+	}
+
+	res := &response{}
+	err := c.send(map[string]interface{}{
+		"command":    "setAttributes",
+		"attributes": attrs,
+	}, res)
+	if err != nil {
+		return c.errorf("Unable to restore session state: %s", err)
+	}
+	c.emit(ConnEvent{Type: EventAttributesChanged, Attributes: res.Attributes})
+	c.autocommit.Store(a.Autocommit)
+	c.txState.Store(txUnknown)
+
+	if a.CurrentSchema == "" {
+		_, err = c.execute("CLOSE SCHEMA", nil, "", nil, false, false)
+		if err != nil {
+			return c.errorf("Unable to restore session state: %s", err)
+		}
+	}
+	return nil
+}
+
 func (c *Conn) EnableAutoCommit() error {
 	c.log.Info("Enabling AutoCommit")
+	res := &response{}
 	err := c.send(&request{
 		Command:    "setAttributes",
 		Attributes: &Attributes{Autocommit: true},
-	}, &response{})
+	}, res)
 	if err != nil {
 		return c.errorf("Unable to enable autocommit: %s", err)
 	}
+	c.emit(ConnEvent{Type: EventAttributesChanged, Attributes: res.Attributes})
 	return nil
 }
 
@@ -172,41 +680,125 @@ func (c *Conn) DisableAutoCommit() error {
 	// We have to roll our own map because Attributes
 	// needs to have AutoCommit set to omitempty which
 	// causes autocommit=false not to be sent :-(
+	res := &response{}
 	err := c.send(map[string]interface{}{
 		"command": "setAttributes",
 		"attributes": map[string]interface{}{
 			"autocommit": false,
 		},
-	}, &response{})
+	}, res)
 	if err != nil {
 		return c.errorf("Unable to disable autocommit: %s", err)
 	}
+	c.autocommit.Store(false)
+	c.emit(ConnEvent{Type: EventAttributesChanged, Attributes: res.Attributes})
 	return nil
 }
 
+// InTransaction returns whether the session has an open transaction
+// i.e. uncommitted work that a Commit or Rollback would act upon.
+// This is tracked from the statements run (and the attributes in their
+// responses) so the session's attributes are only fetched if it's unknown
+// e.g. after a RawCommand.
+func (c *Conn) InTransaction() (bool, error) {
+	switch c.txState.Load() {
+	case txOpen:
+		return true, nil
+	case txClosed:
+		return false, nil
+	}
+	attr, err := c.GetSessionAttr()
+	if err != nil {
+		return false, err
+	}
+	return attr != nil && attr.OpenTransaction != 0, nil
+}
+
+// Rollback is a no-op if there is no open transaction
 func (c *Conn) Rollback() error {
+	if open, err := c.InTransaction(); err == nil && !open {
+		c.log.Info("No open transaction to rollback")
+		return nil
+	}
 	c.log.Info("Rolling back transaction")
-	_, err := c.execute("ROLLBACK", nil, "", nil, false)
+	_, err := c.execute("ROLLBACK", nil, "", nil, false, false)
 	if err != nil {
 		return c.errorf("Unable to rollback: %s", err)
 	}
 	return nil
 }
 
+// Commit is a no-op if there is no open transaction
 func (c *Conn) Commit() error {
+	if open, err := c.InTransaction(); err == nil && !open {
+		c.log.Info("No open transaction to commit")
+		return nil
+	}
 	c.log.Info("Committing transaction")
-	_, err := c.execute("COMMIT", nil, "", nil, false)
+	_, err := c.execute("COMMIT", nil, "", nil, false, false)
 	if err != nil {
 		return c.errorf("Unable to commit: %s", err)
 	}
 	return nil
 }
 
+// Transaction runs fn within a transaction committing it if fn returns
+// nil and rolling it back if fn returns an error (or panics). Autocommit
+// is disabled for the duration if need be.
+//
+// Exasol has no savepoints so nested Transaction calls (on the same Conn)
+// simply join the outermost transaction rather than committing early.
+// An error from a nested fn rolls back the outer transaction's work too
+// once it's returned up to it. If it's swallowed along the way the
+// nested work is not undone and gets committed with the rest.
+func (c *Conn) Transaction(fn func() error) (err error) {
+	if c.txDepth > 0 {
+		c.txDepth++
+		defer func() { c.txDepth-- }()
+		return fn()
+	}
+
+	attr, err := c.GetSessionAttr()
+	if err != nil {
+		return err
+	}
+	if attr.Autocommit {
+		if err = c.DisableAutoCommit(); err != nil {
+			return err
+		}
+		defer c.EnableAutoCommit()
+	}
+	c.txDepth = 1
+	defer func() {
+		c.txDepth = 0
+		if r := recover(); r != nil {
+			c.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(); err != nil {
+		c.Rollback()
+		return err
+	}
+	return c.Commit()
+}
+
 // TODO change optional args into an ExecConf struct
 // Optional args are binds, default schema, colDefs, isColumnar flag
 // 1) The binds are data bindings for statements containing placeholders.
 //    You can either specify it as []interface{} if there's only one row
 //    or as [][]interface{} if there are multiple rows.
+//    []byte values are hex encoded for HASHTYPE columns (base64 otherwise)
+//    and io.Reader values are read in as strings. time.Time values are
+//    formatted as DATE or TIMESTAMP literals (see ConnConf.TimestampUTC).
+//    Non-ASCII strings bound to ASCII columns are rejected before
+//    anything is sent. Other values are coerced to the parameter types
+//    where need be (see ConnConf.RawBinds).
+//    A slice of structs (or struct pointers) can be given instead with each
+//    struct being a row. Their fields are bound in declaration order unless
+//    the statement uses :name placeholders, which are matched to fields the
+//    same way QueryStruct matches columns.
 // 2) Specifying the default schema allows you to use non-schema-qualified
 //    table identifiers in the statement even when you have no schema currently open.
 // 3) The colDefs option expects a []DataTypes. This is only necessary if you are
@@ -215,7 +807,26 @@ func (c *Conn) Commit() error {
 // 4) The isColumnar boolean indicates whether the binds specified in the
 //    first optional arg are in columnar format (By default the are in row format.)
 func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err error) {
+	res, err := c.ExecuteResult(sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected, nil
+}
+
+// ExecResult holds the details of an executed statement
+type ExecResult struct {
+	RowsAffected int64         // Of the first result (as returned by Execute)
+	NumResults   int           // Number of results returned by the server
+	ResultTypes  []string      // "rowCount" or "resultSet" for each result
+	Duration     time.Duration // Client-measured round trip time
+}
+
+// ExecuteResult is the same as Execute (and takes the same optional args)
+// but returns an ExecResult with more details about the execution.
+func (c *Conn) ExecuteResult(sql string, args ...interface{}) (*ExecResult, error) {
 	var binds [][]interface{}
+	var structs interface{}
 	if len(args) > 0 && args[0] != nil {
 		switch b := args[0].(type) {
 		case [][]interface{}:
@@ -223,7 +834,10 @@ func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err
 		case []interface{}:
 			binds = append(binds, b)
 		default:
-			return 0, c.error("Execute's 2nd param (binds) must be []interface{} or [][]interface{}")
+			if !isStructBinds(b) {
+				return nil, c.error("Execute's 2nd param (binds) must be []interface{}, [][]interface{} or a slice of structs")
+			}
+			structs = b
 		}
 	}
 	var schema string
@@ -232,7 +846,7 @@ func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err
 		case string:
 			schema = s
 		default:
-			return 0, c.error("Execute's 3nd param (schema) must be a string")
+			return nil, c.error("Execute's 3nd param (schema) must be a string")
 		}
 	}
 	var dataTypes []DataType
@@ -241,7 +855,7 @@ func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err
 		case []DataType:
 			dataTypes = d
 		default:
-			return 0, c.error("Execute's 4th param (data types) must be a []DataType")
+			return nil, c.error("Execute's 4th param (data types) must be a []DataType")
 		}
 	}
 	isColumnar := false // Whether or not the passed-in binds are columnar
@@ -250,32 +864,112 @@ func (c *Conn) Execute(sql string, args ...interface{}) (rowsAffected int64, err
 		case bool:
 			isColumnar = ic
 		default:
-			return 0, c.error("Execute's 5th param (isColumnar) must be a boolean")
+			return nil, c.error("Execute's 5th param (isColumnar) must be a boolean")
+		}
+	}
+	if structs != nil {
+		if isColumnar {
+			return nil, c.error("Execute's struct binds can't be columnar")
+		}
+		var err error
+		sql, binds, err = structBinds(sql, structs)
+		if err != nil {
+			return nil, c.errorf("Unable to bind structs: %s", err)
 		}
 	}
+	if c.Conf.StrictBinds && len(args) > 0 && args[0] != nil && noBindData(binds) {
+		return nil, c.errorf("Unable to Execute: %w", ErrNoBindData)
+	}
 
-	res, err := c.execute(sql, binds, schema, dataTypes, isColumnar)
+	start := time.Now()
+	res, err := c.execute(sql, binds, schema, dataTypes, isColumnar, false)
 	if err != nil {
-		return 0, c.errorf("Unable to Execute: %s", err)
-	} else if res.ResponseData.NumResults > 0 {
-		return res.ResponseData.Results[0].RowCount, nil
+		return nil, c.errorf("Unable to Execute: %w", err)
+	}
+	execRes := &ExecResult{Duration: time.Since(start)}
+	if res.ResponseData != nil {
+		execRes.NumResults = int(res.ResponseData.NumResults)
+		for _, r := range res.ResponseData.Results {
+			execRes.ResultTypes = append(execRes.ResultTypes, r.ResultType)
+		}
+		if len(res.ResponseData.Results) > 0 {
+			execRes.RowsAffected = res.ResponseData.Results[0].RowCount
+		}
 	}
-	return 0, nil
+	return execRes, nil
 }
 
-// Optional args are binds, and default schema
+// Validate compiles the statement (by preparing it and closing it again)
+// without executing it, returning any syntax or semantic errors e.g.
+// unknown tables or columns. Like Execute the optional schema allows
+// non-schema-qualified identifiers to be resolved. Validation failures
+// aren't logged since they're an expected outcome.
+func (c *Conn) Validate(sql string, schema ...string) error {
+	var s string
+	if len(schema) > 0 {
+		s = schema[0]
+	}
+	ps, err := c.createPrepStmt(s, sql)
+	if err != nil {
+		return fmt.Errorf("Invalid SQL: %w", err)
+	}
+	return c.closePrepStmt(ps.sth)
+}
+
+// Optional args are binds, default schema, and fetch options
 // 1) The binds are data bindings for queries containing placeholders.
 //    You can specify it []interface{}
 // 2) Specifying the default schema allows you to use non-schema-qualified
 //    table identifiers in the statement even when you have no schema currently open.
+// 3) The FetchOpts override the connection's ConnConf.FetchOpts for this query.
+//
+// The rows are fetched in the background so there's no way to return an
+// error part way through other than by panicking. Use Query or FetchRows
+// to have such errors returned instead. (Fetches exceeding MaxResponseSize
+// are retried in smaller chunks so they only fail if a chunk of 64KB
+// would still exceed it.)
 func (c *Conn) FetchChan(sql string, args ...interface{}) (<-chan []interface{}, error) {
+	rs, opts, err := c.query(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	ch := make(chan []interface{}, 1000)
+	go func() {
+		if err := c.resultsToChan(rs, ch, opts, nil); err != nil {
+			panic(err)
+		}
+	}()
+	return ch, nil
+}
+
+// Executes the query with FetchChan's optional args and streams the
+// results into the returned chan. Closing done stops the streaming early
+// although the chan must still be drained to know that it has stopped.
+// Once it's closed the result set's fetchErr says whether it failed.
+func (c *Conn) fetch(sql string, args []interface{}, done <-chan struct{}) (
+	<-chan []interface{}, *resultSet, error,
+) {
+	rs, opts, err := c.query(sql, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch := make(chan []interface{}, 1000)
+	go c.resultsToChan(rs, ch, opts, done)
+
+	return ch, rs, nil
+}
+
+// Executes the query with FetchChan's optional args returning the
+// (possibly still open) result set along with the fetch options to use.
+func (c *Conn) query(sql string, args []interface{}) (*resultSet, FetchOpts, error) {
 	var binds []interface{}
 	if len(args) > 0 && args[0] != nil {
 		switch b := args[0].(type) {
 		case []interface{}:
 			binds = b
 		default:
-			return nil, c.error("Fetch's 2nd param (binds) must be []interface{}")
+			return nil, FetchOpts{}, c.error("Fetch's 2nd param (binds) must be []interface{}")
 		}
 	}
 	var schema string
@@ -284,62 +978,229 @@ func (c *Conn) FetchChan(sql string, args ...interface{}) (<-chan []interface{},
 		case string:
 			schema = s
 		default:
-			return nil, c.error("Fetch's 3nd param (schema) must be a string")
+			return nil, FetchOpts{}, c.error("Fetch's 3nd param (schema) must be a string")
+		}
+	}
+	opts := c.Conf.FetchOpts
+	if len(args) > 2 && args[2] != nil {
+		switch o := args[2].(type) {
+		case FetchOpts:
+			opts = o
+		default:
+			return nil, FetchOpts{}, c.error("Fetch's 4th param (fetch options) must be a FetchOpts")
 		}
 	}
 
-	resp, err := c.execute(sql, [][]interface{}{binds}, schema, nil, false)
+	resp, err := c.execute(sql, [][]interface{}{binds}, schema, nil, false, c.wantNumbers(opts))
 	if err != nil {
-		return nil, c.errorf("Unable to Fetch: %s", err)
+		return nil, FetchOpts{}, c.errorf("Unable to Fetch: %w", err)
 	}
 	respData := resp.ResponseData
-	if respData.NumResults != 1 {
-		return nil, c.errorf("Unexpected numResults: %v", respData.NumResults)
+	if len(respData.Results) != 1 {
+		return nil, FetchOpts{}, c.errorf("Unable to Fetch: %w", &ProtocolError{
+			"execute", fmt.Sprintf("expected 1 result but got %d", len(respData.Results)),
+		})
 	}
 	result := respData.Results[0]
 	if result.ResultType != resultSetType {
-		return nil, c.errorf("Unexpected result type: %v", result.ResultType)
-	}
-	if result.ResultSet == nil {
-		return nil, c.error("Missing websocket API resultset")
+		return nil, FetchOpts{}, c.errorf("Unable to Fetch: %w", &ProtocolError{
+			"execute", fmt.Sprintf("expected a resultSet but got %q", result.ResultType),
+		})
 	}
 
-	ch := make(chan []interface{}, 1000)
-	go c.resultsToChan(result.ResultSet, ch)
+	if !opts.keepNumbers {
+		c.decodeData(result.ResultSet.Data, result.ResultSet.Columns)
+	}
 
-	return ch, nil
+	return result.ResultSet, opts, nil
 }
 
 // For large datasets use FetchChan to avoid buffering all the data in memory
 func (c *Conn) FetchSlice(sql string, args ...interface{}) (res [][]interface{}, err error) {
-	resChan, err := c.FetchChan(sql, args...)
+	resChan, rs, err := c.fetch(sql, args, nil)
 	if err != nil {
 		return nil, err
 	}
 	for row := range resChan {
 		res = append(res, row)
 	}
+	if rs.fetchErr != nil {
+		return nil, rs.fetchErr
+	}
 	return res, nil
 }
 
+// FetchOne returns the single value of a query expected to return
+// exactly one row with one column. It takes the same optional args as FetchChan.
+// ErrNoRows or ErrTooManyRows is returned (wrapped) if the query returns
+// zero or multiple rows (only the latter is logged). A SQL NULL is returned as nil.
+func (c *Conn) FetchOne(sql string, args ...interface{}) (interface{}, error) {
+	resChan, rs, err := c.fetch(sql, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	row, ok := <-resChan
+	if !ok && rs.fetchErr != nil {
+		return nil, rs.fetchErr
+	} else if !ok {
+		// Not logged since it's an expected outcome
+		return nil, fmt.Errorf("Unable to FetchOne: %w", ErrNoRows)
+	}
+	extraRows := 0
+	for range resChan {
+		extraRows++
+	}
+	if rs.fetchErr != nil {
+		return nil, rs.fetchErr
+	} else if extraRows > 0 {
+		return nil, c.errorf("Unable to FetchOne: %w", ErrTooManyRows)
+	}
+	if len(row) != 1 {
+		return nil, c.errorf("Unable to FetchOne: expected 1 column but got %d", len(row))
+	}
+	return row[0], nil
+}
+
+// FetchValue is a typed version of FetchOne. Because Exasol returns all
+// numbers as float64 you can ask for any numeric type (e.g. int64) and
+// the value will be converted (as will DATEs/TIMESTAMPs into time.Time).
+// A SQL NULL results in T's zero value.
+// This is a function rather than a method because Go methods can't be generic.
+func FetchValue[T any](c *Conn, sql string, args ...interface{}) (T, error) {
+	var ret T
+	val, err := c.FetchOne(sql, args...)
+	if err != nil || val == nil {
+		return ret, err
+	}
+	if v, ok := val.(T); ok {
+		return v, nil
+	}
+	err = convertAssign(reflect.ValueOf(&ret).Elem(), val, c.timeLoc())
+	if err != nil {
+		return ret, c.errorf("Unable to FetchValue: %s", err)
+	}
+	return ret, nil
+}
+
+// FetchColumn returns the values of a query that selects a single column.
+// It takes the same optional args as FetchChan.
+func (c *Conn) FetchColumn(sql string, args ...interface{}) (res []interface{}, err error) {
+	resChan, rs, err := c.fetch(sql, args, nil)
+	if err != nil {
+		return nil, err
+	}
+	for row := range resChan {
+		if len(row) != 1 {
+			err = fmt.Errorf("expected 1 column but got %d", len(row))
+			continue // Drain the chan
+		}
+		res = append(res, row[0])
+	}
+	if rs.fetchErr != nil {
+		return nil, rs.fetchErr
+	} else if err != nil {
+		return nil, c.errorf("Unable to FetchColumn: %s", err)
+	}
+	return res, nil
+}
+
+// Exists returns whether the query returns any rows at all.
+// Rows consisting solely of NULLs still count as existing.
+// It takes the same optional args as FetchChan.
+func (c *Conn) Exists(sql string, args ...interface{}) (bool, error) {
+	sql = fmt.Sprintf(
+		"SELECT CASE WHEN EXISTS (%s) THEN TRUE ELSE FALSE END",
+		trimStmt(sql),
+	)
+	val, err := c.FetchOne(sql, args...)
+	if err != nil {
+		return false, err
+	}
+	exists, _ := val.(bool)
+	return exists, nil
+}
+
+// Count returns the number of rows returned by the query.
+// It takes the same optional args as FetchChan.
+// The count is cast to a string server-side so that it isn't
+// subject to float64 rounding above 2^53.
+func (c *Conn) Count(sql string, args ...interface{}) (int64, error) {
+	sql = fmt.Sprintf(
+		"SELECT CAST(COUNT(*) AS VARCHAR(20)) FROM (%s)",
+		trimStmt(sql),
+	)
+	val, err := c.FetchOne(sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	str, _ := val.(string)
+	count, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return 0, c.errorf("Unable to parse count %v: %s", val, err)
+	}
+	return count, nil
+}
+
 func (c *Conn) SetTimeout(timeout uint32) error {
+	res := &response{}
 	err := c.send(&request{
 		Command:    "setAttributes",
 		Attributes: &Attributes{QueryTimeout: timeout},
-	}, &response{})
+	}, res)
 	if err != nil {
 		return c.errorf("Unable to set timeout: %s", err)
 	}
+	c.emit(ConnEvent{Type: EventAttributesChanged, Attributes: res.Attributes})
 	return nil
 }
 
-// Gets a sync.Mutext lock on the handle.
-// Allows coordinating use of the handle across multiple Go routines
-func (c *Conn) Lock()   { c.mux.Lock() }
-func (c *Conn) Unlock() { c.mux.Unlock() }
+// Returns a copy of the connection's tags (see ConnConf.Tags).
+// Handy for correlating log output with the session.
+func (c *Conn) Tags() map[string]string {
+	tags := map[string]string{}
+	for k, v := range c.Conf.Tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// Returns a copy of Stats which, unlike reading Stats directly,
+// is safe to do while the Conn is being used by other Go routines
+func (c *Conn) GetStats() map[string]int {
+	c.statsMux.Lock()
+	defer c.statsMux.Unlock()
+	stats := make(map[string]int, len(c.Stats))
+	for k, v := range c.Stats {
+		stats[k] = v
+	}
+	return stats
+}
+
+// Gets a lock on the handle.
+// Allows coordinating use of the handle across multiple Go routines.
+// See LockPriority for letting some callers jump the queue
+// and TryLock/LockContext for not waiting forever.
+func (c *Conn) Lock()   { c.sched.lock(PriorityNormal); c.guardLocked() }
+func (c *Conn) Unlock() { c.guardUnlocking(); c.sched.unlock() }
 
 /*--- Private Routines ---*/
 
+// Closes the websocket and any open bulk proxies (just the once)
+// returning whether this was the call that closed it
+func (c *Conn) close(wsh WSHandler) (closed bool) {
+	c.closeOnce.Do(func() {
+		closed = true
+		c.proxyMux.Lock()
+		for p := range c.proxies {
+			p.Shutdown()
+		}
+		c.proxyMux.Unlock()
+		wsh.Close()
+		c.closeSSH()
+	})
+	return closed
+}
+
 func (c *Conn) login() error {
 	loginReq := &loginReq{
 		Command:         "login",
@@ -361,8 +1222,12 @@ func (c *Conn) login() error {
 		N: &modulus,
 		E: int(pubKeyExp),
 	}
-	password := []byte(c.Conf.Password)
+	password, err := c.password()
+	if err != nil {
+		return err
+	}
 	encPass, err := rsa.EncryptPKCS1v15(rand.Reader, &pubKey, password)
+	clear(password)
 	if err != nil {
 		return fmt.Errorf("Password encryption error: %s", err)
 	}
@@ -374,13 +1239,22 @@ func (c *Conn) login() error {
 		Username:         c.Conf.Username,
 		Password:         b64Pass,
 		UseCompression:   false, // TODO: See if we can get compression working
-		ClientName:       c.Conf.ClientName,
+		ClientName:       c.clientName(),
 		ClientVersion:    c.Conf.ClientVersion, // The version of the calling application
 		DriverName:       "go-exasol-client v" + DriverVersion,
 		ClientOs:         runtime.GOOS,
 		ClientOsUsername: osUser.Username,
 		ClientRuntime:    runtime.Version(),
-		Attributes:       &Attributes{Autocommit: true}, // Default AutoCommit to on
+		Attributes:       &Attributes{},
+	}
+
+	if c.Conf.SessionAttributes != nil {
+		*authReq.Attributes = *c.Conf.SessionAttributes
+	}
+	authReq.Attributes.Autocommit = true // Default AutoCommit to on
+
+	if c.Conf.TimestampUTC {
+		authReq.Attributes.TimestampUtcEnabled = true
 	}
 
 	if c.Conf.QueryTimeout.Seconds() > 0 {
@@ -393,36 +1267,174 @@ func (c *Conn) login() error {
 		return fmt.Errorf("Unable to authenticate: %s", err)
 	}
 
+	c.autocommit.Store(true)
+	c.txState.Store(txClosed)
 	c.SessionID = authResp.ResponseData.SessionID
 	c.Metadata = authResp.ResponseData
 	c.log.Info("Connected SessionID:", c.SessionID)
+	c.emit(ConnEvent{Type: EventAuthenticated, Host: c.host})
 	c.wsh.EnableCompression(false)
+	c.compression = CompressionState{Protocol: authReq.UseCompression}
+	if c.Conf.Compressor != nil {
+		c.compression.Compressor = c.Conf.Compressor.Name()
+	}
 
 	return nil
 }
 
+// Returns a copy of the password for the caller to zero after use
+func (c *Conn) password() ([]byte, error) {
+	switch {
+	case c.Conf.PasswordFunc != nil:
+		pass, err := c.Conf.PasswordFunc()
+		if err != nil {
+			return nil, fmt.Errorf("Unable to get password: %w", err)
+		}
+		return []byte(pass), nil
+	case c.Conf.PasswordBytes != nil:
+		pass := bytes.Clone(c.Conf.PasswordBytes)
+		clear(c.Conf.PasswordBytes)
+		c.Conf.PasswordBytes = nil
+		return pass, nil
+	}
+	return []byte(c.Conf.Password), nil
+}
+
+// The client name is suffixed with any tags e.g. "MyApp (env=prod; job=123)"
+func (c *Conn) clientName() string {
+	if len(c.Conf.Tags) == 0 {
+		return c.Conf.ClientName
+	}
+	keys := make([]string, 0, len(c.Conf.Tags))
+	for k := range c.Conf.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	tags := make([]string, len(keys))
+	for i, k := range keys {
+		tags[i] = k + "=" + c.Conf.Tags[k]
+	}
+	return strings.TrimSpace(fmt.Sprintf(
+		"%s (%s)", c.Conf.ClientName, strings.Join(tags, "; "),
+	))
+}
+
+// Applies ConnConf.SQLRewriter and QueryTags (if any) to the SQL about to be sent
+func (c *Conn) rewriteSQL(sql string) string {
+	if c.Conf.SQLRewriter != nil {
+		rewritten := c.Conf.SQLRewriter(sql)
+		if rewritten != sql {
+			c.log.Debug("Rewritten to: ", redactSQL(rewritten))
+		}
+		sql = rewritten
+	}
+	return c.tagSQL(sql)
+}
+
 func (c *Conn) execute(
 	sql string,
 	binds [][]interface{},
 	schema string,
 	dataTypes []DataType,
 	isColumnar bool,
+	numbers bool, // Whether result data needs json.Numbers (see wantNumbers)
 ) (*execRes, error) {
 	// Just a simple execute (no prepare) if there are no binds
-	if binds == nil || len(binds) == 0 ||
-		binds[0] == nil || len(binds[0]) == 0 {
-		c.log.Debug("Execute: ", sql)
+	if noBindData(binds) {
+		c.log.Debug("Execute: ", redactSQL(sql))
 		req := &execReq{
 			Command:    "execute",
 			Attributes: &Attributes{CurrentSchema: schema},
-			SqlText:    sql,
+			SqlText:    c.rewriteSQL(sql),
 		}
-		res := &execRes{}
+		res := &execRes{numbers: numbers}
 		err := c.send(req, res)
-		return res, err
+		if err != nil {
+			return res, err
+		}
+		if res.Attributes != nil {
+			c.emit(ConnEvent{Type: EventAttributesChanged, Attributes: res.Attributes})
+		}
+		return res, c.validateExecData(req.Command, res.ResponseData)
 	} else {
-		return c.executePrepStmt(sql, binds, schema, dataTypes, isColumnar)
+		return c.executePrepStmt(sql, binds, schema, dataTypes, isColumnar, numbers)
+	}
+}
+
+const (
+	txUnknown int32 = iota
+	txClosed
+	txOpen
+)
+
+// Tracks whether there's an open transaction from each request and its
+// response. Exasol only includes changed attributes in its responses so
+// a zero openTransaction is indistinguishable from an absent one. Any
+// statement run with autocommit disabled is therefore assumed to have
+// opened a transaction until a COMMIT or ROLLBACK succeeds.
+func (c *Conn) trackTx(sent, received interface{}) {
+	r := reflect.Indirect(reflect.ValueOf(received))
+	if r.Kind() != reflect.Struct {
+		return
+	}
+	ok := r.FieldByName("Status").String() == "ok"
+	var attrs *Attributes
+	if f := r.FieldByName("Attributes"); f.IsValid() {
+		attrs, _ = f.Interface().(*Attributes)
+	}
+
+	switch req := sent.(type) {
+	case *request:
+		switch {
+		case !ok || attrs == nil:
+		case req.Command == "getAttributes":
+			// These are all of the session's attributes
+			c.autocommit.Store(attrs.Autocommit)
+			c.txState.Store(txClosed + int32(min(attrs.OpenTransaction, 1)))
+			return
+		case req.Command == "setAttributes" && req.Attributes != nil && req.Attributes.Autocommit:
+			c.autocommit.Store(true)
+			c.txState.Store(txUnknown)
+		}
+	case *execReq:
+		if ClassifyStatement(req.SqlText) == StmtTransaction {
+			if ok {
+				c.txState.Store(txClosed)
+			}
+			return
+		}
+		if !c.autocommit.Load() {
+			c.txState.Store(txOpen)
+		}
+	case *execPrepStmt:
+		if !c.autocommit.Load() {
+			c.txState.Store(txOpen)
+		}
 	}
+	if ok && attrs != nil && attrs.OpenTransaction != 0 {
+		c.txState.Store(txOpen)
+	}
+}
+
+// Whether the binds (row or column-wise) hold no values
+func noBindData(binds [][]interface{}) bool {
+	return len(binds) == 0 || len(binds[0]) == 0
+}
+
+// Checks that every row (or column if they're columnar)
+// has the same number of values as the first
+func validateBinds(binds [][]interface{}, isColumnar bool) error {
+	what := "row"
+	if isColumnar {
+		what = "column"
+	}
+	for i, b := range binds {
+		if len(b) != len(binds[0]) {
+			return fmt.Errorf("Invalid binds: %s %d has %d values but %s 1 has %d",
+				what, i+1, len(b), what, len(binds[0]))
+		}
+	}
+	return nil
 }
 
 func (c *Conn) executePrepStmt(
@@ -431,7 +1443,12 @@ func (c *Conn) executePrepStmt(
 	schema string,
 	dataTypes []DataType,
 	isColumnar bool,
+	numbers bool,
 ) (*execRes, error) {
+	if err := validateBinds(binds, isColumnar); err != nil {
+		return nil, err
+	}
+
 	// There are binds so we need to send data so do a prepare + execute
 	ps, err := c.getPrepStmt(schema, sql)
 	if err != nil {
@@ -450,6 +1467,23 @@ func (c *Conn) executePrepStmt(
 	}
 	numCols := len(binds)
 	numRows := len(binds[0])
+	if numCols != len(ps.columns) {
+		if !c.Conf.CachePrepStmts {
+			c.closePrepStmt(ps.sth)
+		}
+		return nil, fmt.Errorf(
+			"Invalid binds: the statement has %d parameters but %d values were bound per row",
+			len(ps.columns), numCols,
+		)
+	}
+
+	binds, err = encodeBinds(binds, ps.columns, c.timeLoc(), !c.Conf.RawBinds)
+	if err != nil {
+		if !c.Conf.CachePrepStmts {
+			c.closePrepStmt(ps.sth)
+		}
+		return nil, err
+	}
 
 	c.log.Debugf("Executing %d x %d stmt", numCols, numRows)
 	req := &execPrepStmt{
@@ -460,14 +1494,16 @@ func (c *Conn) executePrepStmt(
 		Columns:         ps.columns,
 		Data:            binds,
 	}
-	res := &execRes{}
+	res := &execRes{numbers: numbers}
 	err = c.send(req, res)
 
 	if err != nil &&
 		regexp.MustCompile("Statement handle not found").MatchString(err.Error()) {
 		// Not sure what causes this but I've seen it happen. So just try again.
 		c.log.Warning("Statement handle not found:", ps.sth)
-		delete(c.prepStmtCache, sql)
+		c.cacheMux.Lock()
+		delete(c.prepStmtCache, prepStmtKey{schema, sql})
+		c.cacheMux.Unlock()
 		ps, err := c.getPrepStmt(schema, sql)
 		if err != nil {
 			return nil, err
@@ -479,48 +1515,210 @@ func (c *Conn) executePrepStmt(
 	if !c.Conf.CachePrepStmts {
 		c.closePrepStmt(ps.sth)
 	}
-	return res, err
+	if err != nil {
+		return res, err
+	}
+	return res, c.validateExecData(req.Command, res.ResponseData)
 }
 
-func (c *Conn) resultsToChan(rs *resultSet, ch chan<- []interface{}) {
-	defer close(ch)
+// Closing done stops the streaming early (e.g. the consumer broke out of
+// its loop) in which case the server-side result set is closed right away.
+func (c *Conn) resultsToChan(
+	rs *resultSet,
+	ch chan<- []interface{},
+	opts FetchOpts,
+	done <-chan struct{},
+) (err error) {
+	defer func() {
+		rs.fetchErr = err
+		close(ch)
+	}()
 
 	// If the resultset < 1000 rows and < 64MB then rs.Data is defined and rs.ResultSetHandle is not
 	// If the resultset < 1000 rows and > 64MB then both rs.Data and rs.ResultSetHandle are defined
 	// If the resultset > 1000 rows then rs.Data is not defined and rs.ResultSetHandle is
 	rowsRetrieved := uint64(0)
+	stopped := false
 	if rs.Data != nil && len(rs.Data) > 0 {
-		transposeToChan(ch, rs.Data)
+		// rs.Data has already been decoded by query()
+		stopped = !transposeToChan(ch, rs.Data, opts.RowPool, done)
 		rowsRetrieved = uint64(len(rs.Data[0]))
 	}
 	if rs.ResultSetHandle == 0 {
 		return
 	}
 
-	for rowsRetrieved < rs.NumRows {
-		fetchReq := &fetchReq{
+	maxBytes := opts.MaxFetchBytes
+	if maxBytes <= 0 || maxBytes > maxFetchBytes {
+		maxBytes = maxFetchBytes
+	}
+	budget := c.fetchBudget
+	if budget != nil && int64(maxBytes) > budget.size {
+		maxBytes = int(budget.size)
+	}
+	numBytes := opts.Prefetch
+	if numBytes <= 0 || numBytes > maxBytes {
+		numBytes = maxBytes
+	}
+
+	// With a FetchMemoryBudget each request's bytes are reserved
+	// until its rows have been received and decoded
+	var held, reserved int64
+	defer func() {
+		if budget != nil {
+			budget.release(held + reserved)
+		}
+	}()
+	reserve := func(wait bool) bool {
+		if budget == nil {
+			return true
+		}
+		n := int64(numBytes)
+		if wait && !budget.acquire(n, done, c.ctx.Done()) || !wait && !budget.tryAcquire(n) {
+			return false
+		}
+		reserved = n
+		return true
+	}
+
+	fetch := func(startPos uint64) (func(interface{}) error, error) {
+		return c.asyncSend(&fetchReq{
 			Command:         "fetch",
 			ResultSetHandle: rs.ResultSetHandle,
-			StartPosition:   rowsRetrieved,
-			NumBytes:        64 * 1024 * 1024, // Max allowed
-		}
-		fetchRes := &fetchRes{}
-		err := c.send(fetchReq, fetchRes)
-		if err != nil {
-			// Panic because this routine is async so no good
-			// way to tell the caller that something bad happened
-			panic(err)
+			StartPosition:   startPos,
+			NumBytes:        numBytes,
+		})
+	}
+
+	// The next chunk is requested before the current one is pushed into
+	// the chan so that the network transfer overlaps with the consumer.
+	var receiver func(interface{}) error
+	if rowsRetrieved < rs.NumRows && !stopped && reserve(true) {
+		receiver, err = fetch(rowsRetrieved)
+	}
+	for receiver != nil {
+		fetchRes := &fetchRes{numbers: c.wantNumbers(opts)}
+		err = receiver(fetchRes)
+		var tooLarge *ResponseTooLargeError
+		if errors.As(err, &tooLarge) && numBytes > minFetchBytes {
+			// Retry in smaller chunks (from then on) as the connection is still usable
+			numBytes = max(numBytes/2, minFetchBytes)
+			c.log.Debugf("Fetch exceeded MaxResponseSize, retrying with %d bytes", numBytes)
+			maxBytes = numBytes
+			if budget != nil {
+				budget.release(reserved)
+				reserved = 0
+			}
+			receiver, err = nil, nil
+			if reserve(true) {
+				receiver, err = fetch(rowsRetrieved)
+			}
+			continue
+		} else if err != nil {
+			break
 		}
+		held, reserved = reserved, 0
 		rowsRetrieved += fetchRes.ResponseData.NumRows
-		transposeToChan(ch, fetchRes.ResponseData.Data)
+
+		// Start small for latency then grow for throughput
+		if numBytes < maxBytes {
+			numBytes *= 2
+			if numBytes > maxBytes {
+				numBytes = maxBytes
+			}
+		}
+
+		// Only request the next chunk up front if the budget allows it and
+		// pushing this one won't block, since a consumer draining another
+		// chan first would otherwise leave the reservation held indefinitely
+		receiver = nil
+		fits := budget == nil || cap(ch)-len(ch) >= int(fetchRes.ResponseData.NumRows)
+		if rowsRetrieved < rs.NumRows && fits && reserve(false) {
+			if receiver, err = fetch(rowsRetrieved); err != nil {
+				break
+			}
+		}
+		if !opts.keepNumbers {
+			c.decodeData(fetchRes.ResponseData.Data, rs.Columns)
+		}
+		if budget != nil {
+			budget.release(held)
+			held = 0
+		}
+		if !transposeToChan(ch, fetchRes.ResponseData.Data, opts.RowPool, done) {
+			// Stopped early so discard the already requested chunk
+			if receiver != nil {
+				receiver(&response{})
+			}
+			break
+		}
+		if receiver == nil && rowsRetrieved < rs.NumRows {
+			if !reserve(true) {
+				break // Stopped or disconnected while waiting
+			}
+			if receiver, err = fetch(rowsRetrieved); err != nil {
+				break
+			}
+		}
 	}
 
-	closeRSReq := &closeResultSet{
-		Command:          "closeResultSet",
-		ResultSetHandles: []int{rs.ResultSetHandle},
+	if closeErr := c.closeResultSet(rs.ResultSetHandle); closeErr != nil {
+		c.log.Warning("Unable to close result set:", closeErr)
 	}
-	err := c.send(closeRSReq, &response{})
 	if err != nil {
-		c.log.Warning("Unable to close result set:", err)
+		return c.errorf("Unable to Fetch: %w", err)
+	}
+	return nil
+}
+
+// Whether result data needs decoding with its numbers as json.Number
+// rather than float64 i.e. whether decodeData has anything to do or
+// they're being kept as is
+func (c *Conn) wantNumbers(opts FetchOpts) bool {
+	return c.Conf.UseNumber || c.Conf.TypedInts || opts.keepNumbers
+}
+
+// DATEs and TIMESTAMPs come back without a time zone so they're only
+// known to be UTC with TimestampUTC. Otherwise they're taken as local time.
+func (c *Conn) timeLoc() *time.Location {
+	if c.Conf.TimestampUTC {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// Converts the json.Numbers in the (columnar) result data in-place
+// into the Go types appropriate for the column type and ConnConf
+func (c *Conn) decodeData(data [][]interface{}, cols []column) {
+	if c.Conf.UseNumber || !c.Conf.TypedInts {
+		return // They're already as wanted
+	}
+	for i, colData := range data {
+		asInt := false
+		if c.Conf.TypedInts && i < len(cols) {
+			dt := cols[i].DataType
+			asInt = dt.Type == "DECIMAL" && dt.Scale == 0 && dt.Precision <= 18
+		}
+		for j, val := range colData {
+			num, ok := val.(json.Number)
+			if !ok {
+				continue
+			}
+			if asInt {
+				if n, err := num.Int64(); err == nil {
+					colData[j] = n
+					continue
+				}
+			}
+			colData[j], _ = num.Float64()
+		}
+	}
+}
+
+func (c *Conn) closeResultSet(handle int) error {
+	closeRSReq := &closeResultSet{
+		Command:          "closeResultSet",
+		ResultSetHandles: []int{handle},
 	}
+	return c.send(closeRSReq, &response{})
 }
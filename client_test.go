@@ -2,16 +2,28 @@ package exasol
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
 )
 
 // Test various connection options:
@@ -205,7 +217,28 @@ func (s *testSuite) TestHostRanges() {
 		c, err := Connect(conf)
 		s.Nil(c)
 		if s.Error(err) {
-			s.Regexp(regexp.MustCompile(`\b127\.0\.0\.(1|2|3)\b`), err.Error())
+			for _, ip := range []string{"127.0.0.1", "127.0.0.2", "127.0.0.3"} {
+				s.Contains(err.Error(), ip+":", "Every host's error is reported")
+			}
+			s.ErrorIs(err, syscall.ECONNREFUSED)
+		}
+	}
+
+	conf.ParallelConnect = true
+	c, err := Connect(conf)
+	s.Nil(c)
+	if s.Error(err) {
+		s.Contains(err.Error(), "all 3 hosts failed")
+	}
+
+	// Mix in the real host
+	if regexp.MustCompile(`^127\.0\.0\.1$`).MatchString(*testHost) {
+		conf.Port = uint16(*testPort)
+		c, err = Connect(conf)
+		if s.NoError(err) {
+			_, err = c.Execute("SELECT 1")
+			s.NoError(err)
+			c.Disconnect()
 		}
 	}
 }
@@ -522,6 +555,35 @@ func (wsh *testWSHandler) ReadJSON(resp interface{}) error { return nil }
 func (wsh *testWSHandler) EnableCompression(e bool)        {}
 func (wsh *testWSHandler) Close()                          {}
 
+// Queues up the messages written so they can be read back
+type frameWSHandler struct {
+	testWSHandler
+	frames []*bytes.Buffer
+}
+
+type frameWriter struct {
+	*bytes.Buffer
+	wsh *frameWSHandler
+}
+
+func (w frameWriter) Close() error {
+	w.wsh.frames = append(w.wsh.frames, w.Buffer)
+	return nil
+}
+
+func (wsh *frameWSHandler) NextWriter() (io.WriteCloser, error) {
+	return frameWriter{&bytes.Buffer{}, wsh}, nil
+}
+
+func (wsh *frameWSHandler) NextReader() (io.Reader, error) {
+	if len(wsh.frames) == 0 {
+		return nil, io.EOF
+	}
+	frame := wsh.frames[0]
+	wsh.frames = wsh.frames[1:]
+	return frame, nil
+}
+
 func (s *testSuite) TestWSHandler() {
 	conf := s.connConf()
 	conf.SuppressError = true
@@ -531,3 +593,1270 @@ func (s *testSuite) TestWSHandler() {
 		s.Contains(err.Error(), "Connecting in test handler", "Got error")
 	}
 }
+
+func (s *testSuite) TestMaxResponseSize() {
+	frames := &frameWSHandler{}
+	wsh := &limitWSHandler{FrameWSHandler: frames, limit: 100}
+	frames.frames = []*bytes.Buffer{
+		bytes.NewBufferString(`{"status":"ok","responseData":"` + strings.Repeat("a", 100) + `"}`),
+		bytes.NewBufferString(`{"status":"ok"}`),
+	}
+	resp := &response{}
+	s.ErrorIs(wsh.ReadJSON(resp), errResponseTooLarge)
+	s.NoError(wsh.ReadJSON(resp), "The next response is fine")
+	s.Equal("ok", resp.Status)
+
+	conf := s.connConf()
+	conf.MaxResponseSize = 64 * 1024
+	exa, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer exa.Disconnect()
+	_, err = exa.FetchSlice("SELECT LPAD('a', 2000, 'a') FROM VALUES BETWEEN 1 AND 1000")
+	var tooLarge *ResponseTooLargeError
+	if s.ErrorAs(err, &tooLarge) {
+		s.Contains([]string{"execute", "fetch"}, tooLarge.Command)
+		s.Contains(err.Error(), "MaxFetchBytes")
+	}
+	s.True(exa.IsAlive())
+	got, err := exa.FetchSlice("SELECT 1 FROM dual")
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(1)}}, got)
+}
+
+func (s *testSuite) TestFetchOpts() {
+	payload := [][]interface{}{{}, {}}
+	for i := 0; i < 2500; i++ {
+		payload[0] = append(payload[0], float64(i))
+		payload[1] = append(payload[1], "a")
+	}
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo VALUES (?,?)", payload, nil, nil, true)
+
+	// Start with a tiny fetch so multiple growing fetches are needed
+	opts := FetchOpts{Prefetch: 1024, MaxFetchBytes: 16 * 1024}
+	got, err := exa.FetchSlice("SELECT * FROM foo ORDER BY id", nil, s.schema, opts)
+	if s.NoError(err) {
+		s.Equal(Transpose(payload), got)
+	}
+
+	_, err = exa.FetchSlice("SELECT * FROM foo", nil, s.schema, "asdf")
+	if s.Error(err) {
+		s.Contains(err.Error(), "must be a FetchOpts")
+	}
+}
+
+func (s *testSuite) TestFetchRowPool() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo VALUES (1,'a'),(2,'b'),(3,'c')")
+
+	pool := &sync.Pool{}
+	opts := FetchOpts{RowPool: pool}
+	got, err := exa.FetchChan("SELECT * FROM foo ORDER BY id", nil, s.schema, opts)
+	if s.NoError(err) {
+		var res [][]interface{}
+		for row := range got {
+			res = append(res, []interface{}{row[0], row[1]})
+			pool.Put(row)
+		}
+		expect := [][]interface{}{
+			{float64(1), "a"},
+			{float64(2), "b"},
+			{float64(3), "c"},
+		}
+		s.Equal(expect, res)
+	}
+}
+
+func (s *testSuite) TestFetchOneValueColumn() {
+	exa := s.exaConn
+	exa.Conf.SuppressError = true
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo VALUES (1,'a'),(2,'b'),(3,NULL)")
+
+	got, err := exa.FetchOne("SELECT val FROM foo WHERE id = ?", []interface{}{1}, s.schema)
+	s.NoError(err)
+	s.Equal("a", got)
+
+	got, err = exa.FetchOne("SELECT val FROM foo WHERE id = 3", nil, s.schema)
+	s.NoError(err)
+	s.Nil(got, "NULL is nil")
+
+	_, err = exa.FetchOne("SELECT val FROM foo WHERE FALSE", nil, s.schema)
+	s.ErrorIs(err, ErrNoRows)
+
+	_, err = exa.FetchOne("SELECT val FROM foo", nil, s.schema)
+	s.ErrorIs(err, ErrTooManyRows)
+
+	_, err = exa.FetchOne("SELECT id, val FROM foo WHERE id = 1", nil, s.schema)
+	if s.Error(err) {
+		s.Contains(err.Error(), "expected 1 column")
+	}
+
+	cnt, err := FetchValue[int64](exa, "SELECT COUNT(*) FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.Equal(int64(3), cnt)
+
+	str, err := FetchValue[string](exa, "SELECT val FROM foo WHERE id = 2", nil, s.schema)
+	s.NoError(err)
+	s.Equal("b", str)
+
+	_, err = FetchValue[bool](exa, "SELECT val FROM foo WHERE id = 2", nil, s.schema)
+	if s.Error(err) {
+		s.Contains(err.Error(), "cannot convert")
+	}
+
+	col, err := exa.FetchColumn("SELECT id FROM foo ORDER BY id", nil, s.schema)
+	s.NoError(err)
+	s.Equal([]interface{}{float64(1), float64(2), float64(3)}, col)
+
+	_, err = exa.FetchColumn("SELECT id, val FROM foo", nil, s.schema)
+	if s.Error(err) {
+		s.Contains(err.Error(), "expected 1 column")
+	}
+}
+
+func (s *testSuite) TestExistsAndCount() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo VALUES (1,'a'),(2,'b'),(NULL,NULL)")
+
+	exists, err := exa.Exists("SELECT * FROM foo WHERE id = ?", []interface{}{1}, s.schema)
+	s.NoError(err)
+	s.True(exists)
+
+	exists, err = exa.Exists("SELECT * FROM foo WHERE id IS NULL;", nil, s.schema)
+	s.NoError(err)
+	s.True(exists, "Rows of NULLs exist")
+
+	exists, err = exa.Exists("SELECT * FROM foo WHERE FALSE", nil, s.schema)
+	s.NoError(err)
+	s.False(exists)
+
+	count, err := exa.Count("SELECT * FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.Equal(int64(3), count)
+
+	count, err = exa.Count("SELECT * FROM foo WHERE id > ?", []interface{}{1}, s.schema)
+	s.NoError(err)
+	s.Equal(int64(1), count)
+}
+
+func (s *testSuite) TestTrackTx() {
+	c := &Conn{}
+	c.autocommit.Store(true)
+	c.txState.Store(txClosed)
+	ok := &execRes{}
+	ok.Status = "ok"
+
+	c.trackTx(&execReq{Command: "execute", SqlText: "INSERT INTO t VALUES (1)"}, ok)
+	s.Equal(txClosed, c.txState.Load(), "Autocommitted")
+
+	c.autocommit.Store(false)
+	c.trackTx(&execReq{Command: "execute", SqlText: "INSERT INTO t VALUES (1)"}, ok)
+	s.Equal(txOpen, c.txState.Load())
+	c.trackTx(&execReq{Command: "execute", SqlText: "COMMIT"}, &execRes{})
+	s.Equal(txOpen, c.txState.Load(), "The COMMIT failed")
+	c.trackTx(&execReq{Command: "execute", SqlText: "COMMIT"}, ok)
+	s.Equal(txClosed, c.txState.Load())
+	c.trackTx(&execPrepStmt{Command: "executePreparedStatement"}, ok)
+	s.Equal(txOpen, c.txState.Load())
+
+	c.trackTx(&request{Command: "getAttributes"}, &response{Status: "ok", Attributes: &Attributes{Autocommit: true}})
+	s.Equal(txClosed, c.txState.Load())
+	s.True(c.autocommit.Load())
+	c.trackTx(&request{Command: "getAttributes"}, &response{Status: "ok", Attributes: &Attributes{OpenTransaction: 1}})
+	s.Equal(txOpen, c.txState.Load())
+	s.False(c.autocommit.Load())
+
+	c.trackTx(&request{Command: "setAttributes", Attributes: &Attributes{Autocommit: true}}, &response{Status: "ok", Attributes: &Attributes{}})
+	s.Equal(txUnknown, c.txState.Load())
+	s.True(c.autocommit.Load())
+}
+
+func (s *testSuite) TestInTransaction() {
+	conf := s.connConf()
+	conf.RollbackOnDisconnect = true
+	c, err := Connect(conf)
+	s.Nil(err)
+	c.DisableAutoCommit()
+
+	open, err := c.InTransaction()
+	s.NoError(err)
+	s.False(open, "Nothing open yet")
+	s.NoError(c.Commit(), "Commit is a no-op")
+	s.NoError(c.Rollback(), "Rollback is a no-op")
+
+	c.Execute("CREATE TABLE " + s.qschema + ".foo ( id INT )")
+	open, err = c.InTransaction()
+	s.NoError(err)
+	s.True(open, "Transaction is open")
+
+	// The uncommitted table should get rolled back
+	c.Disconnect()
+	exists, err := s.exaConn.Exists(
+		"SELECT * FROM exa_all_tables WHERE table_schema = 'TEST' AND table_name = 'FOO'",
+	)
+	s.NoError(err)
+	s.False(exists, "Rolled back on disconnect")
+}
+
+func (s *testSuite) TestTransaction() {
+	c, err := Connect(s.connConf())
+	if !s.NoError(err) {
+		return
+	}
+	defer c.Disconnect()
+	c.Execute("OPEN SCHEMA " + s.qschema)
+	c.Execute("CREATE TABLE foo ( id INT )")
+	count := func() int64 {
+		n, err := s.exaConn.Count("SELECT * FROM foo", nil, s.schema)
+		s.NoError(err)
+		return n
+	}
+
+	s.NoError(c.Transaction(func() error {
+		_, err := c.Execute("INSERT INTO foo VALUES (1)")
+		return err
+	}))
+	s.Equal(int64(1), count(), "Committed")
+	attr, _ := c.GetSessionAttr()
+	s.True(attr.Autocommit, "Autocommit restored")
+
+	failed := errors.New("failed")
+	s.Equal(failed, c.Transaction(func() error {
+		c.Execute("INSERT INTO foo VALUES (2)")
+		return failed
+	}))
+	s.Equal(int64(1), count(), "Rolled back")
+
+	// Nested calls join the outer transaction
+	err = c.Transaction(func() error {
+		c.Execute("INSERT INTO foo VALUES (3)")
+		err := c.Transaction(func() error {
+			_, err := c.Execute("INSERT INTO foo VALUES (4)")
+			return err
+		})
+		s.NoError(err)
+		open, _ := c.InTransaction()
+		s.True(open, "The nested call didn't commit")
+		s.Equal(int64(1), count())
+		return c.Transaction(func() error { return failed })
+	})
+	s.Equal(failed, err)
+	s.Equal(int64(1), count(), "The nested error rolled it all back")
+
+	s.Panics(func() {
+		c.Transaction(func() error {
+			c.Execute("INSERT INTO foo VALUES (5)")
+			panic("oops")
+		})
+	})
+	s.Equal(int64(1), count(), "Rolled back upon panic")
+	s.NoError(c.Transaction(func() error { return nil }), "No longer nested")
+}
+
+func (s *testSuite) TestSessionState() {
+	conf := s.connConf()
+	c, err := Connect(conf)
+	s.Nil(err)
+	defer c.Disconnect()
+
+	state, err := c.SaveSessionState()
+	s.NoError(err)
+	s.True(state.Attributes.Autocommit)
+	s.Equal("", state.Attributes.CurrentSchema)
+
+	c.Conf.SuppressError = true
+	c.DisableAutoCommit()
+	c.SetTimeout(10)
+	c.Execute("OPEN SCHEMA " + s.qschema)
+
+	s.NoError(c.RestoreSessionState(state))
+	s.False(c.Conf.SuppressError)
+	attr, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.True(attr.Autocommit, "Autocommit restored")
+	s.Equal(uint32(0), attr.QueryTimeout, "Timeout restored")
+	s.Equal("", attr.CurrentSchema, "Schema restored")
+}
+
+func (s *testSuite) TestConnTags() {
+	conf := s.connConf()
+	conf.ClientName = "MyTester"
+	conf.ClientVersion = "123"
+	conf.Tags = map[string]string{"job": "42", "env": "test"}
+	c, err := Connect(conf)
+	s.Nil(err, "No connection errors")
+	defer c.Disconnect()
+
+	got, _ := c.FetchOne(`
+		SELECT client
+		FROM exa_user_sessions
+		WHERE session_id = CURRENT_SESSION
+	`)
+	s.Equal("MyTester (env=test; job=42) 123", got, "Tags are in the client name")
+
+	tags := c.Tags()
+	s.Equal(conf.Tags, tags)
+	tags["job"] = "43"
+	s.Equal("42", c.Tags()["job"], "Tags returns a copy")
+}
+
+func (s *testSuite) TestConnSessionAttributes() {
+	conf := s.connConf()
+	conf.SessionAttributes = &Attributes{
+		DateFormat:        "DD.MM.YYYY",
+		NumericCharacters: ",.",
+		QueryTimeout:      7,
+	}
+	c, err := Connect(conf)
+	s.Nil(err, "No connection errors")
+	defer c.Disconnect()
+
+	attr, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.Equal("DD.MM.YYYY", attr.DateFormat)
+	s.Equal(",.", attr.NumericCharacters)
+	s.Equal(uint32(7), attr.QueryTimeout)
+	s.True(attr.Autocommit, "Autocommit still defaults to on")
+
+	got, err := c.FetchOne("SELECT TO_CHAR(DATE '2020-01-31')")
+	s.NoError(err)
+	s.Equal("31.01.2020", got)
+}
+
+func (s *testSuite) TestExecuteResult() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+
+	res, err := exa.ExecuteResult("INSERT INTO foo VALUES (?,?)", [][]interface{}{{1, "a"}, {2, "b"}}, s.schema)
+	if s.NoError(err) {
+		s.Equal(int64(2), res.RowsAffected)
+		s.Equal(1, res.NumResults)
+		s.Equal([]string{"rowCount"}, res.ResultTypes)
+		s.Greater(res.Duration, time.Duration(0))
+	}
+
+	res, err = exa.ExecuteResult("SELECT * FROM foo", nil, s.schema)
+	if s.NoError(err) {
+		s.Equal([]string{"resultSet"}, res.ResultTypes)
+	}
+
+	exa.Conf.SuppressError = true
+	res, err = exa.ExecuteResult("ASDF")
+	s.Error(err)
+	s.Nil(res)
+}
+
+func (s *testSuite) TestStrictBinds() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT )")
+	_, err := exa.Execute("INSERT INTO foo SELECT 1", [][]interface{}{}, s.schema)
+	s.NoError(err, "Executed without binds by default")
+
+	exa.Conf.StrictBinds = true
+	defer func() { exa.Conf.StrictBinds = false }()
+	for _, binds := range []interface{}{
+		[][]interface{}{}, []interface{}{}, [][]interface{}{{}}, [][]interface{}(nil), []struct{ ID int }{},
+	} {
+		_, err = exa.Execute("INSERT INTO foo VALUES (?)", binds, s.schema)
+		s.ErrorIs(err, ErrNoBindData, "%#v", binds)
+	}
+	_, err = exa.Execute("INSERT INTO foo SELECT 1", nil, s.schema)
+	s.NoError(err, "nil binds are fine")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?)", []interface{}{2}, s.schema)
+	s.NoError(err)
+	s.Equal(float64(3), s.fetch("SELECT COUNT(*) FROM foo")[0][0])
+}
+
+func (s *testSuite) TestRaggedBinds() {
+	s.EqualError(
+		validateBinds([][]interface{}{{1, "a"}, {2}}, false),
+		"Invalid binds: row 2 has 1 values but row 1 has 2",
+	)
+	s.EqualError(
+		validateBinds([][]interface{}{{1, 2}, {"a", "b", "c"}}, true),
+		"Invalid binds: column 2 has 3 values but column 1 has 2",
+	)
+	s.NoError(validateBinds([][]interface{}{{1, "a"}, {2, "b"}}, false))
+
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	_, err := exa.Execute("INSERT INTO foo VALUES (?,?)", [][]interface{}{{1, "a"}, {2, "b", "c"}}, s.schema)
+	s.ErrorContains(err, "row 2 has 3 values but row 1 has 2")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?,?)", [][]interface{}{{1, 2}, {"a"}}, s.schema, nil, true)
+	s.ErrorContains(err, "column 2 has 1 values but column 1 has 2")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?,?)", []interface{}{1, "a", "extra"}, s.schema)
+	s.ErrorContains(err, "the statement has 2 parameters but 3 values were bound per row")
+}
+
+func (s *testSuite) TestTypedInts() {
+	s.execute("CREATE TABLE " + s.qschema + ".foo ( id DECIMAL(18,0), big DECIMAL(20,0), val DECIMAL(10,2) )")
+	s.execute("INSERT INTO " + s.qschema + ".foo VALUES " +
+		"(9007199254740992, 1, 1.5), (9007199254740993, 2, 2.5), (999999999999999999, 3, 3.5)")
+	sql := "SELECT * FROM " + s.qschema + ".foo ORDER BY id"
+
+	// By default values above 2^53 lose precision
+	got := s.fetch(sql)
+	s.Equal(float64(9007199254740992), got[1][0], "2^53+1 rounds down")
+
+	conf := s.connConf()
+	conf.TypedInts = true
+	c, err := Connect(conf)
+	s.Nil(err)
+	defer c.Disconnect()
+
+	got, err = c.FetchSlice(sql)
+	s.NoError(err)
+	expect := [][]interface{}{
+		{int64(9007199254740992), float64(1), float64(1.5)},
+		{int64(9007199254740993), float64(2), float64(2.5)},
+		{int64(999999999999999999), float64(3), float64(3.5)},
+	}
+	s.Equal(expect, got, "Only DECIMAL(<=18,0) are int64")
+
+	// Binding it back in round trips exactly
+	id, err := FetchValue[int64](c, "SELECT id FROM "+s.qschema+".foo WHERE id = ?", []interface{}{got[1][0]})
+	s.NoError(err)
+	s.Equal(int64(9007199254740993), id)
+}
+
+func (s *testSuite) TestDecodeJSON() {
+	msg := `{"status":"ok","responseData":{"numRows":1,"data":[[9007199254740993],[1.5]]}}`
+	res := &fetchRes{}
+	s.NoError(decodeJSON(strings.NewReader(msg), res))
+	s.Equal([][]interface{}{{float64(9007199254740992)}, {1.5}}, res.ResponseData.Data, "Fast path")
+
+	res = &fetchRes{numbers: true}
+	s.NoError(decodeJSON(strings.NewReader(msg), res))
+	s.Equal(uint64(1), res.ResponseData.NumRows)
+	s.Equal([][]interface{}{{json.Number("9007199254740993")}, {json.Number("1.5")}}, res.ResponseData.Data)
+
+	c := &Conn{Conf: ConnConf{TypedInts: true}}
+	s.True(c.wantNumbers(FetchOpts{}))
+	c.decodeData(res.ResponseData.Data, []column{
+		{DataType: DataType{Type: "DECIMAL", Precision: 18}},
+		{DataType: DataType{Type: "DECIMAL", Precision: 18, Scale: 1}},
+	})
+	s.Equal([][]interface{}{{int64(9007199254740993)}, {1.5}}, res.ResponseData.Data)
+	s.False((&Conn{}).wantNumbers(FetchOpts{}))
+	s.True((&Conn{}).wantNumbers(FetchOpts{keepNumbers: true}))
+}
+
+func (s *testSuite) TestUseNumber() {
+	conf := s.connConf()
+	conf.UseNumber = true
+	conf.TypedInts = true // Overridden by UseNumber
+	c, err := Connect(conf)
+	s.Nil(err)
+	defer c.Disconnect()
+
+	got, err := c.FetchSlice("SELECT 9007199254740993, 1.25, 'a'")
+	s.NoError(err)
+	s.Equal([][]interface{}{{json.Number("9007199254740993"), json.Number("1.25"), "a"}}, got)
+
+	id, err := FetchValue[int64](c, "SELECT 9007199254740993")
+	s.NoError(err)
+	s.Equal(int64(9007199254740993), id)
+
+	str, err := FetchValue[string](c, "SELECT 1.25")
+	s.NoError(err)
+	s.Equal("1.25", str)
+}
+
+func (s *testSuite) TestBinaryBinds() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( ht HASHTYPE(4 BYTE), b64 VARCHAR(20), txt VARCHAR(5) )", nil, s.schema)
+
+	bin := []byte{0xde, 0xad, 0xbe, 0xef}
+	row := []interface{}{bin, bin, strings.NewReader("hello")}
+	got, err := exa.Execute("INSERT INTO foo VALUES (?,?,?)", row, s.schema)
+	s.NoError(err)
+	s.Equal(int64(1), got)
+	s.Equal(bin, row[0], "Binds are not modified")
+
+	data := s.fetch("SELECT * FROM " + s.qschema + ".foo")
+	s.Equal([][]interface{}{{"deadbeef", "3q2+7w==", "hello"}}, data)
+
+	exa.Conf.SuppressError = true
+	_, err = exa.Execute("INSERT INTO foo (txt) VALUES (?)", []interface{}{strings.NewReader("too long")}, s.schema)
+	if s.Error(err) {
+		s.Contains(err.Error(), "exceeds the column size")
+	}
+}
+
+func (s *testSuite) TestReadBind() {
+	str, err := readBind(strings.NewReader("ééééé"), 5)
+	s.NoError(err)
+	s.Equal("ééééé", str, "Sized in characters rather than bytes")
+	str, err = readBind(strings.NewReader("日本語𝄞"), 4)
+	s.NoError(err)
+	s.Equal("日本語𝄞", str)
+
+	_, err = readBind(strings.NewReader("éééééé"), 5)
+	s.EqualError(err, "value exceeds the column size of 5")
+	_, err = readBind(strings.NewReader(strings.Repeat("a", 100)), 5)
+	s.EqualError(err, "value exceeds the column size of 5")
+
+	str, err = readBind(strings.NewReader("no limit"), 0)
+	s.NoError(err)
+	s.Equal("no limit", str)
+}
+
+func (s *testSuite) TestASCIIBinds() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( a VARCHAR(10) ASCII, u VARCHAR(10) UTF8 )", nil, s.schema)
+
+	rows, err := exa.FetchRows("SELECT * FROM foo", nil, s.schema)
+	if s.NoError(err) {
+		cols := rows.ColumnTypes()
+		s.Equal("ASCII", cols[0].DataType.CharacterSet)
+		s.Equal("UTF8", cols[1].DataType.CharacterSet)
+		rows.Close()
+	}
+
+	_, err = exa.Execute("INSERT INTO foo VALUES (?,?)", []interface{}{"abc", "héllo"}, s.schema)
+	s.NoError(err, "Non-ASCII is fine in UTF8 columns")
+
+	exa.Conf.SuppressError = true
+	_, err = exa.Execute("INSERT INTO foo VALUES (?,?)", [][]interface{}{{"ok", "ok"}, {"héllo", "ok"}}, s.schema)
+	if s.Error(err) {
+		s.Contains(err.Error(), "Non-ASCII value in row 2 for ASCII column 1 (VARCHAR)")
+		s.NotContains(err.Error(), "héllo", "The value isn't reported")
+	}
+	s.Equal([][]interface{}{{"abc", "héllo"}}, s.fetch("SELECT * FROM "+s.qschema+".foo"), "Nothing was inserted")
+}
+
+func (s *testSuite) TestSQLRewriter() {
+	conf := s.connConf()
+	var seen []string
+	conf.SQLRewriter = func(sql string) string {
+		seen = append(seen, sql)
+		return strings.ReplaceAll(sql, "{tenant}", s.qschema)
+	}
+	exa, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer exa.Disconnect()
+
+	_, err = exa.Execute("CREATE TABLE {tenant}.foo ( id INT )")
+	s.NoError(err)
+	_, err = exa.Execute("INSERT INTO {tenant}.foo VALUES (?)", []interface{}{1})
+	s.NoError(err, "Prepared statements too")
+	err = exa.BulkExecute(
+		"IMPORT INTO {tenant}.foo FROM CSV AT '%s' FILE 'data.csv'",
+		bytes.NewBufferString("2\n"),
+	)
+	s.NoError(err, "And bulk transfers")
+	got, err := exa.FetchSlice("SELECT * FROM {tenant}.foo ORDER BY id")
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(1)}, {float64(2)}}, got)
+	s.Contains(seen, "INSERT INTO {tenant}.foo VALUES (?)", "Given the original")
+}
+
+func (s *testSuite) TestValidate() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE " + s.qschema + ".foo ( id INT )")
+
+	s.NoError(exa.Validate("SELECT id FROM " + s.qschema + ".foo WHERE id = ?"))
+	s.NoError(exa.Validate("INSERT INTO foo VALUES (1)", s.schema))
+	s.NoError(exa.Validate("CREATE TABLE bar ( id INT )", s.schema))
+	s.Equal([][]interface{}{{float64(0)}}, s.fetch(
+		"SELECT COUNT(*) FROM "+s.qschema+".foo",
+	), "Nothing was executed")
+	exists, _ := exa.Exists("SELECT * FROM exa_all_tables WHERE table_schema = 'TEST' AND table_name = 'BAR'")
+	s.False(exists)
+
+	var srvErr *ServerError
+	err := exa.Validate("SELEC 1")
+	s.ErrorAs(err, &srvErr)
+	s.ErrorContains(err, "Invalid SQL")
+	s.Error(exa.Validate("SELECT nope FROM foo", s.schema))
+}
+
+func (s *testSuite) TestBindCoercion() {
+	exa := s.exaConn
+	exa.Execute(`CREATE TABLE foo (
+		d DECIMAL(18,0), s VARCHAR(20), i INTERVAL DAY TO SECOND
+	)`, nil, s.schema)
+
+	huge := int64(1234567890123456789) // Not exactly representable as a float64
+	_, err := exa.Execute(
+		"INSERT INTO foo VALUES (?,?,?)",
+		[]interface{}{huge, 42, 26*time.Hour + 3*time.Minute + 1500*time.Millisecond},
+		s.schema,
+	)
+	s.NoError(err)
+	s.Equal([][]interface{}{{"1234567890123456789", "42", "+01 02:03:01.500"}}, s.fetch(
+		"SELECT CAST(d AS VARCHAR(20)), s, CAST(i AS VARCHAR(30)) FROM "+s.qschema+".foo",
+	))
+
+	dec := DataType{Type: "DECIMAL", Precision: 36}
+	for _, tc := range []struct {
+		val  interface{}
+		dt   DataType
+		want string
+		ok   bool
+	}{
+		{uint64(18446744073709551615), dec, "18446744073709551615", true},
+		{int32(-5), dec, "-5", true},
+		{int64(5), DataType{Type: "DECIMAL", Precision: 9}, "", false},
+		{1.5, dec, "", false},
+		{json.Number("12.50"), dec, "12.50", true},
+		{new(big.Int).Lsh(big.NewInt(1), 100), dec, "1267650600228229401496703205376", true},
+		{big.NewRat(1, 3), DataType{Type: "DECIMAL", Precision: 36, Scale: 4}, "0.3333", true},
+		{big.NewRat(1, 2), DataType{Type: "DOUBLE"}, "0.5", true},
+		{big.NewRat(-5, 4), DataType{Type: "VARCHAR", Size: 20}, "-1.25", true},
+		{big.NewRat(3, 1), DataType{Type: "VARCHAR", Size: 20}, "3", true},
+		{2.5, DataType{Type: "VARCHAR"}, "2.5", true},
+		{true, DataType{Type: "CHAR"}, "true", true},
+		{-(time.Second + time.Millisecond), DataType{Type: "INTERVAL DAY TO SECOND"}, "-00 00:00:01.001", true},
+		{(*big.Int)(nil), dec, "", false},
+		{(*big.Float)(nil), dec, "", false},
+		{(*big.Rat)(nil), dec, "", false},
+		{time.Second, DataType{Type: "DECIMAL"}, "", false},
+		{"str", dec, "", false},
+	} {
+		got, ok := coerceBind(tc.val, tc.dt)
+		s.Equal(tc.ok, ok, "%v", tc.val)
+		s.Equal(tc.want, got, "%v", tc.val)
+	}
+
+	binds := [][]interface{}{{huge}}
+	got, err := encodeBinds(binds, []column{{DataType: dec}}, time.Local, false)
+	s.NoError(err)
+	s.Equal(huge, got[0][0], "Not coerced with RawBinds")
+
+	col := []interface{}{huge}
+	binds = [][]interface{}{col}
+	got, err = encodeBinds(binds, []column{{DataType: dec}}, time.Local, true)
+	s.NoError(err)
+	s.Equal("1234567890123456789", got[0][0])
+	s.Equal(huge, binds[0][0], "Caller's outer slice isn't modified")
+	s.Equal(huge, col[0], "Caller's column isn't modified")
+}
+
+func (s *testSuite) TestTimeBindRoundTrip() {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	ts := time.Date(2020, 1, 31, 1, 2, 3, 456000000, tokyo)
+	date := time.Date(2020, 1, 31, 0, 0, 0, 0, tokyo)
+	cols := []column{{DataType: DataType{Type: "TIMESTAMP"}}, {DataType: DataType{Type: "DATE"}}}
+
+	for _, utc := range []bool{false, true} {
+		c := &Conn{Conf: ConnConf{TimestampUTC: utc}}
+		got, err := encodeBinds([][]interface{}{{ts}, {date}}, cols, c.timeLoc(), false)
+		s.NoError(err)
+		s.Equal("2020-01-31", got[1][0], "Date isn't shifted (utc=%v)", utc)
+
+		back, err := parseTime(got[0][0].(string), c.timeLoc())
+		s.NoError(err)
+		s.True(ts.Equal(back), "Same instant read back (utc=%v): %v", utc, back)
+	}
+}
+
+func (s *testSuite) TestTimestampUTC() {
+	conf := s.connConf()
+	conf.TimestampUTC = true
+	conf.SessionAttributes = &Attributes{Timezone: "AMERICA/NEW_YORK"}
+	c, err := Connect(conf)
+	s.Nil(err)
+	defer c.Disconnect()
+
+	attr, err := c.GetSessionAttr()
+	s.NoError(err)
+	s.True(attr.TimestampUtcEnabled)
+
+	c.Execute("CREATE TABLE foo ( d DATE, ts TIMESTAMP WITH LOCAL TIME ZONE )", nil, s.schema)
+	loc, _ := time.LoadLocation("America/New_York")
+	ts := time.Date(2021, 3, 14, 1, 30, 0, 0, loc) // Just before the DST switch
+	_, err = c.Execute("INSERT INTO foo VALUES (?,?)", []interface{}{ts, ts}, s.schema)
+	s.NoError(err)
+
+	got, err := FetchValue[time.Time](c, "SELECT ts FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.True(ts.Equal(got), "Round trips across time zones")
+	s.Equal(time.UTC, got.Location())
+
+	d, err := FetchValue[string](c, "SELECT TO_CHAR(d, 'YYYY-MM-DD') FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.Equal("2021-03-14", d)
+}
+
+func (s *testSuite) TestLockPriority() {
+	c := &Conn{}
+	c.Lock()
+
+	var order []string
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	waitFor := func(name string, p Priority) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.LockPriority(p)
+			mux.Lock()
+			order = append(order, name)
+			mux.Unlock()
+			c.Unlock()
+		}()
+		// Let it queue up before the next one
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor("batch1", PriorityBatch)
+	waitFor("normal", PriorityNormal)
+	waitFor("batch2", PriorityBatch)
+	waitFor("interactive", PriorityInteractive)
+
+	c.Unlock()
+	wg.Wait()
+	s.Equal([]string{"interactive", "normal", "batch1", "batch2"}, order)
+}
+
+func (s *testSuite) TestLockContext() {
+	c := &Conn{}
+	s.True(c.TryLock())
+	s.False(c.TryLock(), "Already locked")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	s.ErrorIs(c.LockContext(ctx), context.DeadlineExceeded)
+	s.Less(time.Since(start), time.Second)
+	s.Empty(c.sched.waiters[PriorityNormal], "Gave up its place in the queue")
+
+	got := make(chan error)
+	go func() { got <- c.LockContext(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	c.Unlock()
+	s.NoError(<-got, "Handed over")
+	c.Unlock()
+
+	cancelled, cancelCause := context.WithCancelCause(context.Background())
+	cancelCause(ErrConnClosed)
+	s.ErrorIs(c.LockContext(cancelled), ErrConnClosed, "Even if it's free")
+	s.True(c.TryLock(), "Not left locked")
+	c.Unlock()
+}
+
+// Run with: go test -race
+func (s *testSuite) TestConcurrentUse() {
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	var wg sync.WaitGroup
+	conns := make([]*Conn, 4)
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := Connect(conf)
+			s.NoError(err)
+			conns[i] = c
+		}(i)
+	}
+	wg.Wait()
+
+	// Share one Conn amongst many Go routines
+	c := conns[0]
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c.Lock()
+			got, err := c.FetchOne("SELECT ? FROM dual", []interface{}{i})
+			c.Unlock()
+			s.NoError(err)
+			s.Equal(float64(i), got)
+			s.Equal(1, c.GetStats()["StmtCacheLen"])
+		}(i)
+	}
+	// Streams that are closed early shouldn't race either
+	for _, c := range conns[1:] {
+		wg.Add(1)
+		go func(c *Conn) {
+			defer wg.Done()
+			rows := c.StreamQuery("EXPORT (SELECT * FROM exa_sql_keywords) INTO CSV AT '%s' FILE 'data.csv'")
+			<-rows.Data
+			rows.Close()
+		}(c)
+	}
+	wg.Wait()
+
+	for _, c := range conns {
+		c.Disconnect()
+	}
+}
+
+func (s *testSuite) TestConnectError() {
+	// Fakes a load balancer redirecting the handshake
+	lb := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://elsewhere:8563/")
+		w.WriteHeader(http.StatusMovedPermanently)
+		w.Write([]byte("Moved to elsewhere"))
+	}))
+	defer lb.Close()
+	host, port, _ := net.SplitHostPort(lb.Listener.Addr().String())
+	portN, _ := strconv.Atoi(port)
+	conf := ConnConf{
+		Host:          host,
+		Port:          uint16(portN),
+		TLSConfig:     &tls.Config{InsecureSkipVerify: true},
+		SuppressError: true,
+	}
+
+	_, err := Connect(conf)
+	var connErr *ConnectError
+	if s.ErrorAs(err, &connErr) {
+		s.Equal(http.StatusMovedPermanently, connErr.StatusCode)
+		s.Equal("https://elsewhere:8563/", connErr.Header.Get("Location"))
+		s.Equal("Moved to elsewhere", connErr.Body)
+		s.Contains(err.Error(), "(HTTP 301 Moved Permanently) redirected to https://elsewhere:8563/")
+	}
+
+	conf.TLSConfig = &tls.Config{} // So its self-signed certificate fails verification
+	_, err = Connect(conf)
+	var certErr *tls.CertificateVerificationError
+	s.ErrorAs(err, &certErr)
+	if s.ErrorAs(err, &connErr) {
+		s.Zero(connErr.StatusCode)
+	}
+}
+
+func (s *testSuite) TestConnectContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := ConnectContext(ctx, conf)
+	s.Nil(err)
+
+	_, err = c.Execute("SELECT 1")
+	s.NoError(err)
+
+	// Cancelling aborts in-flight operations
+	rows := c.StreamQuery("EXPORT (SELECT * FROM exa_sql_keywords a, exa_sql_keywords b) INTO CSV AT '%s' FILE 'data.csv'")
+	<-rows.Data
+	cancel()
+	for range rows.Data {
+	}
+	s.Error(rows.Error)
+
+	_, err = c.Execute("SELECT 1")
+	if s.Error(err) {
+		s.Contains(err.Error(), "context canceled")
+	}
+	c.Disconnect()
+}
+
+func (s *testSuite) TestMaxIdleTimeAndLifetime() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.MaxIdleTime = 500 * time.Millisecond
+	c, err := Connect(conf)
+	s.Nil(err)
+
+	// Staying busy keeps it alive
+	for i := 0; i < 5; i++ {
+		_, err = c.Execute("SELECT 1")
+		s.NoError(err)
+		time.Sleep(200 * time.Millisecond)
+	}
+	time.Sleep(time.Second)
+	_, err = c.Execute("SELECT 1")
+	if s.Error(err) {
+		s.Contains(err.Error(), ErrIdleTimeout.Error())
+	}
+
+	conf.MaxIdleTime = 0
+	conf.MaxLifetime = 500 * time.Millisecond
+	c, err = Connect(conf)
+	s.Nil(err)
+	for i := 0; i < 5; i++ {
+		c.Execute("SELECT 1")
+		time.Sleep(200 * time.Millisecond)
+	}
+	_, err = c.Execute("SELECT 1")
+	if s.Error(err) {
+		s.Contains(err.Error(), ErrMaxLifetime.Error())
+	}
+}
+
+func (s *testSuite) TestOnStateChange() {
+	var events []ConnEventType
+	var mux sync.Mutex
+	conf := s.connConf()
+	conf.OnStateChange = func(ev ConnEvent) {
+		mux.Lock()
+		events = append(events, ev.Type)
+		mux.Unlock()
+	}
+	c, err := Connect(conf)
+	s.Nil(err)
+
+	c.DisableAutoCommit()
+	c.Execute("OPEN SCHEMA " + s.qschema)
+	c.Execute("CREATE TABLE foo (id INT)")
+	c.BulkInsert(s.schema, "foo", bytes.NewBufferString("1\n"))
+	c.Disconnect()
+
+	mux.Lock()
+	defer mux.Unlock()
+	s.Equal([]ConnEventType{
+		EventConnected,
+		EventAuthenticated,
+		EventAttributesChanged, // Autocommit
+		EventAttributesChanged, // Schema
+		EventProxyOpened,
+		EventProxyClosed,
+		EventDisconnected,
+	}, events)
+	s.Equal("proxy opened", EventProxyOpened.String())
+}
+
+func (s *testSuite) TestHostSelection() {
+	c := &Conn{Conf: ConnConf{Host: "10.0.0.1..3", HostSelection: HostRoundRobin}}
+	ips := expandHostRange(c.Conf.Host)
+	s.Equal([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, c.orderHosts(ips))
+	s.Equal([]string{"10.0.0.2", "10.0.0.3", "10.0.0.1"}, c.orderHosts(ips))
+	s.Equal([]string{"10.0.0.3", "10.0.0.1", "10.0.0.2"}, c.orderHosts(ips))
+	s.Equal([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, c.orderHosts(ips))
+
+	c.Conf.HostSelection = HostSticky
+	c.Conf.ClientName = "etl"
+	first := c.orderHosts(ips)
+	s.ElementsMatch(ips, first)
+	for i := 0; i < 5; i++ {
+		s.Equal(first, c.orderHosts(ips), "Always the same order")
+	}
+
+	c.Conf.HostSelection = HostRandom
+	s.ElementsMatch(ips, c.orderHosts(ips))
+
+	c.Conf.PreferredHost = "10.0.0.2"
+	ordered := c.orderHosts(ips)
+	s.Equal("10.0.0.2", ordered[0], "Preferred first")
+	s.ElementsMatch(ips, ordered)
+	s.Equal([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, ips, "Unchanged")
+
+	c.Conf.PreferredHost = ""
+	c.Conf.HostSelection = HostLocal
+	local := []string{"10.0.0.1", "127.0.0.1", "10.0.0.3"}
+	s.Equal("127.0.0.1", c.orderHosts(local)[0], "Local first")
+	s.ElementsMatch(ips, c.orderHosts(ips), "Else random")
+
+	s.Equal(s.exaConn.Conf.Host, s.exaConn.Host())
+	rows := s.exaConn.StreamQuery("EXPORT (SELECT 1 FROM dual) INTO CSV AT '%s' FILE 'data.csv'")
+	for range rows.Data {
+	}
+	s.Nil(rows.Error)
+	s.Equal(s.exaConn.Host(), rows.proxy.Node(), "Proxy on the connected node")
+}
+
+func (s *testSuite) TestDialer() {
+	// The ConnectTimeout applies to dialing the websocket and proxies alike
+	c := &Conn{Conf: ConnConf{ConnectTimeout: time.Nanosecond}}
+	_, err := c.dialer()(context.Background(), "tcp", s.connConf().Host+":8563")
+	s.ErrorIs(err, context.DeadlineExceeded)
+
+	c.Conf.ConnectTimeout = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.dialer()(ctx, "tcp", s.connConf().Host+":8563")
+	s.ErrorIs(err, context.Canceled)
+}
+
+func (s *testSuite) TestSSHTunnel() {
+	// To test this properly you need to set the EXA_SSH_HOST ENV to
+	// a 'user:password@host:port' that can reach the Exasol host
+	env := os.Getenv("EXA_SSH_HOST")
+	conf := s.connConf()
+	conf.SuppressError = true
+	if env == "" {
+		conf.SSH = &SSHConfig{Host: "127.0.0.1:1"}
+		_, err := Connect(conf)
+		if s.Error(err) {
+			s.Contains(err.Error(), "HostKeyCallback is required")
+		}
+		s.T().Skip("EXA_SSH_HOST must be set to 'user:pass@host:port' in order for TestSSHTunnel to run.")
+	}
+	userPass, host, _ := strings.Cut(env, "@")
+	user, pass, _ := strings.Cut(userPass, ":")
+	conf.SSH = &SSHConfig{
+		Host:            host,
+		User:            user,
+		Password:        pass,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	}
+	c, err := Connect(conf)
+	if s.NoError(err) {
+		defer c.Disconnect()
+		c.Execute("CREATE TABLE foo (id INT)", nil, s.schema)
+		s.NoError(c.BulkInsert(s.schema, "foo", bytes.NewBufferString("1\n2\n")))
+		data := new(bytes.Buffer)
+		s.NoError(c.BulkSelect(s.schema, "foo", data))
+		s.Equal("1\n2\n", data.String())
+	}
+}
+
+func (s *testSuite) TestSocketOpts() {
+	conf := s.connConf()
+	conf.Socket = SocketOpts{
+		KeepAlive:      5 * time.Second,
+		DisableNoDelay: true,
+		ReadBuffer:     1 << 20,
+		WriteBuffer:    1 << 20,
+	}
+	c, err := Connect(conf)
+	if s.NoError(err) {
+		defer c.Disconnect()
+		c.Execute("CREATE TABLE foo (id INT)", nil, s.schema)
+		s.NoError(c.BulkInsert(s.schema, "foo", bytes.NewBufferString("1\n2\n")))
+		data := new(bytes.Buffer)
+		s.NoError(c.BulkSelect(s.schema, "foo", data))
+		s.Equal("1\n2\n", data.String())
+	}
+
+	conf.Socket = SocketOpts{KeepAlive: -1}
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		c.Disconnect()
+	}
+}
+
+func (s *testSuite) TestRawCommand() {
+	exa := s.exaConn
+	var res struct {
+		Status     string
+		Attributes Attributes
+	}
+	err := exa.RawCommand(map[string]string{"command": "getAttributes"}, &res)
+	s.NoError(err)
+	s.Equal("ok", res.Status)
+	s.True(res.Attributes.Autocommit)
+
+	var execRes struct {
+		ResponseData struct {
+			Results []struct {
+				ResultSet struct {
+					Data [][]interface{}
+				}
+			}
+		}
+	}
+	err = exa.RawCommand(struct {
+		Command string `json:"command"`
+		SQLText string `json:"sqlText"`
+	}{"execute", "SELECT CURRENT_SCHEMA"}, &execRes, &Attributes{CurrentSchema: s.schema})
+	s.NoError(err)
+	s.Equal("TEST", execRes.ResponseData.Results[0].ResultSet.Data[0][0])
+
+	exa.Conf.SuppressError = true
+	err = exa.RawCommand(map[string]string{"command": "execute", "sqlText": "ASDF"}, nil)
+	var serverErr *ServerError
+	if s.ErrorAs(err, &serverErr) {
+		s.Contains(serverErr.Text, "syntax error")
+		s.Equal("42000", serverErr.SQLCode)
+	}
+	s.Error(exa.RawCommand(map[string]string{}, nil), "Missing command")
+}
+
+func (s *testSuite) TestWireLog() {
+	wireLog := &bytes.Buffer{}
+	conf := s.connConf()
+	conf.WireLog = wireLog
+	c, err := Connect(conf)
+	s.Nil(err)
+	got, err := c.FetchOne("SELECT 123")
+	s.NoError(err)
+	s.Equal(float64(123), got, "Responses are still decoded")
+	c.Disconnect()
+
+	s.Contains(wireLog.String(), `-> {"command":"execute","attributes":{},"sqlText":"SELECT 123"}`)
+	s.Contains(wireLog.String(), `<- {"status":"ok"`)
+	s.Contains(wireLog.String(), `"password":"<redacted>"`)
+	s.NotContains(wireLog.String(), *testPass)
+
+	tap := &tapWSHandler{w: wireLog}
+	wireLog.Reset()
+	tap.tap("->", []byte(`{"sqlText":"CREATE USER u IDENTIFIED BY \"s3cr\"\"et\"; ALTER USER u IDENTIFIED BY 'x''y'"}`))
+	s.Contains(wireLog.String(), `-> {"sqlText":"CREATE USER u IDENTIFIED BY <redacted>; ALTER USER u IDENTIFIED BY <redacted>"}`)
+}
+
+func (s *testSuite) TestStrictResponses() {
+	output := &bytes.Buffer{}
+	logger := customTestLogger("warning")
+	logger.SetOutput(output)
+	c := &Conn{log: logger}
+
+	s.NoError(c.validateExecData("execute", &execData{
+		NumResults: 1,
+		Results: []result{{
+			ResultType: resultSetType,
+			ResultSet: &resultSet{
+				NumColumns: 1, NumRows: 2,
+				Columns: []column{{Name: "A"}},
+				Data:    [][]interface{}{{1, 2}},
+			},
+		}},
+	}))
+	s.Empty(output.String())
+
+	partial := &execData{
+		NumResults: 1,
+		Results: []result{{
+			ResultType: resultSetType,
+			ResultSet: &resultSet{
+				NumColumns: 1, NumRows: 5,
+				Columns: []column{{Name: "A"}},
+				Data:    [][]interface{}{{1, 2}},
+			},
+		}},
+	}
+	s.NoError(c.validateExecData("execute", partial), "Lenient by default")
+	s.Contains(output.String(), "has 2 of 5 rows but no resultSetHandle")
+
+	c.Conf.StrictResponses = true
+	err := c.validateExecData("execute", partial)
+	var protoErr *ProtocolError
+	if s.ErrorAs(err, &protoErr) {
+		s.Equal("execute", protoErr.Command)
+		s.Contains(err.Error(), "Unexpected response to execute")
+	}
+
+	c.Conf.StrictResponses = false
+	s.Error(c.validateExecData("execute", nil), "Always an error")
+	s.Error(c.validateExecData("execute", &execData{
+		NumResults: 1,
+		Results:    []result{{ResultType: resultSetType}},
+	}), "Always an error")
+}
+
+func (s *testSuite) TestConnState() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	s.Nil(err)
+	s.True(c.IsAlive())
+	s.Equal(StateConnected, c.State())
+
+	// Killing our own session breaks the websocket
+	other, err := Connect(conf)
+	s.Nil(err)
+	_, err = other.Execute(fmt.Sprintf("KILL SESSION %d", c.SessionID))
+	s.NoError(err)
+	other.Disconnect()
+
+	_, err = c.Execute("SELECT 1")
+	s.Error(err)
+	s.False(c.IsAlive())
+	s.Equal(StateBroken, c.State())
+
+	// Subsequent calls fail fast
+	_, err = c.Execute("SELECT 1")
+	s.ErrorIs(err, ErrConnClosed)
+
+	c.Disconnect()
+	s.Equal(StateClosed, c.State())
+	s.Equal("closed", c.State().String())
+}
+
+func (s *testSuite) TestPasswordProviders() {
+	conf := s.connConf()
+	secret := conf.Password
+	conf.Password = ""
+	conf.PasswordFunc = func() (string, error) { return secret, nil }
+	s.NotContains(fmt.Sprintf("%v %+v %#v", conf, conf, conf), secret, "Not printed")
+	c, err := Connect(conf)
+	if s.NoError(err) {
+		c.Disconnect()
+	}
+
+	conf.PasswordFunc = func() (string, error) { return "", errors.New("vault down") }
+	conf.SuppressError = true
+	_, err = Connect(conf)
+	if s.Error(err) {
+		s.Contains(err.Error(), "vault down")
+	}
+
+	conf.PasswordFunc = nil
+	conf.PasswordBytes = []byte(secret)
+	s.NotContains(fmt.Sprintf("%v", conf), secret, "Not printed")
+	pass := conf.PasswordBytes
+	c, err = Connect(conf)
+	if s.NoError(err) {
+		c.Disconnect()
+	}
+	s.Equal(make([]byte, len(secret)), pass, "Zeroed")
+
+	conf.PasswordBytes = nil
+	conf.Password = secret
+	s.NotContains(fmt.Sprintf("%v", conf), secret, "Not printed")
+	s.Contains(fmt.Sprintf("%v", conf), "<redacted>")
+}
+
+func (s *testSuite) TestRetire() {
+	conf := s.connConf()
+	conf.SuppressError = true
+	c, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+
+	// Waits until the connection isn't in use
+	c.Lock()
+	c.Retire(100 * time.Millisecond)
+	time.Sleep(300 * time.Millisecond)
+	s.True(c.IsAlive(), "Still in use")
+	c.Unlock()
+	time.Sleep(300 * time.Millisecond)
+	s.False(c.IsAlive(), "Retired")
+
+	_, err = c.Execute("SELECT 1")
+	s.ErrorIs(err, ErrRetired)
+}
+
+// Blocks the disconnect request until released
+type disconnectWSHandler struct {
+	testWSHandler
+	sending, release chan bool
+}
+
+func (wsh *disconnectWSHandler) WriteJSON(req interface{}) error {
+	if r, ok := req.(*request); ok && r.Command == "disconnect" {
+		wsh.sending <- true
+		<-wsh.release
+	}
+	return nil
+}
+
+func (wsh *disconnectWSHandler) ReadJSON(resp interface{}) error {
+	reflect.Indirect(reflect.ValueOf(resp)).FieldByName("Status").SetString("ok")
+	return nil
+}
+
+// Run with: go test -race
+func (s *testSuite) TestTryDisconnect() {
+	wsh := &disconnectWSHandler{sending: make(chan bool), release: make(chan bool)}
+	c := &Conn{wsh: wsh, log: newDefaultLogger()}
+	c.ctx, c.cancel = context.WithCancelCause(context.Background())
+	c.state.Store(int32(StateConnected))
+	retired := func() error { return ErrRetired }
+
+	s.False(c.tryDisconnect(func() error { return nil }), "Not expired")
+	c.inFlight.Add(1)
+	s.False(c.tryDisconnect(retired), "In use")
+	c.inFlight.Add(-1)
+
+	done := make(chan bool)
+	go func() { done <- c.tryDisconnect(retired) }()
+	<-wsh.sending
+	_, err := c.asyncSend(&request{Command: "getAttributes"})
+	s.ErrorIs(err, ErrRetired, "Other requests fail whilst disconnecting")
+	s.True(c.tryDisconnect(retired), "Already disconnecting")
+	close(wsh.release)
+	s.True(<-done)
+	s.False(c.IsAlive())
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlags(t *testing.T) {
+	_, err := parseFlags([]string{})
+	assert.ErrorContains(t, err, "Pass a -workload or at least one -query")
+
+	t.Setenv("EXA_PASSWORD", "secret")
+	cfg, err := parseFlags([]string{"-c", "2", "-query", "SELECT 1", "-query", "DELETE FROM t"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, cfg.concurrency)
+		assert.Equal(t, "secret", cfg.conf.Password)
+		if assert.Len(t, cfg.workload.Ops, 2) {
+			assert.Equal(t, "query1", cfg.workload.Ops[0].Name)
+			assert.True(t, cfg.workload.Ops[0].query)
+			assert.False(t, cfg.workload.Ops[1].query)
+		}
+	}
+
+	_, err = parseFlags([]string{"-c", "0", "-query", "SELECT 1"})
+	assert.ErrorContains(t, err, "-c must be at least 1")
+}
+
+func TestLoadWorkload(t *testing.T) {
+	dir := t.TempDir()
+	csv := filepath.Join(dir, "rows.csv")
+	assert.NoError(t, os.WriteFile(csv, []byte("1,a\n"), 0644))
+	file := filepath.Join(dir, "workload.json")
+	assert.NoError(t, os.WriteFile(file, []byte(`{"ops": [
+		{"name": "lookup", "sql": " with x AS (SELECT 1) SELECT * FROM x", "weight": 3},
+		{"import": {"table": "s.t", "file": "`+csv+`"}}
+	]}`), 0644))
+	w, err := LoadWorkload(file)
+	if assert.NoError(t, err) && assert.Len(t, w.Ops, 2) {
+		assert.True(t, w.Ops[0].query)
+		assert.Equal(t, "op2", w.Ops[1].Name)
+		assert.Equal(t, 1, w.Ops[1].Weight)
+		assert.Equal(t, "t", w.Ops[1].table)
+		assert.Equal(t, []byte("1,a\n"), w.Ops[1].data)
+	}
+
+	for json, msg := range map[string]string{
+		`{"ops": []}`:                                                     "There are no ops",
+		`{"ops": [{"name": "a"}]}`:                                        "a has neither sql nor an import",
+		`{"ops": [{"sql": "x", "weight": -1}]}`:                           "op1's weight can't be negative",
+		`{"ops": [{"import": {"table": "t"}}]}`:                           "op1's import table must be schema.table",
+		`{"ops": [{"sql": "x"}], "extra": true}`:                          "unknown field",
+		`{"ops": [{"name": "a", "sql": "x"}, {"name": "a", "sql": "y"}]}`: "more than one op named a",
+	} {
+		assert.NoError(t, os.WriteFile(file, []byte(json), 0644))
+		_, err := LoadWorkload(file)
+		assert.ErrorContains(t, err, msg, json)
+	}
+}
+
+func TestPick(t *testing.T) {
+	w := &Workload{Ops: []*Op{{SQL: "a", Weight: 9}, {SQL: "b"}}}
+	assert.NoError(t, w.init())
+	rnd := rand.New(rand.NewSource(1))
+	counts := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		counts[w.pick(rnd).SQL]++
+	}
+	assert.InDelta(t, 9000, counts["a"], 300)
+	assert.InDelta(t, 1000, counts["b"], 300)
+}
+
+func TestReport(t *testing.T) {
+	ms := time.Millisecond
+	sorted := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		sorted = append(sorted, time.Duration(i)*ms)
+	}
+	assert.Equal(t, 50*ms, percentile(sorted, 50))
+	assert.Equal(t, 99*ms, percentile(sorted, 99))
+	assert.Equal(t, 1*ms, percentile(sorted[:1], 99))
+	assert.Zero(t, percentile(nil, 50))
+
+	a, b := &Op{Name: "a"}, &Op{Name: "b"}
+	rec := newRecorder([]*Op{a, b})
+	for _, d := range []time.Duration{3 * ms, 1 * ms, 2 * ms} {
+		rec.record(a, d, 10, 0, nil)
+	}
+	rec.record(b, 5*ms, 0, 0, errors.New("boom"))
+	rec.record(b, 4*ms, 0, 100, nil)
+
+	rep := rec.report(2 * time.Second)
+	assert.Equal(t, 1, rep.Errors())
+	assert.Equal(t, 3, rep.Ops[0].Count)
+	assert.Equal(t, int64(30), rep.Ops[0].Rows)
+	assert.Equal(t, 2*ms, rep.Ops[0].P50)
+	assert.Equal(t, 3*ms, rep.Ops[0].Max)
+	assert.Equal(t, 1.5, rep.Ops[0].PerSecond)
+	assert.Equal(t, "boom", rep.Ops[1].FirstErr)
+	assert.Equal(t, 4, rep.Total.Count)
+	assert.Equal(t, 4*ms, rep.Total.Max)
+
+	var out bytes.Buffer
+	assert.NoError(t, rep.Write(&out))
+	assert.Contains(t, out.String(), "b failed: boom")
+	assert.Contains(t, out.String(), "total")
+}
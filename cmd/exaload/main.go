@@ -0,0 +1,213 @@
+/*
+	exaload load tests an Exasol database by running a weighted mix of
+	queries and imports from a number of concurrent connections and then
+	reporting the throughput and latency percentiles of each.
+
+	Usage:
+
+	    exaload [flags] -query 'SELECT ...' [-query ...]
+	    exaload [flags] -workload workload.json
+
+	A workload file lists the operations along with their relative weights:
+
+	    {"ops": [
+	        {"name": "lookup", "sql": "SELECT * FROM s.t WHERE id = 1", "weight": 10},
+	        {"name": "report", "sql": "SELECT COUNT(*) FROM s.t", "weight": 1},
+	        {"name": "load", "import": {"table": "s.staging", "file": "rows.csv"}}
+	    ]}
+
+	SELECTs have their results fetched in full, other statements are
+	executed and imports bulk load the CSV file into the table. The
+	password is taken from the EXA_PASSWORD environment variable unless
+	-pass is given.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+type config struct {
+	conf        exasol.ConnConf
+	workload    *Workload
+	concurrency int
+	duration    time.Duration
+	total       int
+	seed        int64
+	asJSON      bool
+}
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, "; ") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	cfg, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if cfg.asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.Write(os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if report.Errors() > 0 {
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string) (*config, error) {
+	fs := flag.NewFlagSet("exaload", flag.ContinueOnError)
+	host := fs.String("host", "127.0.0.1", "Exasol host (or IP range)")
+	port := fs.Int("port", 8563, "Exasol port")
+	user := fs.String("user", "SYS", "Username")
+	pass := fs.String("pass", "", "Password (defaults to $EXA_PASSWORD)")
+	insecure := fs.Bool("insecure", false, "Skip TLS certificate verification")
+	workloadFile := fs.String("workload", "", "JSON workload file")
+	var queries stringsFlag
+	fs.Var(&queries, "query", "A query to run (may be repeated, each weighted equally)")
+	cfg := &config{}
+	fs.IntVar(&cfg.concurrency, "c", 4, "Number of concurrent connections")
+	fs.DurationVar(&cfg.duration, "d", 30*time.Second, "How long to run for")
+	fs.IntVar(&cfg.total, "n", 0, "Stop after this many operations in total (0 means run for -d)")
+	fs.Int64Var(&cfg.seed, "seed", 1, "Seed for choosing the operations")
+	fs.BoolVar(&cfg.asJSON, "json", false, "Report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch {
+	case *workloadFile != "" && len(queries) > 0:
+		return nil, fmt.Errorf("Pass either -workload or -query, not both")
+	case *workloadFile != "":
+		cfg.workload, err = LoadWorkload(*workloadFile)
+	case len(queries) > 0:
+		cfg.workload = &Workload{}
+		for i, sql := range queries {
+			cfg.workload.Ops = append(cfg.workload.Ops, &Op{Name: fmt.Sprintf("query%d", i+1), SQL: sql})
+		}
+		err = cfg.workload.init()
+	default:
+		return nil, fmt.Errorf("Pass a -workload or at least one -query")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cfg.concurrency < 1 {
+		return nil, fmt.Errorf("-c must be at least 1")
+	}
+
+	if *pass == "" {
+		*pass = os.Getenv("EXA_PASSWORD")
+	}
+	cfg.conf = exasol.ConnConf{
+		Host:          *host,
+		Port:          uint16(*port),
+		Username:      *user,
+		Password:      *pass,
+		ClientName:    "exaload",
+		SuppressError: true,
+	}
+	if *insecure {
+		cfg.conf.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return cfg, nil
+}
+
+// Runs the workload from each connection until the duration is up,
+// the total number of operations have been run or ctx is cancelled
+func run(ctx context.Context, cfg *config) (*Report, error) {
+	conns := make([]*exasol.Conn, cfg.concurrency)
+	defer func() {
+		for _, conn := range conns {
+			if conn != nil {
+				conn.Disconnect()
+			}
+		}
+	}()
+	for i := range conns {
+		var err error
+		conns[i], err = exasol.ConnectContext(ctx, cfg.conf)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to connect: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+	var remaining chan struct{}
+	if cfg.total > 0 {
+		remaining = make(chan struct{}, cfg.total)
+		for i := 0; i < cfg.total; i++ {
+			remaining <- struct{}{}
+		}
+		close(remaining)
+	}
+
+	rec := newRecorder(cfg.workload.Ops)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i, conn := range conns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(cfg.seed + int64(i)))
+			for ctx.Err() == nil {
+				if remaining != nil {
+					if _, ok := <-remaining; !ok {
+						return
+					}
+				}
+				op := cfg.workload.pick(rnd)
+				opStart := time.Now()
+				rows, bytes, err := op.run(conn)
+				if ctx.Err() != nil && err != nil {
+					return // Interrupted rather than failed
+				}
+				rec.record(op, time.Since(opStart), rows, bytes, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return rec.report(time.Since(start)), nil
+}
@@ -0,0 +1,178 @@
+/*
+	Recording each op's latencies and summarizing them
+	into throughput and percentiles once the run is over
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+type Report struct {
+	Elapsed time.Duration `json:"elapsed"`
+	Ops     []*OpStats    `json:"ops"`
+	Total   *OpStats      `json:"total"`
+}
+
+type OpStats struct {
+	Name      string        `json:"name"`
+	Count     int           `json:"count"`
+	Errors    int           `json:"errors"`
+	FirstErr  string        `json:"first_error,omitempty"`
+	PerSecond float64       `json:"per_second"`
+	Rows      int64         `json:"rows"`
+	Bytes     int64         `json:"bytes"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+func (r *Report) Errors() int {
+	return r.Total.Errors
+}
+
+// Write prints the report as a table followed by the first error of each op
+func (r *Report) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\tcount\terrors\tops/s\trows\tbytes\tp50\tp90\tp99\tmax\t")
+	for _, s := range append(slices.Clone(r.Ops), r.Total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			s.Name, s.Count, s.Errors, s.PerSecond, s.Rows, s.Bytes,
+			round(s.P50), round(s.P90), round(s.P99), round(s.Max),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nelapsed %s\n", round(r.Elapsed))
+	for _, s := range r.Ops {
+		if s.FirstErr != "" && err == nil {
+			_, err = fmt.Fprintf(w, "%s failed: %s\n", s.Name, s.FirstErr)
+		}
+	}
+	return err
+}
+
+/*--- Private Routines ---*/
+
+type recorder struct {
+	mux   sync.Mutex
+	ops   []*Op
+	stats map[*Op]*opRecord
+}
+
+type opRecord struct {
+	latencies []time.Duration // Of the successful runs
+	errors    int
+	firstErr  error
+	rows      int64
+	bytes     int64
+}
+
+func newRecorder(ops []*Op) *recorder {
+	r := &recorder{ops: ops, stats: map[*Op]*opRecord{}}
+	for _, op := range ops {
+		r.stats[op] = &opRecord{}
+	}
+	return r
+}
+
+func (r *recorder) record(op *Op, latency time.Duration, rows, bytes int64, err error) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	rec := r.stats[op]
+	if err != nil {
+		rec.errors++
+		if rec.firstErr == nil {
+			rec.firstErr = err
+		}
+		return
+	}
+	rec.latencies = append(rec.latencies, latency)
+	rec.rows += rows
+	rec.bytes += bytes
+}
+
+func (r *recorder) report(elapsed time.Duration) *Report {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	rep := &Report{Elapsed: elapsed}
+	total := &opRecord{}
+	for _, op := range r.ops {
+		rec := r.stats[op]
+		rep.Ops = append(rep.Ops, rec.summarize(op.Name, elapsed))
+		total.latencies = append(total.latencies, rec.latencies...)
+		total.errors += rec.errors
+		total.rows += rec.rows
+		total.bytes += rec.bytes
+	}
+	rep.Total = total.summarize("total", elapsed)
+	return rep
+}
+
+func (rec *opRecord) summarize(name string, elapsed time.Duration) *OpStats {
+	lat := slices.Clone(rec.latencies)
+	slices.Sort(lat)
+	s := &OpStats{
+		Name:   name,
+		Count:  len(lat),
+		Errors: rec.errors,
+		Rows:   rec.rows,
+		Bytes:  rec.bytes,
+		P50:    percentile(lat, 50),
+		P90:    percentile(lat, 90),
+		P99:    percentile(lat, 99),
+	}
+	if len(lat) > 0 {
+		s.Max = lat[len(lat)-1]
+	}
+	if rec.firstErr != nil {
+		s.FirstErr = rec.firstErr.Error()
+	}
+	if elapsed > 0 {
+		s.PerSecond = float64(s.Count) / elapsed.Seconds()
+	}
+	return s
+}
+
+// Returns the nearest-rank percentile of the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	default:
+		return d.Round(time.Microsecond)
+	}
+}
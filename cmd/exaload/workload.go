@@ -0,0 +1,156 @@
+/*
+	The workload being run: the operations along with how often
+	each is run relative to the others
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+/*--- Public Interface ---*/
+
+type Workload struct {
+	Ops []*Op `json:"ops"`
+
+	totalWeight int
+}
+
+// Op is either SQL or an Import
+type Op struct {
+	Name   string  `json:"name"`
+	SQL    string  `json:"sql,omitempty"`
+	Import *Import `json:"import,omitempty"`
+	Weight int     `json:"weight,omitempty"` // Defaults to 1
+
+	query  bool   // Whether the SQL returns a result set to fetch
+	schema string // The Import's schema and table
+	table  string
+	data   []byte // The Import's file contents
+}
+
+type Import struct {
+	Table string `json:"table"` // schema.table
+	File  string `json:"file"`  // A CSV file
+}
+
+func LoadWorkload(file string) (*Workload, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read the workload: %w", err)
+	}
+	w := &Workload{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(w); err != nil {
+		return nil, fmt.Errorf("Unable to parse the workload %s: %w", file, err)
+	}
+	if err := w.init(); err != nil {
+		return nil, fmt.Errorf("Invalid workload %s: %w", file, err)
+	}
+	return w, nil
+}
+
+/*--- Private Routines ---*/
+
+// Validates the ops filling in the defaults and loading the import files
+func (w *Workload) init() error {
+	if len(w.Ops) == 0 {
+		return fmt.Errorf("There are no ops")
+	}
+	names := map[string]bool{}
+	w.totalWeight = 0
+	for i, op := range w.Ops {
+		if op.Name == "" {
+			op.Name = fmt.Sprintf("op%d", i+1)
+		}
+		if names[op.Name] {
+			return fmt.Errorf("There's more than one op named %s", op.Name)
+		}
+		names[op.Name] = true
+
+		switch {
+		case op.Weight < 0:
+			return fmt.Errorf("%s's weight can't be negative", op.Name)
+		case op.Weight == 0:
+			op.Weight = 1
+		}
+		w.totalWeight += op.Weight
+
+		switch {
+		case op.SQL != "" && op.Import != nil:
+			return fmt.Errorf("%s has both sql and an import", op.Name)
+		case op.SQL != "":
+			op.query = exasol.ClassifyStatement(op.SQL) == exasol.StmtSelect
+		case op.Import != nil:
+			parts := strings.SplitN(op.Import.Table, ".", 2)
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("%s's import table must be schema.table", op.Name)
+			}
+			op.schema, op.table = parts[0], parts[1]
+			var err error
+			op.data, err = os.ReadFile(op.Import.File)
+			if err != nil {
+				return fmt.Errorf("Unable to read %s's import file: %w", op.Name, err)
+			}
+		default:
+			return fmt.Errorf("%s has neither sql nor an import", op.Name)
+		}
+	}
+	return nil
+}
+
+// Chooses an op at random in proportion to their weights
+func (w *Workload) pick(rnd *rand.Rand) *Op {
+	n := rnd.Intn(w.totalWeight)
+	for _, op := range w.Ops {
+		if n < op.Weight {
+			return op
+		}
+		n -= op.Weight
+	}
+	return w.Ops[len(w.Ops)-1]
+}
+
+// Runs the op returning the rows fetched or affected
+// along with the bytes imported (if it's an import)
+func (op *Op) run(conn *exasol.Conn) (rows, bytesSent int64, err error) {
+	switch {
+	case op.Import != nil:
+		res, err := conn.BulkInsertResult(op.schema, op.table, bytes.NewBuffer(op.data))
+		if err != nil {
+			return 0, 0, err
+		}
+		return 0, res.BytesWritten, nil
+	case op.query:
+		ch, err := conn.FetchChan(op.SQL)
+		if err != nil {
+			return 0, 0, err
+		}
+		for range ch {
+			rows++
+		}
+		return rows, 0, nil
+	default:
+		rows, err = conn.Execute(op.SQL)
+		return rows, 0, err
+	}
+}
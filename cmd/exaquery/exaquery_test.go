@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlags(t *testing.T) {
+	t.Setenv("EXA_DSN", "")
+	_, err := parseFlags([]string{"SELECT 1"}, nil)
+	assert.EqualError(t, err, "Pass a -dsn or set EXA_DSN")
+
+	t.Setenv("EXA_PASSWORD", "secret")
+	cfg, err := parseFlags([]string{"-dsn", "exa:db;user=sys", "SELECT 1; SELECT 2", "DELETE FROM t"}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "db", cfg.conf.Host)
+		assert.Equal(t, "secret", cfg.conf.Password)
+		assert.Equal(t, "exaquery", cfg.conf.ClientName)
+		assert.Equal(t, []string{"SELECT 1", "SELECT 2", "DELETE FROM t"}, cfg.statements)
+	}
+
+	file := filepath.Join(t.TempDir(), "script.sql")
+	assert.NoError(t, os.WriteFile(file, []byte("SELECT 3;\n"), 0644))
+	cfg, err = parseFlags([]string{"-dsn", "exa:db;password=pw", "-format", "csv", "-f", file}, nil)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "pw", cfg.conf.Password)
+		assert.Equal(t, []string{"SELECT 3"}, cfg.statements)
+	}
+
+	cfg, err = parseFlags([]string{"-dsn", "exa:db"}, strings.NewReader("SELECT 4"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"SELECT 4"}, cfg.statements)
+	}
+	_, err = parseFlags([]string{"-dsn", "exa:db"}, strings.NewReader("-- nothing"))
+	assert.EqualError(t, err, "There's no SQL to run")
+	_, err = parseFlags([]string{"-dsn", "exa:db", "-format", "xml", "SELECT 1"}, nil)
+	assert.EqualError(t, err, `Unknown -format "xml"`)
+	_, err = parseFlags([]string{"-dsn", "db", "SELECT 1"}, nil)
+	assert.ErrorContains(t, err, "Invalid DSN")
+}
+
+func TestFormatValue(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 600e6, time.UTC)
+	for val, want := range map[interface{}]string{
+		nil:                 "NULL",
+		1234567.5:           "1234567.5",
+		int64(42):           "42",
+		json.Number("1.10"): "1.10",
+		true:                "TRUE",
+		"a\tb\nc":           `a\tb\nc`,
+		ts:                  "2020-01-02 03:04:05.6",
+	} {
+		assert.Equal(t, want, formatValue(val))
+	}
+}
@@ -0,0 +1,161 @@
+/*
+	exaquery runs SQL against Exasol printing the results as a table,
+	CSV or JSON.
+
+	Usage:
+
+	    exaquery [flags] -dsn 'exa:host:8563;user=sys' 'SELECT ...' ...
+	    exaquery [flags] -dsn ... -f script.sql
+	    echo 'SELECT ...' | exaquery [flags] -dsn ...
+	    exaquery [flags] -dsn ... -i
+
+	The SQL comes from the arguments (one statement each), the -f files
+	or, failing those, stdin. If stdin is a terminal (or -i is given)
+	exaquery runs interactively instead: statements are run as they're
+	entered (once terminated by a semicolon) and \? lists the commands
+	e.g. \timing and \d for describing tables. Lines are read using the
+	terminal's own line editing. Files and stdin may hold several statements
+	separated by semicolons. Statements returning a result set have it
+	streamed to stdout, others print the number of rows affected to
+	stderr. The DSN defaults to $EXA_DSN and the password to $EXA_PASSWORD
+	if the DSN doesn't have one. See exasol.ParseDSN for the DSN format.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+type config struct {
+	conf        exasol.ConnConf
+	format      string
+	header      bool
+	statements  []string
+	stopOnErr   bool
+	interactive bool
+}
+
+type stringsFlag []string
+
+func (s *stringsFlag) String() string     { return strings.Join(*s, ", ") }
+func (s *stringsFlag) Set(v string) error { *s = append(*s, v); return nil }
+
+func main() {
+	cfg, err := parseFlags(os.Args[1:], os.Stdin)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	conn, err := exasol.Connect(cfg.conf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Unable to connect:", err)
+		os.Exit(1)
+	}
+	defer conn.Disconnect()
+
+	if cfg.interactive {
+		if err := newREPL(conn, os.Stdout, os.Stderr).loop(os.Stdin); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return
+	}
+
+	failed := false
+	for _, sql := range cfg.statements {
+		if err := runStatement(conn, sql, cfg, os.Stdout, os.Stderr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			if cfg.stopOnErr {
+				break
+			}
+		}
+	}
+	if failed {
+		conn.Disconnect()
+		os.Exit(1)
+	}
+}
+
+func parseFlags(args []string, stdin io.Reader) (*config, error) {
+	fs := flag.NewFlagSet("exaquery", flag.ContinueOnError)
+	dsn := fs.String("dsn", os.Getenv("EXA_DSN"), "Connection string (defaults to $EXA_DSN)")
+	var files stringsFlag
+	fs.Var(&files, "f", "A file of SQL statements to run (may be repeated)")
+	cfg := &config{}
+	fs.StringVar(&cfg.format, "format", "table", "Output format: table, csv or json")
+	fs.BoolVar(&cfg.header, "header", true, "Start CSV output with the column names")
+	fs.BoolVar(&cfg.stopOnErr, "stop-on-error", true, "Stop at the first statement that fails")
+	fs.BoolVar(&cfg.interactive, "i", false, "Run interactively (the default when stdin is a terminal)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	switch cfg.format {
+	case "table", "csv", "json":
+	default:
+		return nil, fmt.Errorf("Unknown -format %q", cfg.format)
+	}
+	if *dsn == "" {
+		return nil, fmt.Errorf("Pass a -dsn or set EXA_DSN")
+	}
+	var err error
+	cfg.conf, err = exasol.ParseDSN(*dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.conf.Password == "" {
+		cfg.conf.Password = os.Getenv("EXA_PASSWORD")
+	}
+	if cfg.conf.ClientName == "" {
+		cfg.conf.ClientName = "exaquery"
+	}
+	cfg.conf.SuppressError = true
+
+	for _, sql := range fs.Args() {
+		cfg.statements = append(cfg.statements, exasol.SplitStatements(sql)...)
+	}
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read SQL: %w", err)
+		}
+		cfg.statements = append(cfg.statements, exasol.SplitStatements(string(sql))...)
+	}
+	if fs.NArg() == 0 && len(files) == 0 {
+		if f, ok := stdin.(*os.File); ok && isTerminal(f) {
+			cfg.interactive = true
+		}
+		if cfg.interactive {
+			return cfg, nil
+		}
+		sql, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read SQL from stdin: %w", err)
+		}
+		cfg.statements = exasol.SplitStatements(string(sql))
+	}
+	if cfg.interactive && len(cfg.statements) > 0 {
+		return nil, fmt.Errorf("-i can't be combined with SQL arguments or -f")
+	}
+	if len(cfg.statements) == 0 {
+		return nil, fmt.Errorf("There's no SQL to run")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,128 @@
+/*
+	Running each statement and printing its results in the chosen format
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+// Runs the statement writing any results to out and
+// the number of rows fetched or affected to status
+func runStatement(conn *exasol.Conn, sql string, cfg *config, out, status io.Writer) error {
+	if exasol.ClassifyStatement(sql) != exasol.StmtSelect {
+		affected, err := conn.Execute(sql)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(status, "%d rows affected\n", affected)
+		return nil
+	}
+
+	var rows uint64
+	var err error
+	switch cfg.format {
+	case "csv":
+		rows, err = conn.FetchToCSV(sql, out, exasol.CSVOpts{Header: cfg.header})
+	case "json":
+		rows, err = conn.FetchToJSON(sql, out)
+	default:
+		rows, err = writeTable(conn, sql, out)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(status, "%d rows\n", rows)
+	return nil
+}
+
+// Writes the results as a table. The whole result set is
+// buffered so that the columns can be aligned.
+func writeTable(conn *exasol.Conn, sql string, out io.Writer) (uint64, error) {
+	res, err := conn.FetchRows(sql)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Close()
+
+	t := newTable(out, res.Columns())
+	vals := make([]interface{}, len(t.strs))
+	dests := make([]interface{}, len(vals))
+	for i := range vals {
+		dests[i] = &vals[i]
+	}
+	for res.Next() {
+		if err := res.Scan(dests...); err != nil {
+			return t.rows, err
+		}
+		t.row(vals)
+	}
+	return t.rows, t.flush()
+}
+
+type table struct {
+	tw   *tabwriter.Writer
+	strs []string
+	rows uint64
+}
+
+// Starts a table with a header of the column names
+func newTable(out io.Writer, names []string) *table {
+	t := &table{
+		tw:   tabwriter.NewWriter(out, 0, 0, 2, ' ', 0),
+		strs: make([]string, len(names)),
+	}
+	line := make([]string, len(names))
+	for i, name := range names {
+		line[i] = strings.Repeat("-", len(name))
+	}
+	fmt.Fprintln(t.tw, strings.Join(names, "\t")+"\t")
+	fmt.Fprintln(t.tw, strings.Join(line, "\t")+"\t")
+	return t
+}
+
+func (t *table) row(vals []interface{}) {
+	for i, val := range vals {
+		t.strs[i] = formatValue(val)
+	}
+	fmt.Fprintln(t.tw, strings.Join(t.strs, "\t")+"\t")
+	t.rows++
+}
+
+func (t *table) flush() error { return t.tw.Flush() }
+
+// Formats the decoded value (per the ConnConf's TypedInts/UseNumber)
+func formatValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v))
+	case time.Time:
+		return v.Format("2006-01-02 15:04:05.999")
+	case string:
+		return strings.NewReplacer("\t", `\t`, "\n", `\n`, "\r", `\r`).Replace(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
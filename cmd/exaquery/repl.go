@@ -0,0 +1,232 @@
+/*
+	The interactive mode: statements are read a line at a time and run
+	once they're terminated by a semicolon. The prompt shows the open
+	schema and (with a *) whether there's an open transaction.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+)
+
+const replHelp = `Statements are run once they end with a semicolon. Commands:
+  \q                 Quit
+  \timing [on|off]   Toggle (or set) showing how long statements take
+  \info              Show the server's release, nodes and size
+  \dn                List the schemas
+  \dt [schema]       List the tables in the schema (default the open one)
+  \d table           Describe the table's columns
+  \?                 Show this help
+`
+
+type repl struct {
+	conn   *exasol.Conn
+	out    io.Writer // Results
+	status io.Writer // Prompts, row counts, timings and errors
+	timing bool
+
+	// Overridable for testing
+	runScript func(string) ([]*exasol.ScriptResult, error)
+	attrs     func() (*exasol.Attributes, error)
+}
+
+func newREPL(conn *exasol.Conn, out, status io.Writer) *repl {
+	return &repl{
+		conn:      conn,
+		out:       out,
+		status:    status,
+		runScript: conn.ExecuteScript,
+		attrs:     conn.GetSessionAttr,
+	}
+}
+
+// Reads and runs statements until \q or the end of the input
+// (at which point anything unterminated is run too)
+func (r *repl) loop(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var buf strings.Builder
+	fmt.Fprint(r.status, r.prompt(false))
+	for sc.Scan() {
+		line := sc.Text()
+		if buf.Len() == 0 && strings.HasPrefix(strings.TrimSpace(line), `\`) {
+			if quit := r.command(strings.Fields(line)); quit {
+				return nil
+			}
+		} else if buf.Len() > 0 || strings.TrimSpace(line) != "" {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+			if exasol.StatementComplete(buf.String()) {
+				r.run(buf.String())
+				buf.Reset()
+			}
+		}
+		fmt.Fprint(r.status, r.prompt(buf.Len() > 0))
+	}
+	if buf.Len() > 0 {
+		r.run(buf.String())
+	}
+	fmt.Fprintln(r.status)
+	return sc.Err()
+}
+
+/*--- Private Routines ---*/
+
+// Returns e.g. "MY_SCHEMA*> " or, when continuing a statement, "       -> "
+func (r *repl) prompt(continuing bool) string {
+	name := "exa"
+	open := ""
+	if attrs, err := r.attrs(); err == nil && attrs != nil {
+		if attrs.CurrentSchema != "" {
+			name = attrs.CurrentSchema
+		}
+		if attrs.OpenTransaction != 0 {
+			open = "*"
+		}
+	}
+	prompt := name + open + "> "
+	if continuing {
+		return strings.Repeat(" ", len(prompt)-3) + "-> "
+	}
+	return prompt
+}
+
+// Runs the statements printing their results as tables
+func (r *repl) run(script string) {
+	results, err := r.runScript(script)
+	for _, res := range results {
+		if res.Columns != nil {
+			names := make([]string, len(res.Columns))
+			for i, col := range res.Columns {
+				names[i] = col.Name
+			}
+			t := newTable(r.out, names)
+			for _, row := range res.Rows {
+				t.row(row)
+			}
+			t.flush()
+			fmt.Fprintf(r.status, "%d rows", len(res.Rows))
+		} else {
+			fmt.Fprintf(r.status, "%d rows affected", res.RowsAffected)
+		}
+		if r.timing {
+			fmt.Fprintf(r.status, " (%s)", res.Duration.Round(time.Millisecond))
+		}
+		fmt.Fprintln(r.status)
+	}
+	if err != nil {
+		fmt.Fprintln(r.status, err)
+	}
+}
+
+// Runs a backslash command returning whether to quit
+func (r *repl) command(args []string) (quit bool) {
+	arg := ""
+	if len(args) > 1 {
+		arg = args[1]
+	}
+	switch args[0] {
+	case `\q`, `\quit`:
+		return true
+	case `\timing`:
+		switch strings.ToLower(arg) {
+		case "on":
+			r.timing = true
+		case "off":
+			r.timing = false
+		case "":
+			r.timing = !r.timing
+		default:
+			fmt.Fprintln(r.status, `\timing takes on or off`)
+			return false
+		}
+		fmt.Fprintf(r.status, "Timing is %s\n", map[bool]string{true: "on", false: "off"}[r.timing])
+	case `\info`:
+		r.info()
+	case `\dn`:
+		r.run("SELECT schema_name, schema_owner, schema_comment FROM exa_all_schemas ORDER BY 1;")
+	case `\dt`:
+		if arg == "" {
+			if attrs, err := r.attrs(); err == nil && attrs != nil {
+				arg = attrs.CurrentSchema
+			}
+		}
+		if arg == "" {
+			fmt.Fprintln(r.status, `No schema is open so pass one to \dt`)
+			return false
+		}
+		r.run(fmt.Sprintf(`
+			SELECT table_name, table_row_count, table_comment
+			FROM exa_all_tables WHERE table_schema = '%s' ORDER BY 1;
+		`, exasol.QuoteStr(strings.ToUpper(arg))))
+	case `\d`:
+		if arg == "" {
+			fmt.Fprintln(r.status, `\d takes a table`)
+			return false
+		}
+		r.describe(arg)
+	case `\?`, `\h`, `\help`:
+		fmt.Fprint(r.status, replHelp)
+	default:
+		fmt.Fprintf(r.status, "Unknown command %s (try \\?)\n", args[0])
+	}
+	return false
+}
+
+func (r *repl) info() {
+	info, err := r.conn.RefreshMetadata()
+	if err != nil {
+		fmt.Fprintln(r.status, err)
+		return
+	}
+	fmt.Fprintf(r.out, "Host:     %s\n", r.conn.Host())
+	fmt.Fprintf(r.out, "Release:  %s\n", info.ReleaseVersion)
+	fmt.Fprintf(r.out, "Nodes:    %d\n", info.NumNodes)
+	fmt.Fprintf(r.out, "Raw size: %.2f GiB\n", info.RawSizeGiB)
+	fmt.Fprintf(r.out, "Mem size: %.2f GiB\n", info.MemSizeGiB)
+	if c := r.conn.Compression(); c.Protocol || c.Websocket {
+		fmt.Fprintf(r.out, "Compression: protocol %t, websocket %t\n", c.Protocol, c.Websocket)
+	}
+}
+
+// Describes the [schema.]table's columns
+func (r *repl) describe(table string) {
+	schema, name, ok := strings.Cut(table, ".")
+	if !ok {
+		name = schema
+		schema = ""
+		if attrs, err := r.attrs(); err == nil && attrs != nil {
+			schema = attrs.CurrentSchema
+		}
+	}
+	r.run(fmt.Sprintf(`
+		SELECT column_name, column_type, column_is_nullable, column_default
+		FROM exa_all_columns
+		WHERE column_schema = '%s' AND column_table = '%s'
+		ORDER BY column_ordinal_position;
+	`, exasol.QuoteStr(strings.ToUpper(schema)), exasol.QuoteStr(strings.ToUpper(name))))
+}
+
+// Whether stdin is a terminal (rather than a pipe or file)
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
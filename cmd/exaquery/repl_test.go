@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestREPL(t *testing.T) {
+	var out, status bytes.Buffer
+	var scripts []string
+	attrs := &exasol.Attributes{}
+	r := &repl{
+		out:    &out,
+		status: &status,
+		attrs:  func() (*exasol.Attributes, error) { return attrs, nil },
+		runScript: func(script string) ([]*exasol.ScriptResult, error) {
+			scripts = append(scripts, script)
+			if strings.Contains(script, "nope") {
+				return nil, errors.New("no such table")
+			}
+			attrs = &exasol.Attributes{CurrentSchema: "TEST", OpenTransaction: 1}
+			return []*exasol.ScriptResult{
+				{RowsAffected: 2, Duration: 1500 * time.Microsecond},
+				{
+					Columns: []exasol.Column{{Name: "ID"}, {Name: "NAME"}},
+					Rows:    [][]interface{}{{int64(1), "a"}, {int64(2), nil}},
+				},
+			}, nil
+		},
+	}
+
+	assert.NoError(t, r.loop(strings.NewReader(strings.Join([]string{
+		`\timing`,
+		`INSERT INTO t VALUES (1, 'a;'),`,
+		`  (2, NULL); SELECT * FROM t;`,
+		`SELECT * FROM nope;`,
+		`\bogus`,
+		`\q`,
+		`SELECT 'never run';`,
+	}, "\n"))))
+
+	assert.Equal(t, []string{
+		"INSERT INTO t VALUES (1, 'a;'),\n  (2, NULL); SELECT * FROM t;\n",
+		"SELECT * FROM nope;\n",
+	}, scripts)
+	assert.Equal(t, "ID  NAME  \n--  ----  \n1   a     \n2   NULL  \n", out.String())
+	assert.Equal(t, strings.Join([]string{
+		"exa> Timing is on",
+		"exa>   -> 2 rows affected (2ms)",
+		"2 rows (0s)",
+		"TEST*> no such table",
+		"TEST*> Unknown command \\bogus (try \\?)",
+		"TEST*> ",
+	}, "\n"), status.String())
+
+	// Anything unterminated is run at the end of the input
+	scripts = nil
+	status.Reset()
+	assert.NoError(t, r.loop(strings.NewReader("\\timing off\nDELETE FROM t")))
+	assert.Equal(t, []string{"DELETE FROM t\n"}, scripts)
+	assert.Contains(t, status.String(), "Timing is off")
+}
@@ -0,0 +1,120 @@
+/*
+	Profiling a column's values (counts, range and most common values)
+	e.g. for data-quality checks
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*--- Public Interface ---*/
+
+// ColumnProfile summarizes a column's values. Min, Max and the TopValues
+// are typed as per TableSample. Min and Max aren't calculated for BOOLEAN
+// or GEOMETRY columns (nor are Distinct and TopValues for GEOMETRY ones).
+type ColumnProfile struct {
+	DataType  DataType
+	Rows      int64
+	Nulls     int64
+	Distinct  int64 // Excluding NULL
+	Min, Max  interface{}
+	TopValues []ValueCount // The most common non-NULL values (ties by value)
+}
+
+type ValueCount struct {
+	Value interface{}
+	Count int64
+}
+
+// The number of TopValues ProfileColumn returns
+const ProfileTopValues = 10
+
+// ProfileColumn profiles the column's values with a single query.
+// The identifiers are quoted as for Preview.
+func (c *Conn) ProfileColumn(schema, table, column string) (*ColumnProfile, error) {
+	if schema == "" || table == "" || column == "" {
+		return nil, fmt.Errorf("You must pass in a schema, table and column to ProfileColumn")
+	}
+	from := c.exactTableName(schema, table)
+	col := quoteExact(c.storedName(column))
+	types, err := c.DescribeQuery(fmt.Sprintf("SELECT %s FROM %s", col, from))
+	if err != nil {
+		return nil, c.errorf("Unable to profile %s: %w", column, err)
+	}
+	profile := &ColumnProfile{DataType: types[0].DataType}
+
+	res, err := c.fetchSample(profileColumnSQL(from, col, profile.DataType.Type))
+	if err != nil {
+		return nil, c.errorf("Unable to profile %s: %w", column, err)
+	}
+	for _, row := range res.Rows {
+		count := toInt64(row[1])
+		if toInt64(row[0]) == 0 {
+			profile.Rows = count
+			profile.Nulls = count - toInt64(row[2])
+			profile.Distinct = toInt64(row[3])
+			profile.Min, profile.Max = row[4], row[5]
+		} else {
+			profile.TopValues = append(profile.TopValues, ValueCount{Value: row[4], Count: count})
+		}
+	}
+	return profile, nil
+}
+
+/*--- Private Routines ---*/
+
+// Returns a query whose first row has the kind (0), row count, non-NULL
+// count, distinct count, min and max and whose subsequent rows have the
+// kind (1), count and value of the most common values
+func profileColumnSQL(from, col, colType string) string {
+	distinct := fmt.Sprintf("COUNT(DISTINCT %s)", col)
+	minMax := fmt.Sprintf("MIN(%[1]s) AS v, MAX(%[1]s) AS mx", col)
+	top := fmt.Sprintf(`
+		UNION ALL
+		SELECT * FROM (
+			SELECT 1 AS kind, COUNT(*) AS n, NULL AS nn, NULL AS d, %[1]s AS v, NULL AS mx
+			FROM %[2]s WHERE %[1]s IS NOT NULL
+			GROUP BY %[1]s ORDER BY n DESC, v LIMIT %[3]d
+		)`, col, from, ProfileTopValues)
+	switch colType {
+	case "GEOMETRY":
+		distinct = "NULL"
+		top = ""
+		fallthrough
+	case "BOOLEAN":
+		minMax = fmt.Sprintf("CAST(NULL AS %[1]s) AS v, CAST(NULL AS %[1]s) AS mx", colType)
+	}
+	return fmt.Sprintf(`
+		SELECT * FROM (
+			SELECT 0 AS kind, COUNT(*) AS n, COUNT(%[1]s) AS nn, %[2]s AS d, %[3]s
+			FROM %[4]s
+			%[5]s
+		) ORDER BY kind, n DESC, v
+	`, col, distinct, minMax, from, top)
+}
+
+func toInt64(val interface{}) int64 {
+	switch v := val.(type) {
+	case int64:
+		return v
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
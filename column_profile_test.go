@@ -0,0 +1,53 @@
+package exasol
+
+import "encoding/json"
+
+func (s *testSuite) TestProfileColumnSQL() {
+	sql := profileColumnSQL(`"S"."T"`, `"C"`, "VARCHAR")
+	s.Contains(sql, `COUNT(DISTINCT "C") AS d, MIN("C") AS v, MAX("C") AS mx`)
+	s.Contains(sql, `GROUP BY "C" ORDER BY n DESC, v LIMIT 10`)
+
+	sql = profileColumnSQL(`"S"."T"`, `"C"`, "BOOLEAN")
+	s.Contains(sql, "CAST(NULL AS BOOLEAN) AS v")
+	s.Contains(sql, "UNION ALL")
+
+	sql = profileColumnSQL(`"S"."T"`, `"C"`, "GEOMETRY")
+	s.Contains(sql, "NULL AS d, CAST(NULL AS GEOMETRY) AS v")
+	s.NotContains(sql, "UNION ALL")
+
+	s.Equal(int64(3), toInt64(json.Number("3")))
+	s.Equal(int64(3), toInt64(int64(3)))
+	s.Zero(toInt64(nil))
+}
+
+func (s *testSuite) TestProfileColumn() {
+	s.execute("CREATE TABLE foo (name VARCHAR(10), amt DECIMAL(10,2), ok BOOLEAN)")
+	s.execute(`INSERT INTO foo VALUES
+		('b', 1.5, TRUE), ('a', 2, TRUE), ('b', NULL, FALSE),
+		('c', 1.5, NULL), (NULL, 3, TRUE), ('b', 1.5, TRUE)`)
+
+	p, err := s.exaConn.ProfileColumn(s.schema, "foo", "name")
+	s.NoError(err)
+	s.Equal("VARCHAR", p.DataType.Type)
+	s.Equal(int64(6), p.Rows)
+	s.Equal(int64(1), p.Nulls)
+	s.Equal(int64(3), p.Distinct)
+	s.Equal("a", p.Min)
+	s.Equal("c", p.Max)
+	s.Equal([]ValueCount{{"b", 3}, {"a", 1}, {"c", 1}}, p.TopValues)
+
+	p, err = s.exaConn.ProfileColumn(s.schema, "foo", "amt")
+	s.NoError(err)
+	s.Equal(json.Number("1.50"), p.Min)
+	s.Equal(json.Number("3.00"), p.Max)
+	s.Equal(ValueCount{json.Number("1.50"), 3}, p.TopValues[0])
+
+	p, err = s.exaConn.ProfileColumn(s.schema, "foo", "ok")
+	s.NoError(err)
+	s.Nil(p.Min)
+	s.Equal(int64(2), p.Distinct)
+	s.Equal(ValueCount{true, 4}, p.TopValues[0])
+
+	_, err = s.exaConn.ProfileColumn(s.schema, "foo", "nope")
+	s.ErrorContains(err, "Unable to profile nope")
+}
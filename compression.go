@@ -0,0 +1,138 @@
+/*
+	Compressing the websocket messages independently of the compression
+	built into Exasol's protocol e.g. for proxies in front of the database
+	that terminate compression themselves
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"compress/zlib"
+	"encoding/json"
+	"io"
+)
+
+/*--- Public Interface ---*/
+
+// WSCompressor compresses each websocket message (see ConnConf.Compressor)
+type WSCompressor interface {
+	Name() string // For diagnostics (see Conn.Compression)
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// FrameWSHandler is implemented by WSHandlers that can send and receive
+// raw messages which ConnConf.Compressor requires. The default one does.
+type FrameWSHandler interface {
+	WSHandler
+	// The message is sent upon closing the writer
+	NextWriter() (io.WriteCloser, error)
+	NextReader() (io.Reader, error)
+}
+
+// CompressionState is the compression in use on a connection
+type CompressionState struct {
+	Protocol   bool   // Exasol's own compression (useCompression upon login)
+	Websocket  bool   // The websocket's write compression (permessage-deflate)
+	Compressor string // The name of ConnConf.Compressor if any
+}
+
+// Compression returns the compression negotiated for the connection
+func (c *Conn) Compression() CompressionState { return c.compression }
+
+// ZlibCompressor is a WSCompressor using zlib (as Exasol's own compression does)
+type ZlibCompressor struct {
+	Level int // As for compress/zlib. Zero means zlib.DefaultCompression
+}
+
+func (z ZlibCompressor) Name() string { return "zlib" }
+
+func (z ZlibCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := z.Level
+	if level == 0 {
+		level = zlib.DefaultCompression
+	}
+	return zlib.NewWriterLevel(w, level)
+}
+
+func (z ZlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(r)
+}
+
+/*--- Private Routines ---*/
+
+// This wraps another handler compressing the JSON messages
+type compressWSHandler struct {
+	FrameWSHandler
+	comp WSCompressor
+}
+
+func (wsh *compressWSHandler) NextWriter() (io.WriteCloser, error) {
+	w, err := wsh.FrameWSHandler.NextWriter()
+	if err != nil {
+		return nil, err
+	}
+	cw, err := wsh.comp.NewWriter(w)
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	return &compressWriter{WriteCloser: cw, frame: w}, nil
+}
+
+func (wsh *compressWSHandler) NextReader() (io.Reader, error) {
+	r, err := wsh.FrameWSHandler.NextReader()
+	if err != nil {
+		return nil, err
+	}
+	return wsh.comp.NewReader(r)
+}
+
+func (wsh *compressWSHandler) WriteJSON(req interface{}) error { return writeFrameJSON(wsh, req) }
+func (wsh *compressWSHandler) ReadJSON(resp interface{}) error { return readFrameJSON(wsh, resp) }
+
+// Closing it flushes the compressor and then sends the message
+type compressWriter struct {
+	io.WriteCloser
+	frame io.WriteCloser
+}
+
+func (w *compressWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if e := w.frame.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Sends the JSON as a single message
+func writeFrameJSON(wsh FrameWSHandler, req interface{}) error {
+	w, err := wsh.NextWriter()
+	if err != nil {
+		return err
+	}
+	err = json.NewEncoder(w).Encode(req)
+	if e := w.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Decodes the next message as JSON
+func readFrameJSON(wsh FrameWSHandler, resp interface{}) error {
+	r, err := wsh.NextReader()
+	if err != nil {
+		return err
+	}
+	return decodeJSON(r, resp)
+}
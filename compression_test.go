@@ -0,0 +1,32 @@
+package exasol
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+func (s *testSuite) TestCompressor() {
+	frames := &frameWSHandler{}
+	wsh := &compressWSHandler{FrameWSHandler: frames, comp: ZlibCompressor{}}
+	req := &execReq{Command: "execute", SqlText: "SELECT '" + string(bytes.Repeat([]byte("a"), 1000)) + "'"}
+	s.NoError(wsh.WriteJSON(req))
+	if s.Len(frames.frames, 1) {
+		s.Less(frames.frames[0].Len(), 200, "Compressed")
+	}
+	got := &execReq{}
+	s.NoError(wsh.ReadJSON(got))
+	s.Equal(req, got)
+	s.Error(wsh.ReadJSON(got), "No more messages")
+
+	_, err := ZlibCompressor{Level: zlib.BestCompression + 1}.NewWriter(io.Discard)
+	s.Error(err)
+
+	s.Equal(CompressionState{}, s.exaConn.Compression())
+	conf := s.connConf()
+	conf.SuppressError = true
+	conf.WSHandler = &testWSHandler{}
+	conf.Compressor = ZlibCompressor{}
+	_, err = Connect(conf)
+	s.ErrorContains(err, "must implement FrameWSHandler")
+}
@@ -0,0 +1,217 @@
+/*
+	This encodes Go values into CSV data for the bulk IMPORT APIs.
+
+	Exasol parses imported CSV data using the session's numeric characters
+	and date/timestamp formats so the encoder is normally created from the
+	session (via NewCSVEncoder) to make sure the two agree. Otherwise
+	values can be silently misread (e.g. day and month swapped).
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+type CSVEncoder struct {
+	Separator   byte // Defaults to ,
+	Quote       byte // Defaults to "
+	DecimalMark byte // Defaults to .
+	// Go time layouts for DATE and TIMESTAMP values
+	DateFormat      string // Defaults to 2006-01-02
+	TimestampFormat string // Defaults to 2006-01-02 15:04:05.000000
+	// If set time.Time values for DATE columns are formatted with
+	// DateFormat. Otherwise TimestampFormat is always used.
+	Columns []Column
+}
+
+// NewCSVEncoder returns an encoder matching the session's
+// numeric characters and date/timestamp formats
+func (c *Conn) NewCSVEncoder() (*CSVEncoder, error) {
+	attr, err := c.GetSessionAttr()
+	if err != nil {
+		return nil, err
+	}
+	enc := &CSVEncoder{}
+	if len(attr.NumericCharacters) > 0 {
+		enc.DecimalMark = attr.NumericCharacters[0]
+	}
+	if attr.DateFormat != "" {
+		enc.DateFormat, err = ExasolToGoLayout(attr.DateFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if attr.DatetimeFormat != "" {
+		enc.TimestampFormat, err = ExasolToGoLayout(attr.DatetimeFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return enc, nil
+}
+
+// Encode appends the rows to buf as CSV
+func (e *CSVEncoder) Encode(buf *bytes.Buffer, rows [][]interface{}) error {
+	sep, quote, decimal := e.Separator, e.Quote, e.DecimalMark
+	if sep == 0 {
+		sep = ','
+	}
+	if quote == 0 {
+		quote = '"'
+	}
+	if decimal == 0 {
+		decimal = '.'
+	}
+	if decimal == sep {
+		return fmt.Errorf("The CSV separator and decimal mark can't both be %q", sep)
+	}
+	for r, row := range rows {
+		for i, val := range row {
+			if i > 0 {
+				buf.WriteByte(sep)
+			}
+			str, quotable, err := e.format(val, i, decimal)
+			if err != nil {
+				return fmt.Errorf("Unable to encode row %d column %d: %s", r, i, err)
+			}
+			if quotable && strings.ContainsAny(str, string([]byte{sep, quote, '\n', '\r'})) {
+				q := string(quote)
+				str = q + strings.ReplaceAll(str, q, q+q) + q
+			}
+			buf.WriteString(str)
+		}
+		buf.WriteByte('\n')
+	}
+	return nil
+}
+
+// BulkInsertRows is a typed version of BulkInsert which encodes the rows
+// using a CSVEncoder matching the session and the table's columns
+func (c *Conn) BulkInsertRows(schema, table string, rows [][]interface{}) error {
+	if schema == "" || table == "" {
+		return fmt.Errorf("You must pass in a schema and table to BulkInsertRows")
+	}
+	enc, err := c.NewCSVEncoder()
+	if err != nil {
+		return err
+	}
+	enc.Columns, err = c.DescribeQuery(fmt.Sprintf(
+		"SELECT * FROM %s.%s", c.QuoteIdent(schema), c.QuoteIdent(table),
+	))
+	if err != nil {
+		return err
+	}
+	data := new(bytes.Buffer)
+	if err = enc.Encode(data, rows); err != nil {
+		return err
+	}
+	return c.BulkInsert(schema, table, data)
+}
+
+// ExasolToGoLayout converts an Exasol date/timestamp format
+// (e.g. YYYY-MM-DD HH24:MI:SS.FF3) to a Go time layout
+func ExasolToGoLayout(format string) (string, error) {
+	var layout strings.Builder
+	upper := strings.ToUpper(format)
+FORMAT:
+	for i := 0; i < len(upper); {
+		for _, t := range layoutTokens {
+			if strings.HasPrefix(upper[i:], t.exa) {
+				layout.WriteString(t.golang)
+				i += len(t.exa)
+				continue FORMAT
+			}
+		}
+		ch := upper[i]
+		if ch >= 'A' && ch <= 'Z' {
+			return "", fmt.Errorf("Unsupported date format element in %s at %s", format, format[i:])
+		}
+		layout.WriteByte(ch)
+		i++
+	}
+	return layout.String(), nil
+}
+
+/*--- Private Routines ---*/
+
+// Longest first so that e.g. MONTH isn't taken as MON + TH
+var layoutTokens = []struct{ exa, golang string }{
+	{"YYYY", "2006"}, {"MONTH", "January"}, {"HH24", "15"}, {"HH12", "03"},
+	{"FF1", "0"}, {"FF2", "00"}, {"FF3", "000"}, {"FF4", "0000"},
+	{"FF5", "00000"}, {"FF6", "000000"}, {"FF7", "0000000"},
+	{"FF8", "00000000"}, {"FF9", "000000000"}, {"DAY", "Monday"},
+	{"MON", "Jan"}, {"YY", "06"}, {"MM", "01"}, {"DD", "02"},
+	{"HH", "03"}, {"MI", "04"}, {"SS", "05"}, {"DY", "Mon"},
+	{"AM", "PM"}, {"PM", "PM"},
+}
+
+// Returns the value as a string and whether it may need quoting
+func (e *CSVEncoder) format(val interface{}, col int, decimal byte) (string, bool, error) {
+	setDecimal := func(s string) string {
+		if decimal != '.' {
+			s = strings.Replace(s, ".", string(decimal), 1)
+		}
+		return s
+	}
+	switch v := val.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return v, true, nil
+	case []byte:
+		return string(v), true, nil
+	case bool:
+		return strings.ToUpper(strconv.FormatBool(v)), false, nil
+	case json.Number:
+		return setDecimal(v.String()), false, nil
+	case time.Time:
+		layout := e.TimestampFormat
+		if layout == "" {
+			layout = "2006-01-02 15:04:05.000000"
+		}
+		if col < len(e.Columns) && e.Columns[col].DataType.Type == "DATE" {
+			layout = e.DateFormat
+			if layout == "" {
+				layout = "2006-01-02"
+			}
+		}
+		return v.Format(layout), true, nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "", false, nil
+		}
+		return e.format(rv.Elem().Interface(), col, decimal)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return setDecimal(strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits())), false, nil
+	case reflect.String:
+		return rv.String(), true, nil
+	}
+	return "", false, fmt.Errorf("Unsupported type %T", val)
+}
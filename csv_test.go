@@ -0,0 +1,67 @@
+package exasol
+
+import (
+	"bytes"
+	"time"
+)
+
+func (s *testSuite) TestCSVEncoder() {
+	ts := time.Date(2020, 1, 31, 12, 34, 56, 789000000, time.UTC)
+	five := 5
+	rows := [][]interface{}{
+		{1, 2.5, "a,b", `say "hi"`, true, nil, ts, &five},
+	}
+
+	enc := &CSVEncoder{}
+	data := new(bytes.Buffer)
+	s.NoError(enc.Encode(data, rows))
+	s.Equal("1,2.5,\"a,b\",\"say \"\"hi\"\"\",TRUE,,2020-01-31 12:34:56.789000,5\n", data.String())
+
+	enc = &CSVEncoder{
+		Separator:       ';',
+		DecimalMark:     ',',
+		TimestampFormat: "02.01.2006",
+	}
+	data.Reset()
+	s.NoError(enc.Encode(data, rows))
+	s.Equal("1;2,5;a,b;\"say \"\"hi\"\"\";TRUE;;31.01.2020;5\n", data.String())
+
+	enc = &CSVEncoder{DecimalMark: ','}
+	s.Error(enc.Encode(data, rows), "Ambiguous separator")
+	enc = &CSVEncoder{}
+	err := enc.Encode(data, [][]interface{}{{struct{}{}}})
+	if s.Error(err) {
+		s.Contains(err.Error(), "Unsupported type")
+	}
+
+	layout, err := ExasolToGoLayout("DD.MM.YYYY HH24:MI:SS.FF3")
+	s.NoError(err)
+	s.Equal("02.01.2006 15:04:05.000", layout)
+	layout, err = ExasolToGoLayout("dd-Mon-yy hh:mi AM")
+	s.NoError(err)
+	s.Equal("02-Jan-06 03:04 PM", layout)
+	_, err = ExasolToGoLayout("IYYY-IW")
+	s.Error(err)
+}
+
+func (s *testSuite) TestBulkInsertRows() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id INT, amt DECIMAL(5,2), d DATE, ts TIMESTAMP )`)
+	s.execute(`ALTER SESSION SET NLS_NUMERIC_CHARACTERS = ',.'`)
+	s.execute(`ALTER SESSION SET NLS_DATE_FORMAT = 'DD.MM.YYYY'`)
+	defer s.execute(`ALTER SESSION SET NLS_NUMERIC_CHARACTERS = '.,'`)
+	defer s.execute(`ALTER SESSION SET NLS_DATE_FORMAT = 'YYYY-MM-DD'`)
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	exa.Conf.SuppressError = true
+	err := exa.BulkInsertRows(s.qschema, "foo", [][]interface{}{{1, 1.5, ts, ts}})
+	if s.Error(err) {
+		s.Contains(err.Error(), "can't both be")
+	}
+
+	s.execute(`ALTER SESSION SET NLS_NUMERIC_CHARACTERS = '.,'`)
+	err = exa.BulkInsertRows(s.qschema, "foo", [][]interface{}{{1, 1.5, ts, ts}})
+	s.NoError(err)
+	got := s.fetch(`SELECT id, amt, TO_CHAR(d, 'YYYY-MM-DD'), TO_CHAR(ts, 'YYYY-MM-DD HH24:MI:SS') FROM foo`)
+	s.Equal([][]interface{}{{float64(1), float64(1.5), "2020-01-02", "2020-01-02 03:04:05"}}, got)
+}
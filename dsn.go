@@ -0,0 +1,138 @@
+/*
+	Parsing Exasol connection strings (DSNs) into ConnConfs so that
+	tools can take the connection details as a single argument
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// ParseDSN parses a connection string in the same format as Exasol's
+// other drivers into a ConnConf e.g.
+//
+//	exa:10.0.0.11..14:8563;user=sys;password=exasol;schema=my_schema
+//
+// The port defaults to 8563. The keys (which are case insensitive) are:
+//
+//	user, password, schema, clientname, clientversion
+//	connecttimeout, querytimeout (seconds or a duration e.g. 90s)
+//	validateservercertificate (0 skips verifying the TLS certificate)
+//	tlsservername, typedints, usenumber
+//
+// Semicolons can't appear in the values.
+func ParseDSN(dsn string) (ConnConf, error) {
+	conf := ConnConf{}
+	if !strings.HasPrefix(strings.ToLower(dsn), "exa:") {
+		return conf, fmt.Errorf("Invalid DSN: it must start with exa:")
+	}
+	parts := strings.Split(dsn[len("exa:"):], ";")
+
+	host, port := parts[0], "8563"
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		host, port = host[:i], host[i+1:]
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil || p == 0 {
+		return conf, fmt.Errorf("Invalid DSN port: %q", port)
+	}
+	if host == "" {
+		return conf, fmt.Errorf("Invalid DSN: it has no host")
+	}
+	conf.Host, conf.Port = host, uint16(p)
+
+	for _, part := range parts[1:] {
+		if part == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return conf, fmt.Errorf("Invalid DSN parameter %q: it must be key=value", part)
+		}
+		if err := conf.setDSNParam(strings.ToLower(strings.TrimSpace(key)), val); err != nil {
+			return conf, fmt.Errorf("Invalid DSN parameter %s: %s", key, err)
+		}
+	}
+	return conf, nil
+}
+
+/*--- Private Routines ---*/
+
+func (cc *ConnConf) setDSNParam(key, val string) (err error) {
+	switch key {
+	case "user":
+		cc.Username = val
+	case "password":
+		cc.Password = val
+	case "schema":
+		if cc.SessionAttributes == nil {
+			cc.SessionAttributes = &Attributes{}
+		}
+		cc.SessionAttributes.CurrentSchema = val
+	case "clientname":
+		cc.ClientName = val
+	case "clientversion":
+		cc.ClientVersion = val
+	case "connecttimeout":
+		cc.ConnectTimeout, err = parseDSNDuration(val)
+	case "querytimeout":
+		cc.QueryTimeout, err = parseDSNDuration(val)
+	case "validateservercertificate":
+		var verify bool
+		verify, err = parseDSNBool(val)
+		if err == nil && !verify {
+			if cc.TLSConfig == nil {
+				cc.TLSConfig = &tls.Config{}
+			}
+			cc.TLSConfig.InsecureSkipVerify = true
+		}
+	case "tlsservername":
+		cc.TLSServerName = val
+	case "typedints":
+		cc.TypedInts, err = parseDSNBool(val)
+	case "usenumber":
+		cc.UseNumber, err = parseDSNBool(val)
+	default:
+		return fmt.Errorf("unknown parameter")
+	}
+	return err
+}
+
+// Accepts a number of seconds (as other drivers do) or a Go duration
+func parseDSNDuration(val string) (time.Duration, error) {
+	if secs, err := strconv.ParseUint(val, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("%q isn't a number of seconds or a duration", val)
+	}
+	return d, nil
+}
+
+func parseDSNBool(val string) (bool, error) {
+	switch strings.ToLower(val) {
+	case "1", "true", "yes", "on":
+		return true, nil
+	case "0", "false", "no", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q isn't a boolean", val)
+}
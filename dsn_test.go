@@ -0,0 +1,38 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestParseDSN() {
+	conf, err := ParseDSN("exa:10.0.0.11..14;user=sys;Password=a=b;schema=test;" +
+		"querytimeout=30;connecttimeout=1m;validateservercertificate=0;typedints=1")
+	s.NoError(err)
+	s.Equal("10.0.0.11..14", conf.Host)
+	s.Equal(uint16(8563), conf.Port)
+	s.Equal("sys", conf.Username)
+	s.Equal("a=b", conf.Password)
+	s.Equal("test", conf.SessionAttributes.CurrentSchema)
+	s.Equal(30*time.Second, conf.QueryTimeout)
+	s.Equal(time.Minute, conf.ConnectTimeout)
+	s.True(conf.TLSConfig.InsecureSkipVerify)
+	s.True(conf.TypedInts)
+	s.False(conf.UseNumber)
+
+	conf, err = ParseDSN("EXA:db.example.com:9000")
+	s.NoError(err)
+	s.Equal("db.example.com", conf.Host)
+	s.Equal(uint16(9000), conf.Port)
+	s.Nil(conf.TLSConfig)
+
+	for dsn, msg := range map[string]string{
+		"db:8563":                "Invalid DSN: it must start with exa:",
+		"exa::8563":              "Invalid DSN: it has no host",
+		"exa:db:port":            `Invalid DSN port: "port"`,
+		"exa:db;user":            `Invalid DSN parameter "user": it must be key=value`,
+		"exa:db;color=red":       "Invalid DSN parameter color: unknown parameter",
+		"exa:db;querytimeout=-1": `Invalid DSN parameter querytimeout: "-1" isn't a number of seconds or a duration`,
+		"exa:db;usenumber=maybe": `Invalid DSN parameter usenumber: "maybe" isn't a boolean`,
+	} {
+		_, err := ParseDSN(dsn)
+		s.EqualError(err, msg, dsn)
+	}
+}
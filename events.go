@@ -0,0 +1,91 @@
+/*
+	Connection state change notifications (see ConnConf.OnStateChange)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "time"
+
+type ConnState int32
+
+const (
+	StateConnected ConnState = iota
+	StateBroken              // A websocket error left the protocol in an unknown state
+	StateClosed              // Disconnected or the ConnectContext context is done
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnected:
+		return "connected"
+	case StateBroken:
+		return "broken"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+type ConnEventType int
+
+const (
+	EventConnected         ConnEventType = iota // Websocket connected to Host
+	EventAuthenticated                          // Logged in as SessionID
+	EventAttributesChanged                      // Attributes has the new values if the server sent them
+	EventDisconnected                           // Err is why, if it wasn't via Disconnect
+	EventProxyOpened                            // Bulk IMPORT/EXPORT proxy opened to Host
+	EventProxyClosed
+	EventProxyStats // ProxyStats has a bulk transfer's throughput (see ConnConf.ProxyStatsInterval)
+)
+
+func (t ConnEventType) String() string {
+	switch t {
+	case EventConnected:
+		return "connected"
+	case EventAuthenticated:
+		return "authenticated"
+	case EventAttributesChanged:
+		return "attributes changed"
+	case EventDisconnected:
+		return "disconnected"
+	case EventProxyOpened:
+		return "proxy opened"
+	case EventProxyClosed:
+		return "proxy closed"
+	case EventProxyStats:
+		return "proxy stats"
+	}
+	return "unknown"
+}
+
+type ConnEvent struct {
+	Type       ConnEventType
+	Time       time.Time
+	SessionID  uint64
+	Host       string
+	Attributes *Attributes
+	ProxyStats *ProxyStats
+	Err        error
+}
+
+/*--- Private Routines ---*/
+
+// Events are delivered synchronously on whichever Go routine caused them
+func (c *Conn) emit(ev ConnEvent) {
+	if c.Conf.OnStateChange == nil {
+		return
+	}
+	ev.Time = time.Now()
+	ev.SessionID = c.SessionID
+	c.Conf.OnStateChange(ev)
+}
@@ -0,0 +1,233 @@
+/*
+	Package exaarrow loads Arrow IPC files and streams (via arrow-go) into
+	Exasol tables by converting their record batches into CSV chunks that
+	are streamed through the bulk IMPORT path.
+
+	It's a separate module so that the main package doesn't depend on
+	arrow-go. Column types are imported as follows:
+
+	    BOOL                      BOOLEAN
+	    INT*, UINT*, FLOAT*       numbers
+	    DECIMAL128, DECIMAL256    numbers (with no loss of precision)
+	    DATE32, DATE64            dates
+	    TIMESTAMP                 timestamps (converted to UTC)
+	    STRING, LARGE_STRING      strings
+
+	Anything else (e.g. nested or dictionary encoded columns) isn't supported.
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exaarrow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+)
+
+/*--- Public Interface ---*/
+
+// ImportOpts controls how Arrow data is loaded into a table
+type ImportOpts struct {
+	// Maps Arrow column names to table columns. Unlisted columns are
+	// loaded into the table column of the same name (matched exactly or
+	// else case-insensitively) and those mapped to "" are skipped.
+	Columns map[string]string
+}
+
+// ImportFile loads the Arrow IPC file (or stream) at path into the table
+// (see Import)
+func ImportFile(
+	conn *exasol.Conn, schema, table, path string, opts ImportOpts,
+) (*exasol.BulkResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if fr, err := ipc.NewFileReader(f); err == nil {
+		defer fr.Close()
+		return importRecords(conn, schema, table, fr.Schema(), fr.Read, opts)
+	}
+	// Not the file format so try it as a stream
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	sr, err := ipc.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer sr.Release()
+	return Import(conn, schema, table, sr, opts)
+}
+
+// Import loads the record batches read from rr (e.g. an ipc.Reader) into
+// the table. The columns are mapped to the table's and their types checked
+// (see exasol.Conn.MapImportColumns) before anything is loaded, and the
+// batches are then streamed in one at a time. Should reading fail part way
+// the import is rolled back.
+func Import(
+	conn *exasol.Conn, schema, table string, rr array.RecordReader, opts ImportOpts,
+) (*exasol.BulkResult, error) {
+	return importRecords(conn, schema, table, rr.Schema(), func() (arrow.Record, error) {
+		if rr.Next() {
+			return rr.Record(), nil
+		} else if err := rr.Err(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}, opts)
+}
+
+/*--- Private Routines ---*/
+
+// An Arrow column being imported
+type source struct {
+	exasol.ImportColumn
+	index   int
+	convert func(arrow.Array, int) interface{}
+}
+
+// Imports the records returned by next (until io.EOF). Each record is
+// only used until next is called again as readers reuse them.
+func importRecords(
+	conn *exasol.Conn, schema, table string, sch *arrow.Schema,
+	next func() (arrow.Record, error), opts ImportOpts,
+) (*exasol.BulkResult, error) {
+	srcs, err := sources(sch, opts)
+	if err != nil {
+		return nil, err
+	}
+	importCols := make([]exasol.ImportColumn, len(srcs))
+	for i, src := range srcs {
+		importCols[i] = src.ImportColumn
+	}
+	cols, err := conn.MapImportColumns(schema, table, importCols)
+	if err != nil {
+		return nil, err
+	}
+	return conn.StreamInsertRows(schema, table, cols, func(yield func([]interface{}, error) bool) {
+		for {
+			rec, err := next()
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+			for j := 0; j < int(rec.NumRows()); j++ {
+				row := make([]interface{}, len(srcs))
+				for i, src := range srcs {
+					if col := rec.Column(src.index); !col.IsNull(j) {
+						row[i] = src.convert(col, j)
+					}
+				}
+				if !yield(row, nil) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Works out the columns to import, their kinds and how to convert their values
+func sources(sch *arrow.Schema, opts ImportOpts) ([]source, error) {
+	var srcs []source
+	for i, field := range sch.Fields() {
+		column, mapped := opts.Columns[field.Name]
+		if mapped && column == "" {
+			continue
+		}
+		kind, convert, err := converter(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("Column %s: %s", field.Name, err)
+		}
+		srcs = append(srcs, source{
+			ImportColumn: exasol.ImportColumn{Name: field.Name, Kind: kind, Column: column},
+			index:        i,
+			convert:      convert,
+		})
+	}
+	for name := range opts.Columns {
+		if len(sch.FieldIndices(name)) == 0 {
+			return nil, fmt.Errorf("The data has no column %s", name)
+		}
+	}
+	if len(srcs) == 0 {
+		return nil, fmt.Errorf("There are no columns to import")
+	}
+	return srcs, nil
+}
+
+// Returns the import kind for the Arrow type and a function to convert
+// its (non-null) values into ones exasol.CSVEncoder accepts
+func converter(dt arrow.DataType) (exasol.ImportKind, func(arrow.Array, int) interface{}, error) {
+	switch t := dt.(type) {
+	case *arrow.BooleanType:
+		return exasol.ImportBool, func(a arrow.Array, i int) interface{} {
+			return a.(*array.Boolean).Value(i)
+		}, nil
+	case *arrow.Int8Type, *arrow.Int16Type, *arrow.Int32Type, *arrow.Int64Type,
+		*arrow.Uint8Type, *arrow.Uint16Type, *arrow.Uint32Type, *arrow.Uint64Type:
+		return exasol.ImportNumber, func(a arrow.Array, i int) interface{} {
+			return json.Number(a.ValueStr(i))
+		}, nil
+	case *arrow.Float32Type:
+		return exasol.ImportNumber, func(a arrow.Array, i int) interface{} {
+			return a.(*array.Float32).Value(i)
+		}, nil
+	case *arrow.Float64Type:
+		return exasol.ImportNumber, func(a arrow.Array, i int) interface{} {
+			return a.(*array.Float64).Value(i)
+		}, nil
+	case *arrow.Decimal128Type:
+		return exasol.ImportNumber, func(a arrow.Array, i int) interface{} {
+			return json.Number(a.(*array.Decimal128).Value(i).ToString(t.Scale))
+		}, nil
+	case *arrow.Decimal256Type:
+		return exasol.ImportNumber, func(a arrow.Array, i int) interface{} {
+			return json.Number(a.(*array.Decimal256).Value(i).ToString(t.Scale))
+		}, nil
+	case *arrow.Date32Type:
+		return exasol.ImportDate, func(a arrow.Array, i int) interface{} {
+			return a.(*array.Date32).Value(i).ToTime()
+		}, nil
+	case *arrow.Date64Type:
+		return exasol.ImportDate, func(a arrow.Array, i int) interface{} {
+			return a.(*array.Date64).Value(i).ToTime()
+		}, nil
+	case *arrow.TimestampType:
+		toTime, err := t.GetToTimeFunc()
+		if err != nil {
+			return 0, nil, err
+		}
+		return exasol.ImportTimestamp, func(a arrow.Array, i int) interface{} {
+			return toTime(a.(*array.Timestamp).Value(i)).UTC()
+		}, nil
+	case *arrow.StringType:
+		return exasol.ImportString, func(a arrow.Array, i int) interface{} {
+			return a.(*array.String).Value(i)
+		}, nil
+	case *arrow.LargeStringType:
+		return exasol.ImportString, func(a arrow.Array, i int) interface{} {
+			return a.(*array.LargeString).Value(i)
+		}, nil
+	}
+	return 0, nil, fmt.Errorf("Unsupported type %s", dt)
+}
@@ -0,0 +1,69 @@
+package exaarrow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/decimal128"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSources(t *testing.T) {
+	sch := arrow.NewSchema([]arrow.Field{
+		{Name: "id", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "price", Type: &arrow.Decimal128Type{Precision: 10, Scale: 2}, Nullable: true},
+		{Name: "ok", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "d", Type: arrow.FixedWidthTypes.Date32, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	b := array.NewRecordBuilder(memory.DefaultAllocator, sch)
+	defer b.Release()
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC)
+	b.Field(0).(*array.Int64Builder).AppendValues([]int64{1, 2}, nil)
+	b.Field(1).(*array.Decimal128Builder).AppendValues([]decimal128.Num{decimal128.FromI64(-150), {}}, []bool{true, false})
+	b.Field(2).(*array.BooleanBuilder).AppendValues([]bool{true, false}, []bool{true, false})
+	b.Field(3).(*array.Date32Builder).AppendValues([]arrow.Date32{arrow.Date32FromTime(ts), 0}, []bool{true, false})
+	b.Field(4).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(ts.UnixMicro()), 0}, []bool{true, false})
+	b.Field(5).(*array.StringBuilder).AppendValues([]string{"a", ""}, []bool{true, false})
+	rec := b.NewRecord()
+	defer rec.Release()
+
+	srcs, err := sources(sch, ImportOpts{Columns: map[string]string{"name": "", "id": "KEY"}})
+	assert.NoError(t, err)
+	kinds := map[string]exasol.ImportKind{}
+	got := map[string][]interface{}{}
+	for _, src := range srcs {
+		kinds[src.Name] = src.Kind
+		col := rec.Column(src.index)
+		for j := 0; j < int(rec.NumRows()); j++ {
+			if col.IsNull(j) {
+				got[src.Name] = append(got[src.Name], nil)
+			} else {
+				got[src.Name] = append(got[src.Name], src.convert(col, j))
+			}
+		}
+	}
+	assert.Equal(t, "KEY", srcs[0].Column)
+	assert.Equal(t, map[string]exasol.ImportKind{
+		"id": exasol.ImportNumber, "price": exasol.ImportNumber, "ok": exasol.ImportBool,
+		"d": exasol.ImportDate, "ts": exasol.ImportTimestamp,
+	}, kinds)
+	assert.Equal(t, map[string][]interface{}{
+		"id":    {json.Number("1"), json.Number("2")},
+		"price": {json.Number("-1.50"), nil},
+		"ok":    {true, nil},
+		"d":     {time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), nil},
+		"ts":    {ts, nil},
+	}, got)
+
+	_, err = sources(sch, ImportOpts{Columns: map[string]string{"nope": "X"}})
+	assert.ErrorContains(t, err, "The data has no column nope")
+	_, _, err = converter(arrow.ListOf(arrow.PrimitiveTypes.Int64))
+	assert.ErrorContains(t, err, "Unsupported type")
+}
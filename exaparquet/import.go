@@ -0,0 +1,264 @@
+/*
+	Loading local Parquet files into Exasol tables by converting their
+	rows into CSV chunks that are streamed through the bulk IMPORT path
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exaparquet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+/*--- Public Interface ---*/
+
+// ImportOpts controls how a Parquet file is loaded into a table
+type ImportOpts struct {
+	// Maps Parquet column names to table columns. Unlisted columns are
+	// loaded into the table column of the same name (matched exactly or
+	// else case-insensitively) and those mapped to "" are skipped.
+	Columns map[string]string
+}
+
+// ImportFile loads the Parquet file at path into the table (see Import)
+func ImportFile(
+	conn *exasol.Conn, schema, table, path string, opts ImportOpts,
+) (*exasol.BulkResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Import(conn, schema, table, f, info.Size(), opts)
+}
+
+// Import loads the Parquet data into the table. The file's columns are
+// mapped to the table's and their types checked (see
+// exasol.Conn.MapImportColumns) before anything is loaded, and the rows
+// are then streamed in without reading the whole file into memory.
+// Only flat files are supported i.e. no nested or repeated columns.
+// Should the file turn out to be invalid part way the import is rolled back.
+func Import(
+	conn *exasol.Conn, schema, table string, r io.ReaderAt, size int64, opts ImportOpts,
+) (*exasol.BulkResult, error) {
+	f, err := pq.OpenFile(r, size)
+	if err != nil {
+		return nil, err
+	}
+	srcs, err := sources(f.Schema(), opts)
+	if err != nil {
+		return nil, err
+	}
+	importCols := make([]exasol.ImportColumn, len(srcs))
+	for i, src := range srcs {
+		importCols[i] = src.ImportColumn
+	}
+	cols, err := conn.MapImportColumns(schema, table, importCols)
+	if err != nil {
+		return nil, err
+	}
+	return conn.StreamInsertRows(schema, table, cols, func(yield func([]interface{}, error) bool) {
+		reader := pq.NewReader(f)
+		defer reader.Close()
+		buf := make([]pq.Row, batchSize)
+		var n uint64
+		for {
+			count, err := reader.ReadRows(buf)
+			for _, pr := range buf[:count] {
+				n++
+				row := make([]interface{}, len(srcs))
+				for i, src := range srcs {
+					val, convErr := src.convert(pr[src.index])
+					if convErr != nil {
+						yield(nil, fmt.Errorf("Row %d column %s: %s", n, src.Name, convErr))
+						return
+					}
+					row[i] = val
+				}
+				if !yield(row, nil) {
+					return
+				}
+			}
+			if err == io.EOF {
+				return
+			} else if err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+	})
+}
+
+/*--- Private Routines ---*/
+
+// A Parquet column being imported
+type source struct {
+	exasol.ImportColumn
+	index   int // The leaf column index
+	convert func(pq.Value) (interface{}, error)
+}
+
+// Works out the columns to import, their kinds and how to convert their values
+func sources(schema *pq.Schema, opts ImportOpts) ([]source, error) {
+	var srcs []source
+	for _, field := range schema.Fields() {
+		name := field.Name()
+		column, mapped := opts.Columns[name]
+		if mapped && column == "" {
+			continue
+		}
+		if !field.Leaf() || field.Repeated() {
+			return nil, fmt.Errorf("Column %s is nested or repeated which isn't supported", name)
+		}
+		leaf, _ := schema.Lookup(name)
+		kind, convert, err := converter(field.Type())
+		if err != nil {
+			return nil, fmt.Errorf("Column %s: %s", name, err)
+		}
+		srcs = append(srcs, source{
+			ImportColumn: exasol.ImportColumn{Name: name, Kind: kind, Column: column},
+			index:        leaf.ColumnIndex,
+			convert:      convert,
+		})
+	}
+	for name := range opts.Columns {
+		if _, ok := schema.Lookup(name); !ok {
+			return nil, fmt.Errorf("The file has no column %s", name)
+		}
+	}
+	if len(srcs) == 0 {
+		return nil, fmt.Errorf("There are no columns to import")
+	}
+	return srcs, nil
+}
+
+// Returns the import kind for the Parquet type and a function
+// to convert its values into ones exasol.CSVEncoder accepts
+func converter(t pq.Type) (exasol.ImportKind, func(pq.Value) (interface{}, error), error) {
+	nullable := func(fn func(pq.Value) (interface{}, error)) func(pq.Value) (interface{}, error) {
+		return func(v pq.Value) (interface{}, error) {
+			if v.IsNull() {
+				return nil, nil
+			}
+			return fn(v)
+		}
+	}
+	lt := t.LogicalType()
+	if lt == nil {
+		lt = &format.LogicalType{}
+	}
+
+	switch {
+	case lt.Decimal != nil:
+		scale := int(lt.Decimal.Scale)
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			unscaled := new(big.Int)
+			switch v.Kind() {
+			case pq.Int32:
+				unscaled.SetInt64(int64(v.Int32()))
+			case pq.Int64:
+				unscaled.SetInt64(v.Int64())
+			default:
+				b := v.ByteArray()
+				unscaled.SetBytes(b)
+				if len(b) > 0 && b[0]&0x80 != 0 { // Two's complement
+					unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(8*len(b))))
+				}
+			}
+			return json.Number(decimalString(unscaled, scale)), nil
+		}), nil
+	case lt.Date != nil:
+		return exasol.ImportDate, nullable(func(v pq.Value) (interface{}, error) {
+			return time.Unix(int64(v.Int32())*86400, 0).UTC(), nil
+		}), nil
+	case lt.Timestamp != nil:
+		unit := lt.Timestamp.Unit
+		return exasol.ImportTimestamp, nullable(func(v pq.Value) (interface{}, error) {
+			switch {
+			case unit.Millis != nil:
+				return time.UnixMilli(v.Int64()).UTC(), nil
+			case unit.Micros != nil:
+				return time.UnixMicro(v.Int64()).UTC(), nil
+			}
+			return time.Unix(0, v.Int64()).UTC(), nil
+		}), nil
+	case lt.Integer != nil && !lt.Integer.IsSigned:
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			if v.Kind() == pq.Int32 {
+				return uint32(v.Int32()), nil
+			}
+			return uint64(v.Int64()), nil
+		}), nil
+	case lt.Time != nil || lt.UUID != nil:
+		return 0, nil, fmt.Errorf("Unsupported type %s", t)
+	}
+
+	switch t.Kind() {
+	case pq.Boolean:
+		return exasol.ImportBool, nullable(func(v pq.Value) (interface{}, error) {
+			return v.Boolean(), nil
+		}), nil
+	case pq.Int32:
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			return v.Int32(), nil
+		}), nil
+	case pq.Int64:
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			return v.Int64(), nil
+		}), nil
+	case pq.Float:
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			return v.Float(), nil
+		}), nil
+	case pq.Double:
+		return exasol.ImportNumber, nullable(func(v pq.Value) (interface{}, error) {
+			return v.Double(), nil
+		}), nil
+	case pq.ByteArray, pq.FixedLenByteArray:
+		return exasol.ImportString, nullable(func(v pq.Value) (interface{}, error) {
+			return string(v.ByteArray()), nil
+		}), nil
+	}
+	return 0, nil, fmt.Errorf("Unsupported type %s", t)
+}
+
+// Formats the integer as a decimal of its units of 10^-scale
+// e.g. 150 with a scale of 2 is 1.50 (the opposite of unscale)
+func decimalString(unscaled *big.Int, scale int) string {
+	digits := unscaled.String()
+	if scale <= 0 {
+		return digits
+	}
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	point := len(digits) - scale
+	return sign + digits[:point] + "." + digits[point:]
+}
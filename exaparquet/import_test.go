@@ -0,0 +1,81 @@
+package exaparquet
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimalString(t *testing.T) {
+	for want, n := range map[string]int64{
+		"1.50": 150, "-0.05": -5, "0.00": 0, "-123.45": -12345,
+	} {
+		assert.Equal(t, want, decimalString(big.NewInt(n), 2))
+	}
+	assert.Equal(t, "42", decimalString(big.NewInt(42), 0))
+}
+
+func TestSources(t *testing.T) {
+	// Written with our own Writer so it covers the round trip
+	cols := []exasol.Column{
+		{Name: "ID", DataType: exasol.DataType{Type: "DECIMAL", Precision: 18}},
+		{Name: "PRICE", DataType: exasol.DataType{Type: "DECIMAL", Precision: 10, Scale: 2}},
+		{Name: "OK", DataType: exasol.DataType{Type: "BOOLEAN"}},
+		{Name: "D", DataType: exasol.DataType{Type: "DATE"}},
+		{Name: "TS", DataType: exasol.DataType{Type: "TIMESTAMP"}},
+		{Name: "NAME", DataType: exasol.DataType{Type: "VARCHAR", Size: 10}},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cols)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Write([]interface{}{
+		"1", "-1.5", true, "2020-01-02", "2020-01-02 03:04:05.000006", "a",
+	}))
+	assert.NoError(t, w.Write([]interface{}{"2", nil, nil, nil, nil, nil}))
+	assert.NoError(t, w.Close())
+
+	f, err := pq.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	srcs, err := sources(f.Schema(), ImportOpts{Columns: map[string]string{"NAME": "", "ID": "KEY"}})
+	assert.NoError(t, err)
+
+	kinds := map[string]exasol.ImportKind{}
+	for _, src := range srcs {
+		kinds[src.Name] = src.Kind
+		if src.Name == "ID" {
+			assert.Equal(t, "KEY", src.Column)
+		}
+	}
+	assert.Equal(t, map[string]exasol.ImportKind{
+		"ID": exasol.ImportNumber, "PRICE": exasol.ImportNumber, "OK": exasol.ImportBool,
+		"D": exasol.ImportDate, "TS": exasol.ImportTimestamp,
+	}, kinds)
+
+	rows := make([]pq.Row, 2)
+	n, _ := pq.NewReader(f).ReadRows(rows)
+	assert.Equal(t, 2, n)
+	got := map[string][]interface{}{}
+	for _, pr := range rows {
+		for _, src := range srcs {
+			val, err := src.convert(pr[src.index])
+			assert.NoError(t, err)
+			got[src.Name] = append(got[src.Name], val)
+		}
+	}
+	assert.Equal(t, map[string][]interface{}{
+		"ID":    {int64(1), int64(2)},
+		"PRICE": {json.Number("-1.50"), nil},
+		"OK":    {true, nil},
+		"D":     {time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), nil},
+		"TS":    {time.Date(2020, 1, 2, 3, 4, 5, 6000, time.UTC), nil},
+	}, got)
+
+	_, err = sources(f.Schema(), ImportOpts{Columns: map[string]string{"NOPE": "X"}})
+	assert.ErrorContains(t, err, "The file has no column NOPE")
+}
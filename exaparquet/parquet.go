@@ -0,0 +1,356 @@
+/*
+	Package exaparquet writes Exasol query results and exports to Parquet
+	files (via parquet-go) using the columns' data types for the schema,
+	and loads Parquet files into tables (see Import).
+
+	It's a separate module so that the main package doesn't depend on
+	parquet-go. Column types are mapped as follows:
+
+	    DECIMAL(p<=18, 0)   INT64
+	    DECIMAL(p<=18, s>0) DECIMAL(p, s) stored as an INT64
+	    DECIMAL(p>18, s)    STRING (so that no precision is lost)
+	    DOUBLE              DOUBLE
+	    BOOLEAN             BOOLEAN
+	    DATE                DATE
+	    TIMESTAMP           TIMESTAMP(MICROS) (WITH LOCAL TIME ZONE too)
+	    Anything else       STRING
+
+	All columns are optional (i.e. nullable).
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exaparquet
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	pq "github.com/parquet-go/parquet-go"
+)
+
+// Rows are buffered up to this many before being written
+const batchSize = 1000
+
+/*--- Public Interface ---*/
+
+// Schema returns the Parquet schema for the columns (see the package doc)
+func Schema(cols []exasol.Column) (*pq.Schema, error) {
+	group := pq.Group{}
+	for _, col := range cols {
+		if _, dup := group[col.Name]; dup {
+			return nil, fmt.Errorf("Duplicate column name %s", col.Name)
+		}
+		group[col.Name] = pq.Optional(node(col.DataType))
+	}
+	return pq.NewSchema("exasol", group), nil
+}
+
+// Writer writes rows of Exasol values (as returned by FetchChan etc. or
+// as strings from CSV exports) to a Parquet file
+type Writer struct {
+	cols   []exasol.Column
+	index  []int // Column => leaf column index (Parquet sorts them by name)
+	pw     *pq.Writer
+	batch  []pq.Row
+	rows   uint64
+	closed bool
+}
+
+// NewWriter creates a Writer for the columns. The options are passed
+// on to parquet-go e.g. pq.Compression(&pq.Zstd).
+func NewWriter(w io.Writer, cols []exasol.Column, opts ...pq.WriterOption) (*Writer, error) {
+	schema, err := Schema(cols)
+	if err != nil {
+		return nil, err
+	}
+	index := make([]int, len(cols))
+	for i, col := range cols {
+		leaf, ok := schema.Lookup(col.Name)
+		if !ok {
+			return nil, fmt.Errorf("Column %s is missing from the schema", col.Name)
+		}
+		index[i] = leaf.ColumnIndex
+	}
+	return &Writer{
+		cols:  cols,
+		index: index,
+		pw:    pq.NewWriter(w, append([]pq.WriterOption{schema}, opts...)...),
+	}, nil
+}
+
+// Write adds a row. The values must be in column order.
+func (w *Writer) Write(row []interface{}) error {
+	if len(row) != len(w.cols) {
+		return fmt.Errorf("Row has %d values but there are %d columns", len(row), len(w.cols))
+	}
+	pr := make(pq.Row, len(row))
+	for i, val := range row {
+		v, err := value(val, w.cols[i].DataType)
+		if err != nil {
+			return fmt.Errorf("Row %d column %s: %s", w.rows+1, w.cols[i].Name, err)
+		}
+		if v.IsNull() {
+			pr[w.index[i]] = v.Level(0, 0, w.index[i])
+		} else {
+			pr[w.index[i]] = v.Level(0, 1, w.index[i])
+		}
+	}
+	w.batch = append(w.batch, pr)
+	w.rows++
+	if len(w.batch) >= batchSize {
+		return w.flush()
+	}
+	return nil
+}
+
+// Rows returns the number of rows written so far
+func (w *Writer) Rows() uint64 { return w.rows }
+
+// Close writes any buffered rows and the Parquet footer.
+// It doesn't close the underlying io.Writer. Subsequent calls do nothing.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return w.pw.Close()
+}
+
+// WriteQuery runs the query (taking FetchRows' optional args) and writes
+// the results to w as Parquet returning the number of rows written.
+// Should anything fail the rows written so far are still closed off
+// as a valid Parquet file.
+func WriteQuery(conn *exasol.Conn, w io.Writer, sql string, args ...interface{}) (n uint64, err error) {
+	rows, err := conn.FetchRows(sql, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	pw, err := NewWriter(w, rows.ColumnTypes())
+	if err != nil {
+		return 0, err
+	}
+	defer closeOnError(pw, &err)
+	row := make([]interface{}, len(rows.Columns()))
+	dest := make([]interface{}, len(row))
+	for i := range row {
+		dest[i] = &row[i]
+	}
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return pw.Rows(), err
+		}
+		if err = pw.Write(row); err != nil {
+			return pw.Rows(), err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return pw.Rows(), err
+	}
+	if err = pw.Close(); err != nil {
+		return 0, err
+	}
+	return pw.Rows(), nil
+}
+
+// WriteExport writes the CSV data of an export (from StreamSelect with
+// ExportOpts.Describe, or StreamQuery with Rows.Columns set via
+// DescribeQuery) to w as Parquet returning the number of rows written.
+// The export must not include a header row (i.e. WithColumnNames).
+// Should anything fail the export is stopped.
+func WriteExport(rows *exasol.Rows, w io.Writer) (uint64, error) {
+	n, err := writeExport(rows, w)
+	if err != nil {
+		// Stop it and keep draining so that the reader isn't blocked
+		go rows.Close()
+		for chunk := range rows.Data {
+			rows.Pool.Put(chunk)
+		}
+		return n, err
+	}
+	return n, rows.Error
+}
+
+/*--- Private Routines ---*/
+
+func writeExport(rows *exasol.Rows, w io.Writer) (n uint64, err error) {
+	if len(rows.Columns) == 0 {
+		return 0, fmt.Errorf("Rows.Columns must be set to write Parquet")
+	}
+	pw, err := NewWriter(w, rows.Columns)
+	if err != nil {
+		return 0, err
+	}
+	defer closeOnError(pw, &err)
+	csvr := csv.NewReader(&chunkReader{rows: rows})
+	csvr.FieldsPerRecord = len(rows.Columns)
+	row := make([]interface{}, len(rows.Columns))
+	var rec []string
+	for {
+		rec, err = csvr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return pw.Rows(), err
+		}
+		for i, field := range rec {
+			if field == "" {
+				row[i] = nil // Exasol doesn't distinguish NULLs from empty strings
+			} else {
+				row[i] = field
+			}
+		}
+		if err = pw.Write(row); err != nil {
+			return pw.Rows(), err
+		}
+	}
+	if err = pw.Close(); err != nil {
+		return 0, err
+	}
+	return pw.Rows(), nil
+}
+
+// Closes the writer if the named err is set upon return so that the rows
+// written so far (as per the count returned) are still a readable file
+func closeOnError(pw *Writer, err *error) {
+	if *err != nil {
+		pw.Close()
+	}
+}
+
+func (w *Writer) flush() error {
+	if len(w.batch) == 0 {
+		return nil
+	}
+	_, err := w.pw.WriteRows(w.batch)
+	w.batch = w.batch[:0]
+	return err
+}
+
+func node(dt exasol.DataType) pq.Node {
+	switch dt.Type {
+	case "DECIMAL":
+		if dt.Precision > 18 {
+			return pq.String()
+		} else if dt.Scale == 0 {
+			return pq.Int(64)
+		}
+		return pq.Decimal(dt.Scale, dt.Precision, pq.Int64Type)
+	case "DOUBLE":
+		return pq.Leaf(pq.DoubleType)
+	case "BOOLEAN":
+		return pq.Leaf(pq.BooleanType)
+	case "DATE":
+		return pq.Date()
+	case "TIMESTAMP", "TIMESTAMP WITH LOCAL TIME ZONE":
+		return pq.Timestamp(pq.Microsecond)
+	}
+	return pq.String()
+}
+
+// Converts the value to the Parquet value for the column's type
+func value(val interface{}, dt exasol.DataType) (pq.Value, error) {
+	if val == nil {
+		return pq.NullValue(), nil
+	}
+	str, isStr := val.(string)
+	if !isStr {
+		str = fmt.Sprint(val)
+		if f, ok := val.(float64); ok {
+			str = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+
+	switch dt.Type {
+	case "DECIMAL":
+		if dt.Precision <= 18 {
+			unscaled, err := unscale(str, dt.Scale)
+			return pq.Int64Value(unscaled), err
+		}
+	case "DOUBLE":
+		f, err := strconv.ParseFloat(str, 64)
+		return pq.DoubleValue(f), err
+	case "BOOLEAN":
+		if b, ok := val.(bool); ok {
+			return pq.BooleanValue(b), nil
+		}
+		switch strings.ToUpper(str) {
+		case "TRUE", "1":
+			return pq.BooleanValue(true), nil
+		case "FALSE", "0":
+			return pq.BooleanValue(false), nil
+		}
+		return pq.Value{}, fmt.Errorf("Invalid BOOLEAN %q", str)
+	case "DATE":
+		t, err := time.Parse("2006-01-02", str)
+		if err != nil {
+			return pq.Value{}, err
+		}
+		return pq.Int32Value(int32(t.Unix() / 86400)), nil
+	case "TIMESTAMP", "TIMESTAMP WITH LOCAL TIME ZONE":
+		t, err := time.Parse("2006-01-02 15:04:05.999999999", str)
+		if err != nil {
+			return pq.Value{}, err
+		}
+		return pq.Int64Value(t.UnixMicro()), nil
+	}
+	return pq.ByteArrayValue([]byte(str)), nil
+}
+
+// Parses the decimal number as an integer of its units of 10^-scale
+// e.g. 1.5 with a scale of 2 is 150
+func unscale(str string, scale int) (int64, error) {
+	if _, err := json.Number(str).Float64(); err != nil || strings.ContainsAny(str, "eE") {
+		return 0, fmt.Errorf("Invalid DECIMAL %q", str)
+	}
+	whole, frac, _ := strings.Cut(str, ".")
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > scale {
+		return 0, fmt.Errorf("DECIMAL %q has more than %d decimal places", str, scale)
+	}
+	digits := whole + frac + strings.Repeat("0", scale-len(frac))
+	return strconv.ParseInt(digits, 10, 64)
+}
+
+// Reads the Rows.Data chunks in sequence returning each to the Pool once consumed
+type chunkReader struct {
+	rows  *exasol.Rows
+	chunk []byte
+	rest  []byte
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.rest) == 0 {
+		if cr.chunk != nil {
+			cr.rows.Pool.Put(cr.chunk)
+			cr.chunk = nil
+		}
+		chunk, ok := <-cr.rows.Data
+		if !ok {
+			return 0, io.EOF
+		}
+		cr.chunk, cr.rest = chunk, chunk
+	}
+	n := copy(p, cr.rest)
+	cr.rest = cr.rest[n:]
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package exaparquet
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/GrantStreetGroup/go-exasol-client"
+	pq "github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnscale(t *testing.T) {
+	for str, want := range map[string]int64{
+		"1.5": 150, "-.5": -50, "12": 1200, "0.10": 10, "-3.25": -325,
+	} {
+		got, err := unscale(str, 2)
+		assert.NoError(t, err, str)
+		assert.Equal(t, want, got, str)
+	}
+	for _, str := range []string{"1.234", "abc", "1e3", ""} {
+		_, err := unscale(str, 2)
+		assert.Error(t, err, str)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	cols := []exasol.Column{
+		{Name: "ID", DataType: exasol.DataType{Type: "DECIMAL", Precision: 18}},
+		{Name: "PRICE", DataType: exasol.DataType{Type: "DECIMAL", Precision: 10, Scale: 2}},
+		{Name: "BIG", DataType: exasol.DataType{Type: "DECIMAL", Precision: 36}},
+		{Name: "OK", DataType: exasol.DataType{Type: "BOOLEAN"}},
+		{Name: "D", DataType: exasol.DataType{Type: "DATE"}},
+		{Name: "TS", DataType: exasol.DataType{Type: "TIMESTAMP"}},
+		{Name: "A_NAME", DataType: exasol.DataType{Type: "VARCHAR", Size: 10}},
+	}
+	schema, err := Schema(cols)
+	assert.NoError(t, err)
+	assert.Len(t, schema.Fields(), len(cols))
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cols)
+	assert.NoError(t, err)
+	// As returned by the websocket API and by CSV exports
+	assert.NoError(t, w.Write([]interface{}{
+		float64(1), 1.5, "123456789012345678901234", true,
+		"2020-01-02", "2020-01-02 03:04:05.000000", "a",
+	}))
+	assert.NoError(t, w.Write([]interface{}{
+		"2", "2.25", nil, "0", nil, "2020-01-02 03:04:05", nil,
+	}))
+	assert.Error(t, w.Write([]interface{}{1}), "Wrong number of values")
+	assert.Error(t, w.Write([]interface{}{1, 1.234, nil, nil, nil, nil, nil}), "Too precise")
+	assert.NoError(t, w.Close())
+	assert.Equal(t, uint64(2), w.Rows())
+
+	f, err := pq.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), f.NumRows())
+
+	_, err = Schema(append(cols, cols[0]))
+	assert.Error(t, err, "Duplicate columns")
+}
+
+func TestWriteExportError(t *testing.T) {
+	rows := &exasol.Rows{
+		Columns: []exasol.Column{
+			{Name: "ID", DataType: exasol.DataType{Type: "DECIMAL", Precision: 18}},
+			{Name: "NAME", DataType: exasol.DataType{Type: "VARCHAR", Size: 10}},
+		},
+		Data: make(chan []byte, 1),
+		Pool: &sync.Pool{},
+	}
+	rows.Data <- []byte("1,a\n2,b,c\n")
+	close(rows.Data)
+
+	var buf bytes.Buffer
+	n, err := writeExport(rows, &buf)
+	assert.Error(t, err, "Wrong number of fields")
+	assert.Equal(t, uint64(1), n)
+
+	f, err := pq.OpenFile(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if assert.NoError(t, err, "Closed despite the error") {
+		assert.Equal(t, int64(1), f.NumRows())
+	}
+}
@@ -0,0 +1,89 @@
+/*
+	Bounding the memory a connection's result sets use
+	while they're being fetched (see ConnConf.FetchMemoryBudget)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "sync"
+
+/*--- Public Interface ---*/
+
+// FetchMemoryInUse returns the bytes currently reserved against the
+// FetchMemoryBudget by the connection's fetches (zero if there's no budget)
+func (c *Conn) FetchMemoryInUse() int64 {
+	if c.fetchBudget == nil {
+		return 0
+	}
+	c.fetchBudget.mux.Lock()
+	defer c.fetchBudget.mux.Unlock()
+	return c.fetchBudget.used
+}
+
+/*--- Private Routines ---*/
+
+type memBudget struct {
+	mux     sync.Mutex
+	size    int64
+	used    int64
+	changed chan struct{} // Closed (and replaced) whenever bytes are released
+}
+
+func newMemBudget(size int64) *memBudget {
+	return &memBudget{size: size, changed: make(chan struct{})}
+}
+
+// Reserves n bytes waiting until they're available. It gives up
+// (returning false) if either of the chans are closed first.
+func (b *memBudget) acquire(n int64, done, closed <-chan struct{}) bool {
+	for {
+		b.mux.Lock()
+		if b.used+n <= b.size {
+			b.used += n
+			b.mux.Unlock()
+			return true
+		}
+		changed := b.changed
+		b.mux.Unlock()
+
+		select {
+		case <-changed:
+		case <-done:
+			return false
+		case <-closed:
+			return false
+		}
+	}
+}
+
+// Reserves n bytes if they're available right now
+func (b *memBudget) tryAcquire(n int64) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	if b.used+n > b.size {
+		return false
+	}
+	b.used += n
+	return true
+}
+
+func (b *memBudget) release(n int64) {
+	if n == 0 {
+		return
+	}
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	b.used -= n
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
@@ -0,0 +1,111 @@
+package exasol
+
+import (
+	"context"
+	"time"
+)
+
+func (s *testSuite) TestMemBudget() {
+	b := newMemBudget(100)
+	s.True(b.tryAcquire(60))
+	s.False(b.tryAcquire(60))
+
+	acquired := make(chan bool)
+	go func() { acquired <- b.acquire(60, nil, nil) }()
+	select {
+	case <-acquired:
+		s.Fail("Acquired beyond the budget")
+	case <-time.After(50 * time.Millisecond):
+	}
+	b.release(60)
+	s.True(<-acquired, "Acquired once released")
+
+	done := make(chan struct{})
+	go func() { acquired <- b.acquire(60, done, nil) }()
+	close(done)
+	s.False(<-acquired, "Gave up once done")
+	b.release(60)
+	s.Zero(b.used)
+}
+
+func (s *testSuite) TestFetchMemoryBudget() {
+	conf := s.connConf()
+	conf.FetchMemoryBudget = 256 * 1024
+	exa, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer exa.Disconnect()
+	s.Zero(exa.FetchMemoryInUse())
+
+	// A slow consumer so that the fetching has to wait on it
+	ch, err := exa.FetchChan("SELECT LPAD('a', 100, 'a') FROM VALUES BETWEEN 1 AND 20000")
+	if !s.NoError(err) {
+		return
+	}
+	count := 0
+	var peak int64
+	for range ch {
+		count++
+		if count%1000 == 0 {
+			peak = max(peak, exa.FetchMemoryInUse())
+			time.Sleep(time.Millisecond)
+		}
+	}
+	s.Equal(20000, count)
+	s.Positive(peak)
+	s.LessOrEqual(peak, conf.FetchMemoryBudget)
+	s.Zero(exa.FetchMemoryInUse(), "All released")
+}
+
+// Returns a single row per fetch
+type rowWSHandler struct{ testWSHandler }
+
+func (wsh *rowWSHandler) ReadJSON(resp interface{}) error {
+	switch r := resp.(type) {
+	case *fetchRes:
+		r.Status = "ok"
+		r.ResponseData = &fetchData{NumRows: 1, Data: [][]interface{}{{"a"}}}
+	case *response:
+		r.Status = "ok"
+	}
+	return nil
+}
+
+func (s *testSuite) TestFetchBudgetSequentialDrain() {
+	c := &Conn{
+		ctx:         context.Background(),
+		wsh:         &rowWSHandler{},
+		log:         newDefaultLogger(),
+		fetchBudget: newMemBudget(128 * 1024),
+	}
+	newRS := func() *resultSet {
+		return &resultSet{ResultSetHandle: 1, NumRows: 5, Columns: []column{{Name: "A"}}}
+	}
+
+	// The first chan isn't drained until the second one has been
+	first := make(chan []interface{}, 1)
+	firstErr := make(chan error, 1)
+	go func() { firstErr <- c.resultsToChan(newRS(), first, FetchOpts{}, nil) }()
+	s.Eventually(func() bool { return len(first) == 1 }, time.Second, time.Millisecond)
+
+	second := make(chan []interface{}, 10)
+	secondErr := make(chan error, 1)
+	go func() { secondErr <- c.resultsToChan(newRS(), second, FetchOpts{}, nil) }()
+	select {
+	case err := <-secondErr:
+		s.NoError(err)
+	case <-time.After(time.Second):
+		s.Fail("Blocked on the budget held by the first")
+		return
+	}
+	s.Len(second, 5)
+
+	count := 0
+	for range first {
+		count++
+	}
+	s.Equal(5, count)
+	s.NoError(<-firstErr)
+	s.Zero(c.fetchBudget.used, "All released")
+}
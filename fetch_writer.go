@@ -0,0 +1,198 @@
+/*
+	Writing query results fetched over the websocket as CSV or JSON
+	for when the bulk EXPORT path isn't permitted or practical
+	(e.g. the server can't connect back to the client)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Results are buffered up to this size before being written
+const fetchWriteBufSize = 64 * 1024
+
+/*--- Public Interface ---*/
+
+// CSVOpts controls FetchToCSV's output
+type CSVOpts struct {
+	Header bool // Start with a row of the column names
+	// The zero value gives comma separated values with DATEs and
+	// TIMESTAMPs as Exasol returns them. Columns is set from the query.
+	Encoder CSVEncoder
+}
+
+// FetchToCSV runs the query (taking the same optional args as FetchChan)
+// and writes the results to w as CSV returning the number of rows written.
+// Numbers are written exactly as Exasol returns them (regardless of
+// ConnConf.TypedInts etc.), booleans as TRUE/FALSE and NULLs as empty values.
+func (c *Conn) FetchToCSV(sql string, w io.Writer, opts CSVOpts, args ...interface{}) (uint64, error) {
+	enc := opts.Encoder
+	var isDecimal []bool
+	return c.fetchTo(sql, w, args, func(buf *bytes.Buffer, cols []column) error {
+		enc.Columns = exportColumns(cols)
+		isDecimal = decimalColumns(cols)
+		if !opts.Header {
+			return nil
+		}
+		names := make([]interface{}, len(cols))
+		for i, col := range cols {
+			names[i] = col.Name
+		}
+		return enc.Encode(buf, [][]interface{}{names})
+	}, func(buf *bytes.Buffer, row []interface{}) error {
+		for i, val := range row {
+			row[i] = decimalNumber(val, isDecimal[i])
+		}
+		return enc.Encode(buf, [][]interface{}{row})
+	}, nil)
+}
+
+// FetchToJSON runs the query (taking the same optional args as FetchChan)
+// and writes the results to w as a JSON array of objects keyed by column
+// name (in column order) returning the number of rows written. Numbers are
+// written exactly as Exasol returns them and DATEs/TIMESTAMPs as strings.
+func (c *Conn) FetchToJSON(sql string, w io.Writer, args ...interface{}) (uint64, error) {
+	var keys [][]byte
+	var isDecimal []bool
+	var rows uint64
+	return c.fetchTo(sql, w, args, func(buf *bytes.Buffer, cols []column) error {
+		keys = make([][]byte, len(cols))
+		isDecimal = decimalColumns(cols)
+		for i, col := range cols {
+			key, err := marshalJSON(col.Name)
+			if err != nil {
+				return err
+			}
+			keys[i] = append(key, ':')
+		}
+		buf.WriteByte('[')
+		return nil
+	}, func(buf *bytes.Buffer, row []interface{}) error {
+		if rows > 0 {
+			buf.WriteByte(',')
+		}
+		rows++
+		buf.WriteString("\n{")
+		for i, val := range row {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			buf.Write(keys[i])
+			b, err := marshalJSON(decimalNumber(val, isDecimal[i]))
+			if err != nil {
+				return err
+			}
+			buf.Write(b)
+		}
+		buf.WriteByte('}')
+		return nil
+	}, func(buf *bytes.Buffer) {
+		buf.WriteString("\n]\n")
+	})
+}
+
+/*--- Private Routines ---*/
+
+// Fetches the query's results (with numbers left as json.Numbers) and
+// writes them to w via the start, row and end (if not nil) functions
+// which append to the buffer. Stops fetching if anything fails (including
+// the fetching itself) in which case the number of rows returned is just
+// those already written.
+func (c *Conn) fetchTo(
+	sql string, w io.Writer, args []interface{},
+	start func(*bytes.Buffer, []column) error,
+	row func(*bytes.Buffer, []interface{}) error,
+	end func(*bytes.Buffer),
+) (uint64, error) {
+	args = append([]interface{}(nil), args...)
+	for len(args) < 3 {
+		args = append(args, nil)
+	}
+	opts := c.Conf.FetchOpts
+	if o, ok := args[2].(FetchOpts); ok {
+		opts = o
+	} else if args[2] != nil {
+		return 0, c.error("Fetch's 4th param (fetch options) must be a FetchOpts")
+	}
+	opts.keepNumbers = true
+	args[2] = opts
+
+	done := make(chan struct{})
+	ch, rs, err := c.fetch(sql, args, done)
+	if err != nil {
+		return 0, err
+	}
+	defer stopFetch(done, ch)
+
+	var buf bytes.Buffer
+	if err = start(&buf, rs.Columns); err != nil {
+		return 0, err
+	}
+	var rows, written uint64
+	for r := range ch {
+		if err = row(&buf, r); err != nil {
+			return written, err
+		}
+		rows++
+		if buf.Len() >= fetchWriteBufSize {
+			if _, err = w.Write(buf.Bytes()); err != nil {
+				return written, err
+			}
+			written = rows
+			buf.Reset()
+		}
+	}
+	if rs.fetchErr != nil {
+		return written, rs.fetchErr
+	}
+	if end != nil {
+		end(&buf)
+	}
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		return written, err
+	}
+	return rows, nil
+}
+
+// Returns which of the columns are DECIMALs
+func decimalColumns(cols []column) []bool {
+	isDecimal := make([]bool, len(cols))
+	for i, col := range cols {
+		isDecimal[i] = col.DataType.Type == "DECIMAL"
+	}
+	return isDecimal
+}
+
+// Treats a DECIMAL value sent as a string (as high precision
+// ones can be) as the number it is
+func decimalNumber(val interface{}, isDecimal bool) interface{} {
+	if str, ok := val.(string); ok && isDecimal {
+		return json.Number(str)
+	}
+	return val
+}
+
+// Like json.Marshal but without escaping HTML characters
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
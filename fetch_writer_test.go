@@ -0,0 +1,81 @@
+package exasol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func (s *testSuite) TestFetchToCSV() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE ` + s.qschema + `.foo (
+		id DECIMAL(20,0), price DECIMAL(10,2), name VARCHAR(20), ok BOOLEAN, d DATE
+	)`)
+	s.execute(`INSERT INTO ` + s.qschema + `.foo VALUES
+		(12345678901234567890, 1.25, 'a,"b"', TRUE, '2020-01-02'),
+		(2, NULL, NULL, FALSE, NULL)`)
+	sql := "SELECT * FROM " + s.qschema + ".foo ORDER BY id"
+
+	var buf bytes.Buffer
+	n, err := exa.FetchToCSV(sql, &buf, CSVOpts{Header: true})
+	s.NoError(err)
+	s.Equal(uint64(2), n)
+	s.Equal("ID,PRICE,NAME,OK,D\n"+
+		"2,,,FALSE,\n"+
+		`12345678901234567890,1.25,"a,""b""",TRUE,2020-01-02`+"\n", buf.String())
+
+	buf.Reset()
+	opts := CSVOpts{Encoder: CSVEncoder{Separator: ';', DecimalMark: ','}}
+	_, err = exa.FetchToCSV(sql+" DESC LIMIT 1", &buf, opts)
+	s.NoError(err)
+	s.Equal(`12345678901234567890;1,25;"a,""b""";TRUE;2020-01-02`+"\n", buf.String())
+
+	buf.Reset()
+	n, err = exa.FetchToCSV("SELECT * FROM foo WHERE id = ?", &buf, CSVOpts{}, []interface{}{2}, s.schema)
+	s.NoError(err, "Takes FetchChan's args")
+	s.Equal(uint64(1), n)
+
+	_, err = exa.FetchToCSV(sql, failingWriter{}, CSVOpts{})
+	s.ErrorContains(err, "disk full")
+}
+
+func (s *testSuite) TestFetchToFetchError() {
+	c := &Conn{
+		Conf: ConnConf{SuppressError: true},
+		wsh:  &fetchErrWSHandler{},
+		log:  newDefaultLogger(),
+	}
+	var buf bytes.Buffer
+	n, err := c.FetchToCSV("SELECT a FROM foo", &buf, CSVOpts{})
+	s.EqualError(err, "Unable to Fetch: Server Error: fetch failed")
+	s.Zero(n)
+	_, err = c.FetchToJSON("SELECT a FROM foo", &buf)
+	s.ErrorAs(err, new(*ServerError))
+	s.Empty(buf.String(), "Nothing is written")
+}
+
+func (s *testSuite) TestFetchToJSON() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE " + s.qschema + ".foo ( id DECIMAL(20,0), name VARCHAR(20), ok BOOLEAN )")
+	s.execute("INSERT INTO " + s.qschema + ".foo VALUES (12345678901234567890, '<a>', TRUE), (2, NULL, FALSE)")
+
+	var buf bytes.Buffer
+	n, err := exa.FetchToJSON("SELECT * FROM "+s.qschema+".foo ORDER BY id", &buf)
+	s.NoError(err)
+	s.Equal(uint64(2), n)
+	s.Equal("[\n"+
+		`{"ID":2,"NAME":null,"OK":false},`+"\n"+
+		`{"ID":12345678901234567890,"NAME":"<a>","OK":true}`+"\n]\n", buf.String())
+	var decoded []map[string]interface{}
+	s.NoError(json.Unmarshal(buf.Bytes(), &decoded))
+
+	buf.Reset()
+	n, err = exa.FetchToJSON("SELECT * FROM "+s.qschema+".foo WHERE FALSE", &buf)
+	s.NoError(err)
+	s.Zero(n)
+	s.Equal("[\n]\n", buf.String())
+}
@@ -0,0 +1,152 @@
+/*
+	Building safely quoted identifiers and placeholders for dynamic SQL
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"regexp"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// Identifier is a (possibly qualified) identifier built via Ident e.g.
+//
+//	Ident("sales").Table("orders").Column("id") // "SALES"."ORDERS"."ID"
+//
+// Unlike QuoteIdent every part is always quoted (so keywords are safe and
+// nothing is passed through as already quoted) and no connection is
+// needed. Regular identifiers are upper-cased as Exasol does for unquoted
+// ones whereas any other name is quoted exactly as given (as is any part
+// marked Exact e.g. a lower-case name created as quoted).
+type Identifier struct {
+	parts []identPart
+}
+
+// Ident starts an identifier with the name e.g. of a schema
+func Ident(name string) Identifier {
+	return Identifier{parts: []identPart{{name: name}}}
+}
+
+// Table qualifies the table with the identifier (its schema). Table and
+// Column are the same, appending a part, and are only named differently
+// so that the chain reads like what it's building.
+func (i Identifier) Table(name string) Identifier {
+	return i.with(name)
+}
+
+// Column qualifies the column with the identifier (its table)
+func (i Identifier) Column(name string) Identifier {
+	return i.with(name)
+}
+
+// Exact marks the last part as being quoted exactly as given
+// e.g. Ident("sales").Exact().Table("orders") is "sales"."ORDERS"
+func (i Identifier) Exact() Identifier {
+	if len(i.parts) == 0 {
+		return i
+	}
+	parts := append([]identPart{}, i.parts...)
+	parts[len(parts)-1].exact = true
+	return Identifier{parts: parts}
+}
+
+// String returns the quoted identifier for use in SQL
+func (i Identifier) String() string {
+	quoted := make([]string, len(i.parts))
+	for j, part := range i.parts {
+		name := part.name
+		if !part.exact {
+			name = regularName(name)
+		}
+		quoted[j] = quoteExact(name)
+	}
+	return strings.Join(quoted, ".")
+}
+
+// IdentList returns the names quoted as per Ident and comma-separated
+// e.g. for an INSERT's column list
+func IdentList(names ...string) string {
+	return quoteList(names, func(name string) string { return Ident(name).String() })
+}
+
+// Placeholders returns n comma-separated bind placeholders e.g. "?, ?, ?"
+func Placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
+
+/*--- Private Routines ---*/
+
+// As per Exasol's regular identifiers, which may contain Unicode letters
+// etc. (and are upper-cased as such) rather than just ASCII ones
+var regularIdentRE = regexp.MustCompile(
+	`^[\p{L}\p{Nl}][\p{L}\p{Nl}\p{Mn}\p{Mc}\p{Nd}\p{Pc}\p{Cf}]*$`,
+)
+
+type identPart struct {
+	name  string
+	exact bool
+}
+
+// Returns the name upper-cased if it's a regular identifier
+// since that's how Exasol stores unquoted ones
+func regularName(name string) string {
+	if regularIdentRE.MatchString(name) {
+		return strings.ToUpper(name)
+	}
+	return name
+}
+
+// Quotes the identifier as is (preserving its case)
+func quoteExact(ident string) string {
+	return `"` + strings.ReplaceAll(ident, `"`, `""`) + `"`
+}
+
+// Quotes each name with quote and comma-separates them
+func quoteList(names []string, quote func(string) string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// Returns the identifier as it's stored in the system tables i.e. as
+// QuoteIdent would quote it minus the quotes (and upper-cased if need be)
+func (c *Conn) storedName(ident string) string {
+	return storedPart(c.QuoteIdent(ident))
+}
+
+// Returns a single, possibly quoted, part of a name as it's stored i.e.
+// upper-cased unless double quoted. [Bracketed] parts are upper-cased
+// too as the brackets just allow keywords etc.
+func storedPart(p string) string {
+	switch {
+	case len(p) > 1 && p[0] == '"' && p[len(p)-1] == '"':
+		return strings.ReplaceAll(p[1:len(p)-1], `""`, `"`)
+	case len(p) > 1 && p[0] == '[' && p[len(p)-1] == ']':
+		return strings.ToUpper(p[1 : len(p)-1])
+	}
+	return strings.ToUpper(p)
+}
+
+// Copies the parts so that builders sharing a prefix don't clobber each other
+func (i Identifier) with(name string) Identifier {
+	parts := make([]identPart, len(i.parts), len(i.parts)+1)
+	copy(parts, i.parts)
+	return Identifier{parts: append(parts, identPart{name: name})}
+}
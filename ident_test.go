@@ -0,0 +1,54 @@
+package exasol
+
+import "fmt"
+
+func (s *testSuite) TestIdent() {
+	s.Equal(`"SALES"`, Ident("sales").String())
+	s.Equal(`"SALES"."ORDERS"."ORDER_ID"`, Ident("sales").Table("Orders").Column("order_id").String())
+	s.Equal(`"TABLE"`, Ident("table").String())
+	s.Equal(`"my table"`, Ident("my table").String())
+	s.Equal(`"1st"`, Ident("1st").String())
+	s.Equal(`"x""; DROP TABLE foo; --"`, Ident(`x"; DROP TABLE foo; --`).String())
+	s.Equal(`"[x]"`, Ident("[x]").String())
+	s.Equal(`"""ALREADY"""`, Ident(`"ALREADY"`).String())
+	s.Equal(`"S"."T"`, fmt.Sprintf("%s", Ident("s").Table("t")))
+	s.Equal(`"sales"."ORDERS"`, Ident("sales").Exact().Table("orders").String())
+	s.Equal(`"SALES"."orders"`, Ident("sales").Table("orders").Exact().String())
+	s.Equal(`"CAFÉ"."ÖLPREIS"`, Ident("café").Table("Ölpreis").String(), "Unicode regular identifiers")
+	s.Equal(`"a-é"`, Ident("a-é").String())
+
+	// Sharing a prefix
+	t := Ident("s").Table("t")
+	a, b := t.Column("a"), t.Column("b")
+	s.Equal(`"S"."T"."A"`, a.String())
+	s.Equal(`"S"."T"."B"`, b.String())
+
+	s.Equal(`"ID", "first name"`, IdentList("id", "first name"))
+	s.Equal("", IdentList())
+
+	s.Equal(`"a", "B""c"`, quoteList([]string{"a", `B"c`}, quoteExact))
+	s.Equal(`my "x"`, storedPart(`"my ""x"""`))
+	s.Equal("MY X", storedPart("[my x]"))
+	s.Equal("FOO", storedPart("foo"))
+
+	s.Equal("?, ?, ?", Placeholders(3))
+	s.Equal("?", Placeholders(1))
+	s.Equal("", Placeholders(0))
+}
+
+func (s *testSuite) TestIdentSQL() {
+	s.execute(`CREATE TABLE "odd table" (id INT, "SELECT" VARCHAR(10))`)
+	table := Ident(s.schema).Exact().Table("odd table")
+	sql := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)",
+		table, IdentList("id", "select"), Placeholders(2),
+	)
+	_, err := s.exaConn.Execute(sql, []interface{}{1, "a"})
+	s.NoError(err)
+	got, err := s.exaConn.FetchOne(fmt.Sprintf(
+		"SELECT %s FROM %s",
+		table.Column("select"), table,
+	))
+	s.NoError(err)
+	s.Equal("a", got)
+}
@@ -0,0 +1,101 @@
+/*
+	Retrieval of the values generated for IDENTITY columns
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// InsertReturningIDs inserts the rows (the values for the columns, not
+// including the identity column) and returns the ids the identity column
+// got for each of them. Exasol can't report generated values so the ids are
+// reserved up front (by bumping the column's identity past them) and
+// inserted explicitly, all within a single Transaction. Should another
+// session insert into the table at the same time one of the transactions
+// fails with a transaction conflict and can be retried.
+func (c *Conn) InsertReturningIDs(
+	schema, table, idColumn string, columns []string, rows [][]interface{},
+) ([]int64, error) {
+	if schema == "" || table == "" || idColumn == "" {
+		return nil, fmt.Errorf("You must pass in a schema, table and id column to InsertReturningIDs")
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	target := c.QuoteIdent(schema) + "." + c.QuoteIdent(table)
+	cols := make([]string, len(columns)+1)
+	cols[0] = c.QuoteIdent(idColumn)
+	for i, col := range columns {
+		cols[i+1] = c.QuoteIdent(col)
+	}
+	insert := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s)", target, strings.Join(cols, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "),
+	)
+
+	var ids []int64
+	err := c.Transaction(func() error {
+		next, err := c.nextIdentity(schema, table, idColumn)
+		if err != nil {
+			return err
+		}
+		_, err = c.Execute(fmt.Sprintf(
+			"ALTER TABLE %s ALTER COLUMN %s SET IDENTITY %d",
+			target, cols[0], next+int64(len(rows)),
+		))
+		if err != nil {
+			return err
+		}
+		ids = make([]int64, len(rows))
+		binds := make([][]interface{}, len(rows))
+		for i, row := range rows {
+			if len(row) != len(columns) {
+				return fmt.Errorf("Row %d has %d values but there are %d columns", i, len(row), len(columns))
+			}
+			ids[i] = next + int64(i)
+			binds[i] = append([]interface{}{ids[i]}, row...)
+		}
+		_, err = c.Execute(insert, binds)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+/*--- Private Routines ---*/
+
+// Returns the value the identity column will give the next row
+func (c *Conn) nextIdentity(schema, table, idColumn string) (int64, error) {
+	s, t, col := c.storedName(schema), c.storedName(table), c.storedName(idColumn)
+	// As a string since identities can exceed a float64's precision
+	next, err := c.FetchOne(`
+		SELECT CAST(column_identity AS VARCHAR(40)) FROM exa_all_columns
+		WHERE column_schema = ? AND column_table = ? AND column_name = ?
+	`, []interface{}{s, t, col})
+	if err != nil {
+		return 0, fmt.Errorf("Unable to find column %s.%s.%s: %w", schema, table, idColumn, err)
+	}
+	str, ok := next.(string)
+	if !ok {
+		return 0, fmt.Errorf("%s.%s.%s is not an IDENTITY column", schema, table, idColumn)
+	}
+	return strconv.ParseInt(str, 10, 64)
+}
@@ -0,0 +1,33 @@
+package exasol
+
+func (s *testSuite) TestInsertReturningIDs() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE foo ( id DECIMAL(18,0) IDENTITY 100, val VARCHAR(10) )")
+	s.execute("INSERT INTO foo (val) VALUES ('a')")
+
+	ids, err := exa.InsertReturningIDs(s.qschema, "foo", "id", []string{"val"}, [][]interface{}{
+		{"b"}, {"c"},
+	})
+	s.NoError(err)
+	s.Equal([]int64{101, 102}, ids)
+
+	s.execute("INSERT INTO foo (val) VALUES ('d')")
+	s.Equal([][]interface{}{
+		{float64(100), "a"},
+		{float64(101), "b"},
+		{float64(102), "c"},
+		{float64(103), "d"},
+	}, s.fetch("SELECT * FROM foo ORDER BY id"), "The identity carries on after them")
+	attr, _ := exa.GetSessionAttr()
+	s.True(attr.Autocommit)
+
+	exa.Conf.SuppressError = true
+	s.execute("CREATE TABLE bar ( id INT, val VARCHAR(10) )")
+	_, err = exa.InsertReturningIDs(s.qschema, "bar", "id", []string{"val"}, [][]interface{}{{"x"}})
+	s.ErrorContains(err, "is not an IDENTITY column")
+	_, err = exa.InsertReturningIDs(s.qschema, "foo", "id", []string{"val"}, [][]interface{}{{"x", "y"}})
+	s.ErrorContains(err, "Row 0 has 2 values but there are 1 columns")
+	ids, err = exa.InsertReturningIDs(s.qschema, "foo", "id", []string{"val"}, nil)
+	s.NoError(err)
+	s.Empty(ids)
+}
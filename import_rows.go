@@ -0,0 +1,230 @@
+/*
+	Streaming rows of Go values into a table via the bulk IMPORT path
+	along with the column mapping and type checks that loaders of other
+	formats (e.g. Parquet or Arrow files) need
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bytes"
+	"fmt"
+	"iter"
+	"strings"
+)
+
+// The size of the CSV chunks StreamInsertRows sends
+const importChunkSize = 1024 * 1024
+
+/*--- Public Interface ---*/
+
+// ImportKind is the broad type of a column being imported
+type ImportKind int
+
+const (
+	ImportString ImportKind = iota
+	ImportNumber
+	ImportBool
+	ImportDate
+	ImportTimestamp
+)
+
+func (k ImportKind) String() string {
+	switch k {
+	case ImportString:
+		return "string"
+	case ImportNumber:
+		return "number"
+	case ImportBool:
+		return "bool"
+	case ImportDate:
+		return "date"
+	case ImportTimestamp:
+		return "timestamp"
+	}
+	return "unknown"
+}
+
+// ImportColumn describes a column of the data being imported
+type ImportColumn struct {
+	Name string
+	Kind ImportKind
+	// The table column to load it into. Defaults to Name.
+	Column string
+}
+
+// MapImportColumns matches the columns being imported to the table's
+// columns (exactly or else case-insensitively) and checks that their
+// types are compatible e.g. that a date isn't being loaded into a DECIMAL.
+// Strings can be loaded into anything (leaving Exasol to parse them) and
+// anything into CHARs/VARCHARs. It returns the table's matching columns
+// in the same order, which can be passed to StreamInsertRows.
+func (c *Conn) MapImportColumns(schema, table string, cols []ImportColumn) ([]Column, error) {
+	tableCols, err := c.DescribeQuery(fmt.Sprintf(
+		"SELECT * FROM %s.%s", c.QuoteIdent(schema), c.QuoteIdent(table),
+	))
+	if err != nil {
+		return nil, err
+	}
+	mapped := make([]Column, len(cols))
+	used := map[int]string{}
+	var problems []string
+	for i, col := range cols {
+		name := col.Column
+		if name == "" {
+			name = col.Name
+		}
+		idx := matchColumn(tableCols, name)
+		if idx < 0 {
+			problems = append(problems, fmt.Sprintf("%s has no column %s", table, name))
+			continue
+		} else if prev, dup := used[idx]; dup {
+			problems = append(problems, fmt.Sprintf(
+				"%s and %s both map to %s", prev, col.Name, tableCols[idx].Name,
+			))
+			continue
+		}
+		used[idx] = col.Name
+		mapped[i] = tableCols[idx]
+		if dt := tableCols[idx].DataType; !importCompatible(dt, col.Kind) {
+			problems = append(problems, fmt.Sprintf(
+				"%s (%s) can't be loaded into %s (%s)", col.Name, col.Kind, tableCols[idx].Name, dt.Type,
+			))
+		}
+	}
+	if problems != nil {
+		return nil, fmt.Errorf("Unable to map columns: %s", strings.Join(problems, "; "))
+	}
+	return mapped, nil
+}
+
+// StreamInsertRows imports the rows (each holding the values for the
+// columns in order) into the table via the bulk IMPORT path without
+// buffering them all up first. The rows are encoded as CSV matching the
+// session (see CSVEncoder) so values can be anything it supports e.g.
+// numbers, strings, bools and time.Times. If the rows fail part way the
+// import is rolled back. Columns not given are left to their defaults.
+func (c *Conn) StreamInsertRows(
+	schema, table string, columns []Column, rows iter.Seq2[[]interface{}, error],
+) (*BulkResult, error) {
+	if schema == "" || table == "" || len(columns) == 0 {
+		return nil, fmt.Errorf("You must pass in a schema, table and columns to StreamInsertRows")
+	}
+	enc, err := c.NewCSVEncoder()
+	if err != nil {
+		return nil, err
+	}
+	enc.Columns = columns
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = quoteExact(col.Name)
+	}
+	sql := fmt.Sprintf(
+		"IMPORT INTO %s.%s (%s) FROM CSV AT '%%s' FILE 'data.csv'",
+		c.QuoteIdent(schema), c.QuoteIdent(table), strings.Join(names, ", "),
+	)
+
+	var res *BulkResult
+	err = c.Transaction(func() error {
+		data := make(chan []byte, 1)
+		done := make(chan struct{})
+		encErr := make(chan error, 1)
+		go func() {
+			defer close(data)
+			encErr <- encodeRows(enc, len(columns), rows, data, done)
+		}()
+		var err error
+		res, err = c.StreamExecuteResult(sql, data)
+		close(done)
+		for range data {
+		}
+		if e := <-encErr; e != nil {
+			return e
+		}
+		return err
+	})
+	return res, err
+}
+
+/*--- Private Routines ---*/
+
+// Whether data of the kind can be loaded into the type of column
+func importCompatible(dt DataType, kind ImportKind) bool {
+	if kind == ImportString {
+		return true
+	}
+	switch dt.Type {
+	case "CHAR", "VARCHAR":
+		return true
+	case "DECIMAL", "DOUBLE":
+		return kind == ImportNumber
+	case "BOOLEAN":
+		return kind == ImportBool
+	case "DATE", "TIMESTAMP", "TIMESTAMP WITH LOCAL TIME ZONE":
+		return kind == ImportDate || kind == ImportTimestamp
+	}
+	return false
+}
+
+// Returns the index of the named column (matched exactly
+// or else case-insensitively) or -1 if there is none
+func matchColumn(cols []Column, name string) int {
+	for i, col := range cols {
+		if col.Name == name {
+			return i
+		}
+	}
+	for i, col := range cols {
+		if strings.EqualFold(col.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Encodes the rows into CSV chunks sent on data until done is closed
+func encodeRows(
+	enc *CSVEncoder, numCols int, rows iter.Seq2[[]interface{}, error],
+	data chan<- []byte, done <-chan struct{},
+) error {
+	send := func(buf *bytes.Buffer) bool {
+		select {
+		case data <- bytes.Clone(buf.Bytes()):
+			buf.Reset()
+			return true
+		case <-done:
+			return false
+		}
+	}
+	var buf bytes.Buffer
+	n := 0
+	for row, err := range rows {
+		if err != nil {
+			return err
+		}
+		n++
+		if len(row) != numCols {
+			return fmt.Errorf("Row %d has %d values but there are %d columns", n, len(row), numCols)
+		}
+		if err = enc.Encode(&buf, [][]interface{}{row}); err != nil {
+			return err
+		}
+		if buf.Len() >= importChunkSize && !send(&buf) {
+			return nil
+		}
+	}
+	if buf.Len() > 0 {
+		send(&buf)
+	}
+	return nil
+}
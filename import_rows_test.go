@@ -0,0 +1,97 @@
+package exasol
+
+import (
+	"errors"
+	"iter"
+	"time"
+)
+
+func (s *testSuite) TestMapImportColumns() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE ` + s.qschema + `.foo (
+		id INT, name VARCHAR(20), "lower" BOOLEAN, d DATE, ts TIMESTAMP, g GEOMETRY
+	)`)
+
+	cols, err := exa.MapImportColumns(s.schema, "foo", []ImportColumn{
+		{Name: "ID", Kind: ImportNumber},
+		{Name: "label", Kind: ImportDate, Column: "name"},
+		{Name: "lower", Kind: ImportBool},
+		{Name: "D", Kind: ImportTimestamp},
+		{Name: "ts", Kind: ImportString},
+		{Name: "g", Kind: ImportString},
+	})
+	s.NoError(err)
+	names := []string{}
+	for _, col := range cols {
+		names = append(names, col.Name)
+	}
+	s.Equal([]string{"ID", "NAME", "lower", "D", "TS", "G"}, names)
+
+	_, err = exa.MapImportColumns(s.schema, "foo", []ImportColumn{
+		{Name: "id", Kind: ImportDate},
+		{Name: "nope"},
+		{Name: "x", Column: "ID", Kind: ImportNumber},
+		{Name: "g", Kind: ImportNumber},
+	})
+	s.ErrorContains(err, "id (date) can't be loaded into ID (DECIMAL)")
+	s.ErrorContains(err, "foo has no column nope")
+	s.ErrorContains(err, "id and x both map to ID")
+	s.ErrorContains(err, "g (number) can't be loaded into G (GEOMETRY)")
+
+	_, err = exa.MapImportColumns(s.schema, "bar", nil)
+	s.Error(err)
+
+	s.True(importCompatible(DataType{Type: "DOUBLE"}, ImportNumber))
+	s.False(importCompatible(DataType{Type: "BOOLEAN"}, ImportNumber))
+	s.True(importCompatible(DataType{Type: "TIMESTAMP WITH LOCAL TIME ZONE"}, ImportTimestamp))
+	s.Equal(-1, matchColumn(cols, "nope"))
+}
+
+func (s *testSuite) TestStreamInsertRows() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE ` + s.qschema + `.foo (
+		id INT, name VARCHAR(20), ok BOOLEAN, ts TIMESTAMP, n INT DEFAULT 7
+	)`)
+	cols, err := exa.MapImportColumns(s.schema, "foo", []ImportColumn{
+		{Name: "id", Kind: ImportNumber},
+		{Name: "name", Kind: ImportString},
+		{Name: "ok", Kind: ImportBool},
+		{Name: "ts", Kind: ImportTimestamp},
+	})
+	s.NoError(err)
+
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := func(n int, fail error) iter.Seq2[[]interface{}, error] {
+		return func(yield func([]interface{}, error) bool) {
+			for i := 1; i <= n; i++ {
+				if !yield([]interface{}{i, `a,"b"`, i%2 == 0, ts}, nil) {
+					return
+				}
+			}
+			if fail != nil {
+				yield(nil, fail)
+			}
+		}
+	}
+
+	// Enough rows to span several chunks
+	total := 100000
+	res, err := exa.StreamInsertRows(s.schema, "foo", cols, rows(total, nil))
+	s.NoError(err)
+	s.Greater(res.Chunks, int64(1))
+	s.Equal([][]interface{}{
+		{float64(2), `a,"b"`, true, "2020-01-02 03:04:05", float64(7)},
+	}, s.fetch(`SELECT id, name, ok, TO_CHAR(ts, 'YYYY-MM-DD HH24:MI:SS'), n
+	            FROM `+s.qschema+`.foo WHERE id = 2`))
+
+	_, err = exa.StreamInsertRows(s.schema, "foo", cols, rows(10, errors.New("bad file")))
+	s.EqualError(err, "bad file")
+	_, err = exa.StreamInsertRows(s.schema, "foo", cols, func(yield func([]interface{}, error) bool) {
+		yield([]interface{}{1}, nil)
+	})
+	s.ErrorContains(err, "Row 1 has 1 values but there are 4 columns")
+	s.Equal([][]interface{}{{float64(total)}}, s.fetch("SELECT COUNT(*) FROM "+s.qschema+".foo"))
+
+	_, err = exa.StreamInsertRows(s.schema, "foo", nil, rows(1, nil))
+	s.Error(err)
+}
@@ -0,0 +1,136 @@
+/*
+	Incremental exports for pipelines that repeatedly pull
+	just the rows added to a table since their last run
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// ExportIncremental exports the rows of the table whose keyColumn is
+// greater than lastSeen (or all of them if lastSeen is nil) sorted by the
+// key. It also returns the new high-water mark to pass in as lastSeen next
+// time, which should only be checkpointed once the Rows have been read
+// without error. The key must only ever increase (e.g. an IDENTITY column
+// or a load timestamp).
+//
+// The mark is the key's maximum when the export starts and rows beyond it
+// aren't exported, so rows added during the export are picked up by the
+// next one rather than being missed or exported twice. Numeric marks are
+// returned as json.Numbers (so large keys don't lose precision) and DATE
+// or TIMESTAMP marks as strings, though lastSeen may also be any Go
+// number or a time.Time. If the export fails, or there are no new rows,
+// the mark returned is lastSeen. As with StreamSelectFrom, an interrupted
+// export can be resumed via Rows.Resume.
+//
+// Note that the key must also increase in commit order. A row whose key
+// was assigned before the mark was read (e.g. a load timestamp set at the
+// start of a long transaction) but that is only committed afterwards is
+// below the mark so it's never exported. Where that can happen pass in a
+// lastSeen that lags behind the mark by longer than such transactions run
+// and de-duplicate the overlap downstream.
+func (c *Conn) ExportIncremental(
+	schema, table, keyColumn string, lastSeen interface{},
+) (*Rows, interface{}) {
+	if schema == "" || table == "" || keyColumn == "" {
+		return c.failedRows(fmt.Errorf(
+			"You must pass in a schema, table and key column to ExportIncremental",
+		)), lastSeen
+	}
+	from := fmt.Sprintf("%s.%s", c.QuoteIdent(schema), c.QuoteIdent(table))
+	key := c.QuoteIdent(keyColumn)
+
+	opts := c.Conf.FetchOpts
+	opts.keepNumbers = true
+	rows, err := c.FetchRows(fmt.Sprintf("SELECT MAX(%s) FROM %s", key, from), nil, nil, opts)
+	if err != nil {
+		return c.failedRows(err), lastSeen
+	}
+	var mark interface{}
+	if rows.Next() {
+		err = rows.Scan(&mark)
+	}
+	dt := rows.ColumnTypes()[0].DataType
+	rows.Close()
+	if err != nil {
+		return c.failedRows(err), lastSeen
+	} else if mark == nil {
+		return c.failedRows(nil), lastSeen // The table is empty
+	}
+
+	upper, err := sqlLiteral(mark, dt)
+	if err != nil {
+		return c.failedRows(err), lastSeen
+	}
+	where := fmt.Sprintf("%s <= %s", key, upper)
+	if lastSeen != nil {
+		lower, err := sqlLiteral(lastSeen, dt)
+		if err != nil {
+			return c.failedRows(fmt.Errorf("Invalid lastSeen for ExportIncremental: %s", err)), lastSeen
+		} else if lower == upper {
+			return c.failedRows(nil), lastSeen // Nothing new
+		}
+		where = fmt.Sprintf("%s > %s AND %s", key, lower, where)
+	}
+	selectSQL := fmt.Sprintf("SELECT * FROM %s WHERE %s", from, where)
+	return c.StreamQueryFrom(selectSQL, key, 0), mark
+}
+
+/*--- Private Routines ---*/
+
+// Returns the value as a SQL literal of the given type
+func sqlLiteral(val interface{}, dt DataType) (string, error) {
+	layout := "2006-01-02 15:04:05.000000"
+	prefix := "TIMESTAMP "
+	switch dt.Type {
+	case "DATE":
+		layout, prefix = "2006-01-02", "DATE "
+	case "TIMESTAMP", "TIMESTAMP WITH LOCAL TIME ZONE":
+	default:
+		prefix = ""
+	}
+
+	switch v := val.(type) {
+	case json.Number:
+		if _, err := v.Float64(); err != nil {
+			return "", fmt.Errorf("Invalid number %q", v)
+		}
+		return v.String(), nil
+	case string:
+		return prefix + "'" + QuoteStr(v) + "'", nil
+	case time.Time:
+		if prefix == "" {
+			return "", fmt.Errorf("A time can't be compared to a %s", dt.Type)
+		}
+		return prefix + "'" + v.Format(layout) + "'", nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'f', -1, rv.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("Unsupported type %T", val)
+}
@@ -0,0 +1,74 @@
+package exasol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func (s *testSuite) TestExportIncremental() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id DECIMAL(20,0), ts TIMESTAMP, val VARCHAR(10) )`)
+	readAll := func(rows *Rows) string {
+		var csv string
+		for d := range rows.Data {
+			csv += string(d)
+		}
+		s.NoError(rows.Error)
+		return csv
+	}
+
+	rows, mark := exa.ExportIncremental(s.qschema, "foo", "id", nil)
+	s.Equal("", readAll(rows), "Empty table")
+	s.Nil(mark)
+
+	s.execute(`INSERT INTO foo VALUES
+		(12345678901234567891, '2020-01-02 03:04:05.123', 'b'),
+		(12345678901234567890, '2020-01-01 00:00:00', 'a')`)
+	rows, mark = exa.ExportIncremental(s.qschema, "foo", "id", nil)
+	s.Equal("12345678901234567890,2020-01-01 00:00:00.000000,a\n"+
+		"12345678901234567891,2020-01-02 03:04:05.123000,b\n", readAll(rows), "Sorted by key")
+	s.Equal(json.Number("12345678901234567891"), mark, "Without losing precision")
+
+	rows, mark = exa.ExportIncremental(s.qschema, "foo", "id", mark)
+	s.Equal("", readAll(rows), "Nothing new")
+	s.Equal(json.Number("12345678901234567891"), mark)
+
+	s.execute(`INSERT INTO foo VALUES (12345678901234567892, '2020-01-03 00:00:00', 'c')`)
+	rows, mark = exa.ExportIncremental(s.qschema, "foo", "id", mark)
+	s.Equal("12345678901234567892,2020-01-03 00:00:00.000000,c\n", readAll(rows))
+	s.Equal(json.Number("12345678901234567892"), mark)
+
+	// Timestamp keys
+	rows, mark = exa.ExportIncremental(s.qschema, "foo", "ts", time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC))
+	s.Equal("12345678901234567891,2020-01-02 03:04:05.123000,b\n"+
+		"12345678901234567892,2020-01-03 00:00:00.000000,c\n", readAll(rows))
+	rows, _ = exa.ExportIncremental(s.qschema, "foo", "ts", mark)
+	s.Equal("", readAll(rows))
+
+	exa.Conf.SuppressError = true
+	rows, mark = exa.ExportIncremental(s.qschema, "foo", "ts", []int{1})
+	s.ErrorContains(rows.Error, "Invalid lastSeen")
+	s.Equal([]int{1}, mark)
+	rows, _ = exa.ExportIncremental(s.qschema, "foo", "nope", nil)
+	s.Error(rows.Error)
+	rows, _ = exa.ExportIncremental(s.qschema, "foo", "", nil)
+	s.ErrorContains(rows.Error, "You must pass in")
+
+	for want, val := range map[string]interface{}{
+		"5": 5, "-1.5": -1.5, "7": uint8(7), "12.50": json.Number("12.50"), "'it''s'": "it's",
+	} {
+		lit, err := sqlLiteral(val, DataType{Type: "DECIMAL"})
+		s.NoError(err)
+		s.Equal(want, lit)
+	}
+	lit, err := sqlLiteral(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC), DataType{Type: "DATE"})
+	s.NoError(err)
+	s.Equal("DATE '2020-01-02'", lit)
+	lit, err = sqlLiteral("2020-01-02 03:04:05.000000", DataType{Type: "TIMESTAMP"})
+	s.NoError(err)
+	s.Equal("TIMESTAMP '2020-01-02 03:04:05.000000'", lit)
+	_, err = sqlLiteral(time.Now(), DataType{Type: "DECIMAL"})
+	s.Error(err)
+	_, err = sqlLiteral(json.Number("1; DROP"), DataType{Type: "DECIMAL"})
+	s.Error(err)
+}
@@ -0,0 +1,134 @@
+/*
+	Inserting rows given as maps of column names to values
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The number of rows sent per executePreparedStatement by InsertRows
+const insertRowsBatchSize = 1000
+
+/*--- Public Interface ---*/
+
+// InsertRows inserts the rows, each a map of column names to values, into
+// the table returning the number of rows inserted. Column names are matched
+// to the table's columns exactly or else case-insensitively. Columns that
+// none of the rows have are left to their defaults while those missing from
+// only some of the rows are NULL in them. The rows are inserted in batches
+// within a single Transaction so either all of them are inserted or none are.
+func (c *Conn) InsertRows(schema, table string, rows []map[string]interface{}) (int64, error) {
+	if schema == "" || table == "" {
+		return 0, fmt.Errorf("You must pass in a schema and table to InsertRows")
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	tableCols, err := c.tableColumns(schema, table)
+	if err != nil {
+		return 0, err
+	}
+	byName := map[string]int{}
+	for i, col := range tableCols {
+		byName[col] = i
+		if _, exists := byName[strings.ToUpper(col)]; !exists {
+			byName[strings.ToUpper(col)] = i
+		}
+	}
+
+	// Map each row's keys to table columns keeping just the ones used
+	used := make([]bool, len(tableCols))
+	keyCols := map[string]int{}
+	for i, row := range rows {
+		for key := range row {
+			if _, ok := keyCols[key]; ok {
+				continue
+			}
+			idx, ok := byName[key]
+			if !ok {
+				idx, ok = byName[strings.ToUpper(key)]
+			}
+			if !ok {
+				return 0, fmt.Errorf("Row %d has unknown column %q for %s.%s", i+1, key, schema, table)
+			}
+			keyCols[key] = idx
+			used[idx] = true
+		}
+	}
+	var cols []string
+	pos := make([]int, len(tableCols)) // Table column index => bind index
+	for i, u := range used {
+		if u {
+			pos[i] = len(cols)
+			cols = append(cols, quoteExact(tableCols[i]))
+		}
+	}
+	if len(cols) == 0 {
+		return 0, fmt.Errorf("The rows for %s.%s have no columns", schema, table)
+	}
+	insert := fmt.Sprintf(
+		"INSERT INTO %s.%s (%s) VALUES (%s)",
+		c.QuoteIdent(schema), c.QuoteIdent(table), strings.Join(cols, ", "),
+		strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", "),
+	)
+
+	binds := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		binds[i] = make([]interface{}, len(cols))
+		for key, val := range row {
+			binds[i][pos[keyCols[key]]] = val
+		}
+	}
+
+	var inserted int64
+	err = c.Transaction(func() error {
+		for start := 0; start < len(binds); start += insertRowsBatchSize {
+			end := min(start+insertRowsBatchSize, len(binds))
+			n, err := c.Execute(insert, binds[start:end])
+			if err != nil {
+				return err
+			}
+			inserted += n
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
+
+/*--- Private Routines ---*/
+
+// Returns the table's column names (as stored) in order
+func (c *Conn) tableColumns(schema, table string) ([]string, error) {
+	s, t := c.storedName(schema), c.storedName(table)
+	res, err := c.FetchSlice(`
+		SELECT column_name FROM exa_all_columns
+		WHERE column_schema = ? AND column_table = ?
+		ORDER BY column_ordinal_position
+	`, []interface{}{s, t})
+	if err != nil {
+		return nil, fmt.Errorf("Unable to find the columns of %s.%s: %w", schema, table, err)
+	} else if len(res) == 0 {
+		return nil, fmt.Errorf("Unable to find the columns of %s.%s", schema, table)
+	}
+	cols := make([]string, len(res))
+	for i, row := range res {
+		cols[i] = row[0].(string)
+	}
+	return cols, nil
+}
@@ -0,0 +1,37 @@
+package exasol
+
+func (s *testSuite) TestInsertRows() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE ` + s.qschema + `.foo (
+		id INT, name VARCHAR(20), "lower" VARCHAR(10), n INT DEFAULT 7
+	)`)
+
+	rows := []map[string]interface{}{
+		{"id": 1, "NAME": "a", "lower": "x"},
+		{"Id": 2},
+	}
+	for i := 3; i <= insertRowsBatchSize+2; i++ {
+		rows = append(rows, map[string]interface{}{"id": i})
+	}
+	n, err := exa.InsertRows(s.schema, "foo", rows)
+	s.NoError(err)
+	s.Equal(int64(len(rows)), n)
+	s.Equal([][]interface{}{
+		{float64(1), "a", "x", float64(7)},
+		{float64(2), nil, nil, float64(7)},
+	}, s.fetch("SELECT * FROM "+s.qschema+".foo WHERE id < 3 ORDER BY id"))
+	s.Equal([][]interface{}{{float64(len(rows))}}, s.fetch("SELECT COUNT(*) FROM "+s.qschema+".foo"))
+
+	_, err = exa.InsertRows(s.schema, "foo", []map[string]interface{}{{"id": 1}, {"nope": 1}})
+	s.ErrorContains(err, `Row 2 has unknown column "nope"`)
+	_, err = exa.InsertRows(s.schema, "bar", rows)
+	s.ErrorContains(err, "Unable to find the columns")
+	_, err = exa.InsertRows(s.schema, "foo", []map[string]interface{}{{"id": "x"}, {"id": 4}})
+	s.Error(err)
+	s.Equal([][]interface{}{{float64(len(rows))}}, s.fetch("SELECT COUNT(*) FROM "+s.qschema+".foo"))
+
+	n, err = exa.InsertRows(s.schema, "foo", nil)
+	s.NoError(err)
+	s.Zero(n)
+	s.Equal(`"a""b"`, quoteExact(`a"b`))
+}
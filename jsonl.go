@@ -0,0 +1,163 @@
+/*
+	Converting exported CSV data into JSON Lines on the fly
+	for consumers (e.g. Elasticsearch or BigQuery loads) that want it
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// WriteJSONLines converts the exported CSV data into JSON Lines as it
+// streams in writing one object per row (keyed by column name in column
+// order) to w. It returns the number of rows written.
+// Rows.Columns must be set (see ExportOpts.Describe and DescribeQuery) as
+// they give the field names and types: DECIMALs and DOUBLEs are written as
+// numbers, BOOLEANs as booleans and everything else as strings. Empty
+// values are NULLs. The chunks are returned to the Pool as they're consumed
+// and if writing fails the export is stopped.
+func (r *Rows) WriteJSONLines(w io.Writer) (uint64, error) {
+	if len(r.Columns) == 0 {
+		r.drain()
+		if r.Error != nil {
+			return 0, r.Error
+		}
+		return 0, errors.New("Rows.Columns must be set to write JSON Lines")
+	}
+	keys := make([][]byte, len(r.Columns))
+	for i, col := range r.Columns {
+		key, err := marshalJSON(col.Name)
+		if err != nil {
+			return 0, err
+		}
+		keys[i] = append(key, ':')
+	}
+
+	csvr := csv.NewReader(&chunkReader{rows: r})
+	csvr.FieldsPerRecord = len(r.Columns)
+	csvr.ReuseRecord = true
+	out := bufio.NewWriterSize(w, fetchWriteBufSize)
+	var line bytes.Buffer
+	var n uint64
+	err := func() error {
+		for first := true; ; first = false {
+			rec, err := csvr.Read()
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if first && r.header {
+				continue
+			}
+			line.Reset()
+			line.WriteByte('{')
+			for i, field := range rec {
+				if i > 0 {
+					line.WriteByte(',')
+				}
+				line.Write(keys[i])
+				val, err := jsonLinesValue(field, r.Columns[i].DataType)
+				if err != nil {
+					return fmt.Errorf("Row %d column %s: %s", n+1, r.Columns[i].Name, err)
+				}
+				line.Write(val)
+			}
+			line.WriteString("}\n")
+			if _, err = out.Write(line.Bytes()); err != nil {
+				return err
+			}
+			n++
+		}
+	}()
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		r.drain()
+		return n, err
+	}
+	r.wg.Wait()
+	return n, r.Error
+}
+
+/*--- Private Routines ---*/
+
+// Reads the Rows.Data chunks in sequence returning each to the Pool once consumed
+type chunkReader struct {
+	rows  *Rows
+	chunk []byte
+	rest  []byte
+}
+
+func (cr *chunkReader) Read(p []byte) (int, error) {
+	for len(cr.rest) == 0 {
+		if cr.chunk != nil {
+			cr.rows.Pool.Put(cr.chunk)
+			cr.chunk = nil
+		}
+		chunk, ok := <-cr.rows.Data
+		if !ok {
+			return 0, io.EOF
+		}
+		cr.chunk, cr.rest = chunk, chunk
+	}
+	n := copy(p, cr.rest)
+	cr.rest = cr.rest[n:]
+	return n, nil
+}
+
+// Stops the export and discards whatever has already been read
+func (r *Rows) drain() {
+	r.stopExport()
+	for chunk := range r.Data {
+		r.Pool.Put(chunk)
+	}
+	r.wg.Wait()
+}
+
+// Encodes the CSV field as a JSON value of the appropriate type
+func jsonLinesValue(field string, dt DataType) ([]byte, error) {
+	if field == "" {
+		return []byte("null"), nil
+	}
+	switch dt.Type {
+	case "DECIMAL", "DOUBLE":
+		// Leading zeros may be left out e.g. .5
+		if strings.HasPrefix(field, ".") {
+			field = "0" + field
+		} else if strings.HasPrefix(field, "-.") {
+			field = "-0" + field[1:]
+		}
+		return marshalJSON(json.Number(field))
+	case "BOOLEAN":
+		switch strings.ToUpper(field) {
+		case "TRUE", "1":
+			return []byte("true"), nil
+		case "FALSE", "0":
+			return []byte("false"), nil
+		}
+		return nil, fmt.Errorf("Invalid BOOLEAN %q", field)
+	}
+	return marshalJSON(field)
+}
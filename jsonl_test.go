@@ -0,0 +1,56 @@
+package exasol
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+)
+
+func (s *testSuite) TestWriteJSONLines() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE ` + s.qschema + `.foo (
+		id DECIMAL(20,0), price DECIMAL(10,2), name VARCHAR(20), ok BOOLEAN, d DATE
+	)`)
+	s.execute(`INSERT INTO ` + s.qschema + `.foo VALUES
+		(12345678901234567890, 0.25, 'a,"b"' || CHR(10) || 'c', TRUE, '2020-01-02'),
+		(2, NULL, NULL, FALSE, NULL)`)
+
+	var buf bytes.Buffer
+	rows := exa.StreamSelect(s.schema, "foo", ExportOpts{Describe: true, WithColumnNames: true})
+	n, err := rows.WriteJSONLines(&buf)
+	s.NoError(err)
+	s.Equal(uint64(2), n)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	s.ElementsMatch([]string{
+		`{"ID":12345678901234567890,"PRICE":0.25,"NAME":"a,\"b\"\nc","OK":true,"D":"2020-01-02"}`,
+		`{"ID":2,"PRICE":null,"NAME":null,"OK":false,"D":null}`,
+	}, lines)
+
+	_, err = exa.StreamSelect(s.schema, "foo").WriteJSONLines(&buf)
+	s.ErrorContains(err, "Columns must be set")
+
+	// Chunks split mid-row and mid-quote
+	r := &Rows{
+		Data: make(chan []byte, 3),
+		Pool: &sync.Pool{}, // Not bufPool since these aren't its size
+		stop: make(chan bool, 1),
+		Columns: []Column{
+			{Name: "n", DataType: DataType{Type: "DOUBLE"}},
+			{Name: "s", DataType: DataType{Type: "VARCHAR"}},
+		},
+	}
+	r.Data <- []byte("-.5,\"x")
+	r.Data <- []byte("\"\"y\"\n1E3,<")
+	r.Data <- []byte("z>\n")
+	close(r.Data)
+	buf.Reset()
+	n, err = r.WriteJSONLines(&buf)
+	s.NoError(err)
+	s.Equal(uint64(2), n)
+	s.Equal(`{"n":-0.5,"s":"x\"y"}`+"\n"+`{"n":1E3,"s":"<z>"}`+"\n", buf.String())
+
+	_, err = jsonLinesValue("maybe", DataType{Type: "BOOLEAN"})
+	s.Error(err)
+	_, err = jsonLinesValue("1,5", DataType{Type: "DECIMAL"})
+	s.Error(err)
+}
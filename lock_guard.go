@@ -0,0 +1,115 @@
+/*
+	A debugging aid that catches Go routines using a shared Conn
+	without holding its lock (see ConnConf.LockGuard)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+type LockGuard int
+
+const (
+	LockGuardOff   LockGuard = iota
+	LockGuardLog             // Log misuse as an error
+	LockGuardPanic           // Panic upon misuse
+)
+
+/*--- Private Routines ---*/
+
+// The directory of this package's source files
+var pkgDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// Records the calling Go routine as holding the Conn's lock
+func (c *Conn) guardLocked() {
+	if c.Conf.LockGuard == LockGuardOff {
+		return
+	}
+	id, _ := currentGoroutine()
+	c.lockShared.Store(true)
+	c.lockOwner.Store(id)
+}
+
+func (c *Conn) guardUnlocking() {
+	if c.Conf.LockGuard != LockGuardOff {
+		c.lockOwner.Store(0)
+	}
+}
+
+// Called before each request is sent. Once the Conn has been locked (so
+// is presumably shared) requests must come from the Go routine holding the
+// lock. Go routines started by this package (e.g. to fetch result sets in
+// the background) act on behalf of their caller so they're exempt.
+func (c *Conn) guardSend() {
+	if c.Conf.LockGuard == LockGuardOff || !c.lockShared.Load() {
+		return
+	}
+	id, internal := currentGoroutine()
+	if internal {
+		return
+	}
+	var problem string
+	if owner := c.lockOwner.Load(); owner == 0 {
+		problem = fmt.Sprintf("Go routine %d used the Conn without holding its lock", id)
+	} else if owner != id {
+		problem = fmt.Sprintf("Go routine %d used the Conn while Go routine %d holds its lock", id, owner)
+	} else {
+		return
+	}
+	if c.Conf.LockGuard == LockGuardPanic {
+		panic("exasol: " + problem)
+	}
+	c.log.Error(problem)
+}
+
+// Returns the current Go routine's id and whether
+// it was started by this package (other than its tests)
+func currentGoroutine() (id int64, internal bool) {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	// goroutine 18 [running]:
+	fields := bytes.Fields(buf[:min(len(buf), 64)])
+	if len(fields) > 1 {
+		id, _ = strconv.ParseInt(string(fields[1]), 10, 64)
+	}
+	// created by <func> in goroutine 7
+	//         /path/to/file.go:123 +0x1d
+	i := bytes.LastIndex(buf, []byte("\ncreated by "))
+	if i < 0 {
+		return id, false
+	}
+	lines := strings.SplitN(string(buf[i+1:]), "\n", 3)
+	if len(lines) < 2 {
+		return id, false
+	}
+	file, _, _ := strings.Cut(strings.TrimSpace(lines[1]), ":")
+	internal = filepath.Dir(file) == pkgDir && !strings.HasSuffix(file, "_test.go")
+	return id, internal
+}
@@ -0,0 +1,55 @@
+package exasol
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (s *testSuite) TestLockGuard() {
+	c := &Conn{
+		Conf: ConnConf{LockGuard: LockGuardPanic},
+		wsh:  &testWSHandler{},
+	}
+	send := func() { c.asyncSend(&response{}) }
+	inGoroutine := func(fn func()) (panicked interface{}) {
+		done := make(chan struct{})
+		go func() {
+			defer func() {
+				panicked = recover()
+				close(done)
+			}()
+			fn()
+		}()
+		<-done
+		return
+	}
+
+	s.Nil(inGoroutine(send), "Not checked until it's been locked")
+
+	c.Lock()
+	s.NotPanics(send, "Held by this Go routine")
+	s.Contains(fmt.Sprint(inGoroutine(send)), "while Go routine", "Another Go routine")
+	s.Nil(inGoroutine(func() {
+		eachParallel(1, 1, 1, func(_, _ int) { send() })
+	}), "Unless it was")
+	c.Unlock()
+	s.Panics(send, "Not holding it")
+
+	s.True(c.TryLock())
+	s.NotPanics(send)
+	c.Unlock()
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.Out = &buf
+	c.log = logger
+	c.Conf.LockGuard = LockGuardLog
+	s.NotPanics(send)
+	s.Contains(buf.String(), "without holding its lock")
+
+	id, internal := currentGoroutine()
+	s.Positive(id)
+	s.False(internal)
+}
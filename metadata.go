@@ -0,0 +1,203 @@
+/*
+	Helpers for branching on the capabilities of the connected
+	server as reported at login (see Conn.Metadata)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerInfo is the cluster info gathered by RefreshMetadata
+type ServerInfo struct {
+	ReleaseVersion string
+	NumNodes       int
+	RawSizeGiB     float64 // Uncompressed data volume as of the last size measurement
+	MemSizeGiB     float64 // Compressed data volume as of the last size measurement
+	Attributes     *Attributes
+	RefreshedAt    time.Time
+}
+
+// Returns whether the negotiated websocket API protocol
+// version is at least the given version
+func (c *Conn) ProtocolAtLeast(version int) bool {
+	return c.Metadata != nil && int(c.Metadata.ProtocolVersion) >= version
+}
+
+// Returns whether the server's release version (e.g. "7.1.6")
+// is at least the given version (e.g. "7.1")
+func (c *Conn) ReleaseAtLeast(version string) bool {
+	return c.Metadata != nil && CompareVersions(c.Metadata.ReleaseVersion, version) >= 0
+}
+
+// HASHTYPE columns were introduced in Exasol 7.0
+func (c *Conn) SupportsHashtype() bool { return c.ReleaseAtLeast("7.0") }
+
+// Returns the maximum VARCHAR length the server allows (or 0 if unknown)
+func (c *Conn) MaxVarcharLength() uint64 {
+	if c.Metadata == nil {
+		return 0
+	}
+	return c.Metadata.MaxVarcharLength
+}
+
+// Returns the maximum identifier length the server allows (or 0 if unknown)
+func (c *Conn) MaxIdentifierLength() uint64 {
+	if c.Metadata == nil {
+		return 0
+	}
+	return c.Metadata.MaxIdentifierLength
+}
+
+// CompareVersions compares dotted version strings numerically returning
+// -1, 0 or 1 if a is less than, equal to or greater than b.
+// Missing components count as zero so "7.1" == "7.1.0". Any non-numeric
+// suffix on a component (e.g. the "-rc1" in "8.0.0-rc1") is ignored.
+func CompareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		an := versionPart(as, i)
+		bn := versionPart(bs, i)
+		if an < bn {
+			return -1
+		} else if an > bn {
+			return 1
+		}
+	}
+	return 0
+}
+
+// RefreshMetadata re-queries the session attributes and the cluster's
+// release, node count and database size. The results are cached in
+// Conn.ServerInfo so that monitoring endpoints can report them cheaply.
+func (c *Conn) RefreshMetadata() (*ServerInfo, error) {
+	attr, err := c.GetSessionAttr()
+	if err != nil {
+		return nil, err
+	}
+	info := &ServerInfo{Attributes: attr, RefreshedAt: time.Now()}
+
+	row, err := c.fetchRow(`
+		SELECT NPROC(), param_value
+		FROM exa_metadata
+		WHERE param_name = 'databaseProductVersion'
+	`)
+	if err != nil {
+		return nil, c.errorf("Unable to refresh metadata: %s", err)
+	}
+	if row != nil {
+		info.NumNodes = int(toFloat(row[0]))
+		info.ReleaseVersion, _ = row[1].(string)
+	}
+
+	// Sizes are measured periodically so there may not be any yet
+	row, err = c.fetchRow(`
+		SELECT raw_object_size, mem_object_size
+		FROM exa_statistics.exa_db_size_last_day
+		ORDER BY measure_time DESC
+		LIMIT 1
+	`)
+	if err != nil {
+		return nil, c.errorf("Unable to refresh metadata: %s", err)
+	}
+	if row != nil {
+		info.RawSizeGiB = toFloat(row[0])
+		info.MemSizeGiB = toFloat(row[1])
+	}
+
+	c.ServerInfo = info
+	return info, nil
+}
+
+// Node is a cluster node that can be connected to
+type Node struct {
+	Host string
+	Port uint16
+}
+
+// Nodes returns the nodes of the cluster. With protocol version 2+ the
+// server is asked for them (so they're correct even when connecting via
+// a single hostname) otherwise they're derived from ConnConf.Host.
+// The websocket API doesn't report the state of each node.
+func (c *Conn) Nodes() ([]Node, error) {
+	hosts := expandHostRange(c.Conf.Host)
+	if c.ProtocolAtLeast(2) {
+		res := &getHostsRes{}
+		ip, err := c.hostIP()
+		if err != nil {
+			return nil, c.errorf("Unable to get nodes: %s", err)
+		}
+		err = c.send(&getHostsReq{Command: "getHosts", HostIp: ip}, res)
+		if err != nil {
+			return nil, c.errorf("Unable to get nodes: %s", err)
+		}
+		if res.ResponseData != nil {
+			hosts = res.ResponseData.Nodes
+		}
+	}
+	nodes := make([]Node, len(hosts))
+	for i, h := range hosts {
+		nodes[i] = Node{Host: h, Port: c.Conf.Port}
+	}
+	return nodes, nil
+}
+
+/*--- Private Routines ---*/
+
+// getHosts wants the IP connected to whereas ConnConf.Host may be a hostname
+func (c *Conn) hostIP() (string, error) {
+	if net.ParseIP(c.host) != nil {
+		return c.host, nil
+	}
+	ips, err := net.DefaultResolver.LookupHost(c.ctx, c.host)
+	if err != nil {
+		return "", fmt.Errorf("Unable to resolve %s: %w", c.host, err)
+	}
+	return ips[0], nil
+}
+
+// Returns the first row of the results (or nil if there are none)
+func (c *Conn) fetchRow(sql string) ([]interface{}, error) {
+	rows, err := c.FetchSlice(sql)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+// Numbers may be float64, int64 or json.Number depending on ConnConf
+func toFloat(val interface{}) float64 {
+	var f float64
+	convertAssign(reflect.ValueOf(&f).Elem(), val, time.UTC)
+	return f
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	p := parts[i]
+	end := 0
+	for end < len(p) && p[end] >= '0' && p[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(p[:end])
+	return n
+}
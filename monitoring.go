@@ -0,0 +1,184 @@
+/*
+	Typed accessors for the EXA_STATISTICS monitoring views
+	for use by lightweight metrics exporters
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// DBSize is a row of EXA_DB_SIZE_* (sizes are in GiB)
+type DBSize struct {
+	MeasureTime          time.Time
+	RawObjectSize        float64
+	MemObjectSize        float64
+	AuxiliarySize        float64
+	StatisticsSize       float64
+	RecommendedDBRAMSize float64
+	StorageSize          float64
+	UsePercent           float64 `exasol:"use"`
+	ObjectCount          int64
+}
+
+// MonitorSample is a row of EXA_MONITOR_* (RAM in GiB, I/O in MiB/s)
+type MonitorSample struct {
+	MeasureTime     time.Time
+	Load            float64
+	CPU             float64 // Percent
+	TempDBRAM       float64
+	PersistentDBRAM float64
+	HDDRead         float64
+	HDDWrite        float64
+	Net             float64
+	Swap            float64
+}
+
+// UsageSample is a row of EXA_USAGE_*
+type UsageSample struct {
+	MeasureTime time.Time
+	Users       int64 // Connected users
+	Queries     int64 // Concurrent queries
+}
+
+// SQLHourly is a row of EXA_SQL_HOURLY (durations are in seconds)
+type SQLHourly struct {
+	IntervalStart time.Time
+	CommandName   string
+	CommandClass  string
+	Success       bool
+	Count         int64
+	DurationAvg   float64
+	DurationMax   float64
+}
+
+// DBSizes returns the database size measurements since the given time
+func (c *Conn) DBSizes(since time.Time) ([]DBSize, error) {
+	return collect(QueryStruct[DBSize](c, `
+		SELECT measure_time, raw_object_size, mem_object_size, auxiliary_size,
+			statistics_size, recommended_db_ram_size, storage_size, "USE", object_count
+		FROM exa_statistics.exa_db_size_last_day
+		WHERE measure_time >= ? ORDER BY measure_time
+	`, []interface{}{since}))
+}
+
+// MonitorSamples returns the CPU, RAM, disk and network
+// usage samples (taken every 30s) since the given time
+func (c *Conn) MonitorSamples(since time.Time) ([]MonitorSample, error) {
+	return collect(QueryStruct[MonitorSample](c, `
+		SELECT measure_time, load, cpu, temp_db_ram, persistent_db_ram,
+			hdd_read, hdd_write, net, swap
+		FROM exa_statistics.exa_monitor_last_day
+		WHERE measure_time >= ? ORDER BY measure_time
+	`, []interface{}{since}))
+}
+
+// UsageSamples returns the user and concurrent query counts since the given time
+func (c *Conn) UsageSamples(since time.Time) ([]UsageSample, error) {
+	return collect(QueryStruct[UsageSample](c, `
+		SELECT measure_time, users, queries
+		FROM exa_statistics.exa_usage_last_day
+		WHERE measure_time >= ? ORDER BY measure_time
+	`, []interface{}{since}))
+}
+
+// QueriesPerHour returns the hourly statement counts and durations
+// (per command and success) since the given time
+func (c *Conn) QueriesPerHour(since time.Time) ([]SQLHourly, error) {
+	return collect(QueryStruct[SQLHourly](c, `
+		SELECT interval_start, command_name, command_class, success,
+			count, duration_avg, duration_max
+		FROM exa_statistics.exa_sql_hourly
+		WHERE interval_start >= ? ORDER BY interval_start, command_name
+	`, []interface{}{since}))
+}
+
+// ProfilePart is a row of EXA_USER_PROFILE_LAST_DAY (RAM/IO in MiB)
+type ProfilePart struct {
+	StmtID        int64
+	PartID        int64
+	PartName      string
+	PartInfo      *string
+	ObjectSchema  *string
+	ObjectName    *string
+	ObjectRows    *float64
+	OutRows       *float64
+	Duration      float64 // Seconds
+	CPU           *float64
+	TempDBRAMPeak *float64
+	HDDRead       *float64
+	HDDWrite      *float64
+	Net           *float64
+	Remarks       *string
+}
+
+// FlushStatistics forces the statistics gathered so far to be committed
+// to the EXA_STATISTICS views. They only become visible in a subsequent
+// transaction so if autocommit is off Commit/Rollback before reading them.
+func (c *Conn) FlushStatistics() error {
+	_, err := c.Execute("FLUSH STATISTICS")
+	return err
+}
+
+// ProfileAndWait runs the statement (taking the same optional args as
+// Execute) with profiling enabled then flushes the statistics and returns
+// the statement's profile. Autocommit must be on for the profile to be visible.
+func (c *Conn) ProfileAndWait(sql string, args ...interface{}) ([]ProfilePart, error) {
+	_, err := c.Execute("ALTER SESSION SET PROFILE = 'ON'")
+	if err != nil {
+		return nil, err
+	}
+	profilingOn := true
+	defer func() {
+		if profilingOn {
+			c.Execute("ALTER SESSION SET PROFILE = 'OFF'")
+		}
+	}()
+	before, err := c.FetchOne("SELECT CURRENT_STATEMENT")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = c.Execute(sql, args...); err != nil {
+		return nil, err
+	}
+	profilingOn = false
+	if _, err = c.Execute("ALTER SESSION SET PROFILE = 'OFF'"); err != nil {
+		return nil, err
+	}
+	if err = c.FlushStatistics(); err != nil {
+		return nil, err
+	}
+
+	parts, err := collect(QueryStruct[ProfilePart](c, `
+		SELECT stmt_id, part_id, part_name, part_info, object_schema, object_name,
+			object_rows, out_rows, duration, cpu, temp_db_ram_peak,
+			hdd_read, hdd_write, net, remarks
+		FROM exa_statistics.exa_user_profile_last_day
+		WHERE session_id = CURRENT_SESSION AND stmt_id > ?
+			AND command_name <> 'ALTER SESSION'
+		ORDER BY stmt_id, part_id
+	`, []interface{}{before}))
+	if err != nil {
+		return nil, err
+	}
+	// Just the first profiled statement after CURRENT_STATEMENT
+	for i := range parts {
+		if parts[i].StmtID != parts[0].StmtID {
+			return parts[:i], nil
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,51 @@
+package exasol
+
+import "time"
+
+func (s *testSuite) TestMonitoring() {
+	exa := s.exaConn
+	since := time.Now().Add(-24 * time.Hour)
+
+	sizes, err := exa.DBSizes(since)
+	if s.NoError(err) && len(sizes) > 0 {
+		s.False(sizes[0].MeasureTime.IsZero())
+		s.GreaterOrEqual(sizes[0].RawObjectSize, float64(0))
+	}
+	samples, err := exa.MonitorSamples(since)
+	if s.NoError(err) && len(samples) > 0 {
+		s.GreaterOrEqual(samples[0].CPU, float64(0))
+	}
+	usage, err := exa.UsageSamples(since)
+	if s.NoError(err) && len(usage) > 0 {
+		s.GreaterOrEqual(usage[0].Users, int64(0))
+	}
+	hourly, err := exa.QueriesPerHour(since)
+	if s.NoError(err) && len(hourly) > 0 {
+		s.NotEmpty(hourly[0].CommandName)
+		s.Greater(hourly[0].Count, int64(0))
+	}
+}
+
+func (s *testSuite) TestProfileAndWait() {
+	exa := s.exaConn
+	s.execute(`CREATE TABLE foo ( id INT )`)
+	s.execute(`INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 1000`)
+
+	s.NoError(exa.FlushStatistics())
+	parts, err := exa.ProfileAndWait("SELECT COUNT(*) FROM foo WHERE id > ?", []interface{}{10})
+	if s.NoError(err) && s.NotEmpty(parts) {
+		s.Equal(int64(1), parts[0].PartID)
+		var scanned bool
+		for _, p := range parts {
+			s.Equal(parts[0].StmtID, p.StmtID, "Single statement")
+			if p.ObjectName != nil && *p.ObjectName == "FOO" {
+				scanned = true
+			}
+		}
+		s.True(scanned, "Profiled the table scan")
+	}
+
+	exa.Conf.SuppressError = true
+	_, err = exa.ProfileAndWait("ASDF")
+	s.Error(err)
+}
@@ -0,0 +1,181 @@
+/*
+	Helpers for spreading work across several connections
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// FetchResult is the outcome of one of ParallelFetch's queries
+type FetchResult struct {
+	Rows [][]interface{}
+	Err  error
+}
+
+// ParallelFetch runs independent queries concurrently across the
+// connections and returns their results in the same order as the queries.
+// Up to workers queries run at once (defaulting to one per connection).
+// If there are more workers than connections they share them, taking
+// each connection's Lock around each query, so the connections may also
+// be in use elsewhere as long as those users Lock them too.
+func ParallelFetch(conns []*Conn, queries []string, workers int) []FetchResult {
+	results := make([]FetchResult, len(queries))
+	if len(conns) == 0 {
+		err := fmt.Errorf("ParallelFetch requires at least one connection")
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+	eachParallel(len(conns), len(queries), workers, func(w, i int) {
+		conn := conns[w%len(conns)]
+		conn.Lock()
+		rows, err := conn.FetchSlice(queries[i])
+		conn.Unlock()
+		results[i] = FetchResult{rows, err}
+	})
+	return results
+}
+
+// LoadJob is a table for LoadTables to load. Data is called for the CSV
+// to send on each attempt so it must be able to start over for retries.
+type LoadJob struct {
+	Schema string
+	Table  string
+	Data   func() (<-chan []byte, error)
+}
+
+type LoadOpts struct {
+	Workers    int // How many tables to load at once. Defaults to one per connection.
+	Retries    int // How many times to retry a table that fails to load
+	RetryDelay time.Duration
+}
+
+// LoadOutcome is how a LoadJob went. Result is of the last attempt.
+type LoadOutcome struct {
+	Schema   string
+	Table    string
+	Result   *BulkResult
+	Attempts int
+	Err      error
+}
+
+// LoadTables loads a batch of tables across the connections (locking them
+// as ParallelFetch does) retrying each table that fails up to
+// opts.Retries times. Retries go to the next live connection. It returns
+// the outcomes in the same order as the jobs, the totals across them and
+// the errors of the tables that never loaded (joined) if any.
+func LoadTables(conns []*Conn, jobs []LoadJob, opts LoadOpts) ([]LoadOutcome, BulkResult, error) {
+	outcomes := make([]LoadOutcome, len(jobs))
+	var total BulkResult
+	if len(conns) == 0 {
+		return outcomes, total, fmt.Errorf("LoadTables requires at least one connection")
+	}
+
+	start := time.Now()
+	eachParallel(len(conns), len(jobs), opts.Workers, func(w, i int) {
+		job := jobs[i]
+		out := &outcomes[i]
+		out.Schema, out.Table = job.Schema, job.Table
+		for out.Attempts <= opts.Retries {
+			if out.Attempts > 0 && opts.RetryDelay > 0 {
+				time.Sleep(opts.RetryDelay)
+			}
+			conn := liveConn(conns, w+out.Attempts)
+			out.Attempts++
+			out.Result, out.Err = loadTable(conn, job)
+			if out.Err == nil {
+				return
+			}
+			conn.log.Warningf(
+				"Load of %s.%s failed (attempt %d): %s", job.Schema, job.Table, out.Attempts, out.Err,
+			)
+		}
+	})
+
+	var errs []error
+	for _, out := range outcomes {
+		if out.Result != nil {
+			total.BytesWritten += out.Result.BytesWritten
+			total.Chunks += out.Result.Chunks
+			total.Retries += out.Result.Retries
+		}
+		total.Retries += out.Attempts - 1
+		if out.Err != nil {
+			errs = append(errs, fmt.Errorf("%s.%s: %w", out.Schema, out.Table, out.Err))
+		}
+	}
+	total.Duration = time.Since(start)
+	return outcomes, total, errors.Join(errs...)
+}
+
+/*--- Private Routines ---*/
+
+// Calls fn for each of the n items from up to workers Go routines (or
+// one per connection if workers < 1) passing the worker's index
+func eachParallel(conns, n, workers int, fn func(worker, item int)) {
+	if workers < 1 {
+		workers = conns
+	}
+	workers = min(workers, n)
+
+	next := make(chan int)
+	go func() {
+		for i := range n {
+			next <- i
+		}
+		close(next)
+	}()
+	var wg sync.WaitGroup
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(w, i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Returns the first live connection starting from conns[from]
+// (wrapping around) or just conns[from] if none are alive
+func liveConn(conns []*Conn, from int) *Conn {
+	for i := range conns {
+		if c := conns[(from+i)%len(conns)]; c.IsAlive() {
+			return c
+		}
+	}
+	return conns[from%len(conns)]
+}
+
+func loadTable(conn *Conn, job LoadJob) (*BulkResult, error) {
+	if job.Data == nil {
+		return nil, fmt.Errorf("LoadJob.Data is required")
+	}
+	data, err := job.Data()
+	if err != nil {
+		return nil, fmt.Errorf("Unable to get the data: %w", err)
+	}
+	conn.Lock()
+	defer conn.Unlock()
+	return conn.StreamInsertResult(job.Schema, job.Table, data)
+}
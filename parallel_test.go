@@ -0,0 +1,103 @@
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+func (s *testSuite) TestParallelFetch() {
+	c2, err := Connect(s.connConf())
+	if !s.NoError(err) {
+		return
+	}
+	defer c2.Disconnect()
+	conns := []*Conn{s.exaConn, c2}
+
+	queries := make([]string, 10)
+	for i := range queries {
+		queries[i] = fmt.Sprintf("SELECT %d, CURRENT_SESSION FROM dual", i)
+	}
+	queries[3] = "SELECT * FROM no_such_table"
+
+	for _, workers := range []int{0, 1, 5} {
+		results := ParallelFetch(conns, queries, workers)
+		if !s.Len(results, 10) {
+			continue
+		}
+		sessions := map[interface{}]bool{}
+		for i, res := range results {
+			if i == 3 {
+				s.Error(res.Err, "Errors are per query")
+				continue
+			}
+			if s.NoError(res.Err) {
+				s.Equal(float64(i), res.Rows[0][0], "In query order")
+				sessions[res.Rows[0][1]] = true
+			}
+		}
+		if workers != 1 {
+			s.Len(sessions, 2, "Spread across the connections")
+		}
+	}
+
+	results := ParallelFetch(nil, queries[:1], 2)
+	s.EqualError(results[0].Err, "ParallelFetch requires at least one connection")
+	s.Empty(ParallelFetch(conns, nil, 2))
+}
+
+func (s *testSuite) TestLoadTables() {
+	c2, err := Connect(s.connConf())
+	if !s.NoError(err) {
+		return
+	}
+	defer c2.Disconnect()
+	conns := []*Conn{s.exaConn, c2}
+	s.execute("CREATE TABLE foo (id INT)")
+	s.execute("CREATE TABLE bar (id INT)")
+
+	csv := func(rows string) func() (<-chan []byte, error) {
+		return func() (<-chan []byte, error) {
+			data := make(chan []byte, 1)
+			data <- []byte(rows)
+			close(data)
+			return data, nil
+		}
+	}
+	flakyCalls := 0
+	flaky := func() (<-chan []byte, error) {
+		flakyCalls++
+		if flakyCalls == 1 {
+			return nil, errors.New("source unavailable")
+		}
+		return csv("3\n")()
+	}
+	jobs := []LoadJob{
+		{Schema: s.schema, Table: "FOO", Data: csv("1\n2\n")},
+		{Schema: s.schema, Table: "BAR", Data: flaky},
+		{Schema: s.schema, Table: "NO_SUCH_TABLE", Data: csv("4\n")},
+	}
+	outcomes, total, err := LoadTables(conns, jobs, LoadOpts{Retries: 1})
+	if s.Error(err) {
+		s.Contains(err.Error(), s.schema+".NO_SUCH_TABLE: ")
+		s.NotContains(err.Error(), "source unavailable")
+	}
+	if s.Len(outcomes, 3) {
+		s.Equal("FOO", outcomes[0].Table)
+		s.NoError(outcomes[0].Err)
+		s.Equal(1, outcomes[0].Attempts)
+		s.Equal(int64(4), outcomes[0].Result.BytesWritten)
+		s.NoError(outcomes[1].Err)
+		s.Equal(2, outcomes[1].Attempts, "Retried")
+		s.Error(outcomes[2].Err)
+		s.Equal(2, outcomes[2].Attempts)
+	}
+	s.Equal(int64(6), total.BytesWritten)
+	s.Greater(total.Duration, time.Duration(0))
+
+	count, _ := s.exaConn.Count("SELECT * FROM foo UNION ALL SELECT * FROM bar", nil, s.schema)
+	s.Equal(int64(3), count)
+
+	_, _, err = LoadTables(nil, jobs, LoadOpts{})
+	s.EqualError(err, "LoadTables requires at least one connection")
+}
@@ -13,8 +13,18 @@
 package exasol
 
 import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 type prepStmt struct {
@@ -23,14 +33,24 @@ type prepStmt struct {
 	lastUsed time.Time
 }
 
+// Statements are cached per schema since that's
+// what their identifiers were resolved against
+type prepStmtKey struct {
+	schema string
+	sql    string
+}
+
 func (c *Conn) getPrepStmt(schema, sql string) (*prepStmt, error) {
 	// TODO die if the num cols/rows expected by prepared statement
 	//      doesn't match the passed in data (i.e. placeholder/binds mismatch)
 	//      otherwise results in lowerlevel websocket closure
 
 	c.log.Debug("Preparing stmt for:", sql)
+	c.cacheMux.Lock()
+	defer c.cacheMux.Unlock()
 	psc := c.prepStmtCache
-	ps := psc[sql]
+	key := prepStmtKey{schema, sql}
+	ps := psc[key]
 	if ps == nil {
 		var err error
 		ps, err = c.createPrepStmt(schema, sql)
@@ -38,9 +58,11 @@ func (c *Conn) getPrepStmt(schema, sql string) (*prepStmt, error) {
 			return nil, err
 		}
 		if c.Conf.CachePrepStmts {
-			psc[sql] = ps
+			psc[key] = ps
+			c.statsMux.Lock()
 			c.Stats["StmtCacheLen"] = len(psc)
 			c.Stats["StmtCacheMiss"]++
+			c.statsMux.Unlock()
 		}
 	}
 	ps.lastUsed = time.Now()
@@ -49,10 +71,10 @@ func (c *Conn) getPrepStmt(schema, sql string) (*prepStmt, error) {
 	// but I saw something on the site about Exasol
 	// being unhappy if there are thousands of open statements.
 	if len(psc) > 1000 {
-		sortedStmts := make([]string, len(psc))
+		sortedStmts := make([]prepStmtKey, len(psc))
 		i := 0
-		for sql := range psc {
-			sortedStmts[i] = sql
+		for key := range psc {
+			sortedStmts[i] = key
 			i++
 		}
 		sort.Slice(sortedStmts, func(i, j int) bool {
@@ -70,7 +92,7 @@ func (c *Conn) createPrepStmt(schema string, sql string) (*prepStmt, error) {
 	sthReq := &createPrepStmtReq{
 		Command:    "createPreparedStatement",
 		Attributes: &Attributes{CurrentSchema: schema},
-		SqlText:    sql,
+		SqlText:    c.rewriteSQL(sql),
 	}
 	sthRes := &createPrepStmtRes{}
 	err := c.send(sthReq, sthRes)
@@ -95,3 +117,182 @@ func (c *Conn) closePrepStmt(sth int) error {
 	}
 	return nil
 }
+
+// Converts any []byte, time.Time and io.Reader values in the (columnar)
+// binds into strings that the websocket API accepts. []byte values are hex
+// encoded for HASHTYPE columns and base64 encoded otherwise. time.Time
+// values are formatted for TIMESTAMP columns in loc (i.e. the location
+// results are read back in, see Conn.timeLoc) whereas DATEs are their own
+// calendar date, without any zone conversion. io.Readers are read in (up to the column's size
+// in characters) as plain strings.
+// Strings bound to ASCII columns are checked up front so that non-ASCII
+// data fails before anything is sent rather than part way into a batch.
+// With coerce other values are converted to suit the parameter's type as
+// well (see coerceBind).
+// Columns needing conversion are copied (into a new outer slice) so the
+// caller's binds aren't modified.
+func encodeBinds(
+	binds [][]interface{}, cols []column, loc *time.Location, coerce bool,
+) ([][]interface{}, error) {
+	var encoded [][]interface{}
+	for i, colData := range binds {
+		var dt DataType
+		if i < len(cols) {
+			dt = cols[i].DataType
+		}
+		asciiOnly := dt.CharacterSet == "ASCII"
+		copied := false
+		for j, val := range colData {
+			var str string
+			switch v := val.(type) {
+			case string:
+				if asciiOnly && !isASCII(v) {
+					return nil, fmt.Errorf(
+						"Non-ASCII value in row %d for ASCII column %d (%s)", j+1, i+1, dt.Type,
+					)
+				}
+				continue
+			case []byte:
+				if dt.Type == "HASHTYPE" {
+					str = hex.EncodeToString(v)
+				} else {
+					str = base64.StdEncoding.EncodeToString(v)
+				}
+			case time.Time:
+				if dt.Type == "DATE" {
+					str = v.Format("2006-01-02")
+				} else {
+					str = v.In(loc).Format("2006-01-02 15:04:05.000000")
+				}
+			case io.Reader:
+				var err error
+				str, err = readBind(v, dt.Size)
+				if err != nil {
+					return nil, fmt.Errorf("Unable to read bind for column %d: %s", i+1, err)
+				}
+				if asciiOnly && !isASCII(str) {
+					return nil, fmt.Errorf(
+						"Non-ASCII value in row %d for ASCII column %d (%s)", j+1, i+1, dt.Type,
+					)
+				}
+			default:
+				var ok bool
+				if !coerce {
+					continue
+				} else if str, ok = coerceBind(v, dt); !ok {
+					continue
+				}
+			}
+			if !copied {
+				if encoded == nil {
+					encoded = append([][]interface{}(nil), binds...)
+				}
+				colData = append([]interface{}(nil), colData...)
+				encoded[i] = colData
+				copied = true
+			}
+			colData[j] = str
+		}
+	}
+	if encoded == nil {
+		return binds, nil
+	}
+	return encoded, nil
+}
+
+// Reads the reader in chunks erroring out as soon as it exceeds maxLen
+// characters (if non-zero) rather than buffering it all up first. As a
+// UTF-8 character is at most 4 bytes no more than 4*maxLen+1 are read.
+func readBind(r io.Reader, maxLen int) (string, error) {
+	if maxLen > 0 {
+		r = io.LimitReader(r, 4*int64(maxLen)+1)
+	}
+	var buf strings.Builder
+	_, err := io.Copy(&buf, r)
+	if err != nil {
+		return "", err
+	}
+	str := buf.String()
+	if maxLen > 0 && utf8.RuneCountInString(str) > maxLen {
+		return "", fmt.Errorf("value exceeds the column size of %d", maxLen)
+	}
+	return str, nil
+}
+
+// Values that JSON would encode as numbers but that Exasol would either
+// reject or lose precision on are converted to strings: integers too big
+// for a float64 bound to high precision DECIMALs, big.Int/Float/Rat and
+// json.Number values, numbers and bools bound to CHAR/VARCHARs and
+// time.Durations bound to INTERVAL DAY TO SECONDs.
+// Returns false if the value should be sent as is.
+func coerceBind(val interface{}, dt DataType) (string, bool) {
+	switch v := val.(type) {
+	case *big.Int:
+		if v == nil {
+			return "", false
+		}
+		return v.String(), true
+	case *big.Float:
+		if v == nil {
+			return "", false
+		}
+		return v.Text('f', -1), true
+	case *big.Rat:
+		if v == nil {
+			return "", false
+		} else if dt.Type == "DECIMAL" {
+			return v.FloatString(dt.Scale), true
+		}
+		// Other types have no scale so it's sent at full precision
+		return new(big.Float).SetRat(v).Text('g', -1), true
+	case json.Number:
+		return v.String(), true
+	case time.Duration:
+		if strings.HasPrefix(dt.Type, "INTERVAL DAY") {
+			return formatDaySecondInterval(v), true
+		}
+		return "", false
+	}
+
+	switch dt.Type {
+	case "DECIMAL":
+		// A float64 only holds 15 significant digits exactly
+		if dt.Precision <= 15 {
+			return "", false
+		}
+		rv := reflect.ValueOf(val)
+		switch {
+		case rv.CanInt():
+			return strconv.FormatInt(rv.Int(), 10), true
+		case rv.CanUint():
+			return strconv.FormatUint(rv.Uint(), 10), true
+		}
+	case "CHAR", "VARCHAR":
+		rv := reflect.ValueOf(val)
+		switch {
+		case rv.CanInt():
+			return strconv.FormatInt(rv.Int(), 10), true
+		case rv.CanUint():
+			return strconv.FormatUint(rv.Uint(), 10), true
+		case rv.CanFloat():
+			return strconv.FormatFloat(rv.Float(), 'f', -1, 64), true
+		case rv.Kind() == reflect.Bool:
+			return strconv.FormatBool(rv.Bool()), true
+		}
+	}
+	return "", false
+}
+
+// Formats it like Exasol does e.g. +01 02:03:04.500
+func formatDaySecondInterval(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	h, m := d/time.Hour, d%time.Hour/time.Minute
+	sec, ms := d%time.Minute/time.Second, d%time.Second/time.Millisecond
+	return fmt.Sprintf("%s%02d %02d:%02d:%02d.%03d", sign, days, h, m, sec, ms)
+}
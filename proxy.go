@@ -16,33 +16,105 @@
 package exasol
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// Small chunks are coalesced into writes of up to this size
+const proxyWriteBufSize = 64 * 1024
+
 type Proxy struct {
 	Host string
 	Port uint32
 
 	conn    net.Conn
+	node    string // The host dialed
 	running bool
+	runMux  sync.Mutex // Guards running as Shutdown can be called concurrently
 	pool    *sync.Pool
 	log     Logger
+	// Called with each chunk Read and returns what to send on in its place
+	// (nil to send nothing). It's called with nil at the end of the data.
+	onRead func(chunk []byte) []byte
+	// Called after each chunk from onRead has been sent on to Read's chan
+	onSent func()
+	// Called with each chunk successfully sent by Write
+	onWrite func(chunk []byte)
+	// If set onStats is called every statsEvery during Read/Write
+	statsEvery time.Duration
+	onStats    func(ProxyStats)
+}
+
+// ProxyStats is the throughput of a proxy over an interval. IOWait is the
+// time spent blocked on the socket (i.e. waiting on the network/Exasol)
+// and ChanWait the time spent blocked on the data chan (i.e. waiting on
+// the application) so that slow transfers can be pinned on one or the other.
+type ProxyStats struct {
+	Node         string
+	Direction    string // "read" (EXPORT) or "write" (IMPORT)
+	Interval     time.Duration
+	Bytes        int64 // During the interval
+	Chunks       int64
+	TotalBytes   int64 // Since the transfer started
+	BytesPerSec  float64
+	ChunksPerSec float64
+	IOWait       time.Duration
+	ChanWait     time.Duration
 }
 
+// NewProxy asks Exasol to setup a proxy for a bulk IMPORT/EXPORT. The host
+// may be an IP range (e.g. 10.0.0.1..4) in which case the nodes are tried
+// in turn until one can be dialed. Node returns the one that was used.
 func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy, error) {
+	var d net.Dialer
+	return newProxyFailover(d.DialContext, expandHostRange(host), port, bufPool, log)
+}
+
+// Tries each host until a proxy can be opened on one of them
+func newProxyFailover(dial dialFunc, hosts []string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy, error) {
+	var errs []error
+	for _, host := range hosts {
+		p, err := newProxy(dial, host, port, bufPool, log)
+		if err == nil {
+			if len(errs) > 0 {
+				p.log.Warningf("Proxy failed over to %s: %s", host, errors.Join(errs...))
+			}
+			return p, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", host, err))
+	}
+	if len(errs) == 1 {
+		return nil, errors.Unwrap(errs[0])
+	}
+	return nil, hostsError(errs)
+}
+
+func newProxy(dial dialFunc, host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy, error) {
+	if bufPool == nil {
+		bufPool = &sync.Pool{New: func() interface{} { return []byte{} }}
+	}
+	if log == nil {
+		log = newDefaultLogger()
+	}
 	p := &Proxy{
 		pool: bufPool,
 		log:  log,
+		node: host,
 	}
 
 	var err error
 	uri := fmt.Sprintf("%s:%d", host, port)
-	p.conn, err = net.Dial("tcp", uri)
+	p.conn, err = dial(context.Background(), "tcp", uri)
 	if err != nil {
 		return nil, fmt.Errorf("Unable to setup proxy (1): %s", err)
 	}
@@ -55,13 +127,15 @@ func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy,
 	binary.LittleEndian.PutUint32(req[8:], 1)
 	_, err = p.conn.Write(req)
 	if err != nil {
+		p.Shutdown()
 		return nil, fmt.Errorf("Unable to setup proxy (2): %s", err)
 	}
 
 	// Exasol replies with the internal host/port it's listening on
 	resp := make([]byte, 24)
-	_, err = p.conn.Read(resp)
+	_, err = io.ReadFull(p.conn, resp)
 	if err != nil {
+		p.Shutdown()
 		return nil, fmt.Errorf("Unable to setup proxy (3): %s", err)
 	}
 
@@ -72,7 +146,35 @@ func NewProxy(host string, port uint16, bufPool *sync.Pool, log Logger) (*Proxy,
 	return p, nil
 }
 
+// Read receives the data of an EXPORT sending it to the data chan in
+// chunks. Sending on stop ends it early (without error) even if it's
+// blocked waiting on Exasol as the socket gets shut down.
 func (p *Proxy) Read(data chan<- []byte, stop <-chan bool) (int64, error) {
+	if data == nil {
+		return 0, fmt.Errorf("Proxy.Read requires a data chan")
+	}
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			close(stopped)
+			p.Shutdown() // Interrupts any blocked conn.Read
+		case <-done:
+		}
+	}()
+
+	totalRead, err := p.read(data, stopped)
+	select {
+	case <-stopped:
+		return totalRead, nil // Errors are expected once the socket is shut
+	default:
+		return totalRead, err
+	}
+}
+
+func (p *Proxy) read(data chan<- []byte, stopped <-chan struct{}) (int64, error) {
 	_, err := p.readHeaders()
 	if err != nil {
 		return 0, err
@@ -86,8 +188,11 @@ func (p *Proxy) Read(data chan<- []byte, stop <-chan bool) (int64, error) {
 
 	// Read chunks
 	var totalRead int64
+	m := p.startMeter("read")
+	defer m.stop()
 DATA:
 	for {
+		started := m.now()
 		chunkSize, err := p.readLine()
 		if err != nil {
 			return totalRead, fmt.Errorf("Unable to read from proxy(2): %s", err)
@@ -120,6 +225,7 @@ DATA:
 		if len(endOfChunk) != 0 || err != nil {
 			return totalRead, fmt.Errorf("Unable to read from proxy(4):%s/%s", endOfChunk, err)
 		}
+		m.ioWaited(started)
 
 		if chunkLen == 0 {
 			// Last chunk so wrap up and head out
@@ -128,22 +234,46 @@ DATA:
 				"Content-Length: 0",
 				"Connection: close",
 			})
+			if p.onRead != nil {
+				if rest := p.onRead(nil); len(rest) > 0 {
+					select {
+					case <-stopped:
+					case data <- rest:
+						if p.onSent != nil {
+							p.onSent()
+						}
+					}
+				}
+			}
 			break
 		}
 
 		totalRead += chunkLen
+		m.add(chunkLen)
+		if p.onRead != nil {
+			if chunk = p.onRead(chunk); chunk == nil {
+				continue
+			}
+		}
+		started = m.now()
 		select {
-		case <-stop:
-			p.Shutdown()
+		case <-stopped:
 			break DATA
 		case data <- chunk:
 		}
+		m.chanWaited(started)
+		if p.onSent != nil {
+			p.onSent()
+		}
 	}
 
 	return totalRead, nil
 }
 
 func (p *Proxy) Write(data <-chan []byte) (bytesWritten int64, err error) {
+	if data == nil {
+		return 0, fmt.Errorf("Proxy.Write requires a data chan")
+	}
 	_, err = p.readHeaders()
 	if err != nil {
 		return bytesWritten, err
@@ -160,26 +290,64 @@ func (p *Proxy) Write(data <-chan []byte) (bytesWritten int64, err error) {
 	if err != nil {
 		err = fmt.Errorf("Unable to send headers to proxy: %s", err)
 	} else {
-		for b := range data {
+		m := p.startMeter("write")
+		defer m.stop()
+		// The chunk framing and payloads are coalesced so that small chunks
+		// don't each go out as several tiny TCP segments. The buffer is
+		// flushed whenever it fills up or the data chan has nothing ready.
+		w := bufio.NewWriterSize(p.conn, proxyWriteBufSize)
+		for {
+			var b []byte
+			ok := true
+			select {
+			case b, ok = <-data:
+			default:
+				started := m.now()
+				if err = w.Flush(); err != nil {
+					break
+				}
+				m.ioWaited(started)
+				started = m.now()
+				b, ok = <-data
+				m.chanWaited(started)
+			}
+			if err != nil || !ok {
+				break
+			}
+			if len(b) == 0 {
+				// A zero length chunk would signal the end of the data
+				continue
+			}
 			l := int64(len(b))
-			bytesWritten += l
-			chunkSize := strconv.FormatInt(l, 16)
-			p.conn.Write([]byte(chunkSize))
-			p.conn.Write([]byte("\r\n"))
-			_, err = p.conn.Write(b)
-			if err != nil {
-				err = fmt.Errorf("Unable to upload data to proxy (2): %s", err)
+			started := m.now()
+			w.WriteString(strconv.FormatInt(l, 16))
+			w.WriteString("\r\n")
+			w.Write(b)
+			if _, err = w.WriteString("\r\n"); err != nil {
 				break
 			}
-			p.conn.Write([]byte("\r\n"))
+			m.ioWaited(started)
+			bytesWritten += l
+			m.add(l)
+			if p.onWrite != nil {
+				p.onWrite(b)
+			}
+		}
+		if err == nil {
+			w.WriteString("0\r\n\r\n") // A final zero chunk
+			err = w.Flush()
+		}
+		if err != nil {
+			err = fmt.Errorf("Unable to upload data to proxy (2): %s", err)
 		}
-		p.conn.Write([]byte("0\r\n\r\n")) // A final zero chunk
 	}
 	return bytesWritten, err
 }
 
 func (p *Proxy) Shutdown() {
-	if p.IsRunning() {
+	p.runMux.Lock()
+	defer p.runMux.Unlock()
+	if p.running {
 		if p.conn != nil {
 			p.conn.Close()
 		}
@@ -187,12 +355,23 @@ func (p *Proxy) Shutdown() {
 	}
 }
 
+// Node returns the Exasol node the proxy is attached to
+// (whereas Host is the address the node listens on for it)
+func (p *Proxy) Node() string { return p.node }
+
 func (p *Proxy) IsRunning() bool {
+	p.runMux.Lock()
+	defer p.runMux.Unlock()
 	return p.running
 }
 
 /* Private routines */
 
+// The URL for the server to reach the proxy at
+func (p *Proxy) url() string {
+	return fmt.Sprintf("http://%s:%d", p.Host, p.Port)
+}
+
 func (p *Proxy) readLine() ([]byte, error) {
 	var line bytes.Buffer
 	var err error
@@ -243,3 +422,100 @@ func (p *Proxy) readHeaders() (headers []string, err error) {
 	}
 	return headers, nil
 }
+
+// Tallies the throughput of a Read/Write reporting it every statsEvery.
+// A nil meter (when stats aren't wanted) does nothing.
+type meter struct {
+	node, direction string
+	every           time.Duration
+	report          func(ProxyStats)
+	bytes           atomic.Int64
+	chunks          atomic.Int64
+	total           atomic.Int64
+	ioWait          atomic.Int64
+	chanWait        atomic.Int64
+	done            chan struct{}
+	wg              sync.WaitGroup
+}
+
+func (p *Proxy) startMeter(direction string) *meter {
+	if p.onStats == nil || p.statsEvery <= 0 {
+		return nil
+	}
+	m := &meter{
+		node:      p.node,
+		direction: direction,
+		every:     p.statsEvery,
+		report:    p.onStats,
+		done:      make(chan struct{}),
+	}
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(m.every)
+		defer ticker.Stop()
+		last := time.Now()
+		for {
+			select {
+			case <-m.done:
+				return
+			case now := <-ticker.C:
+				m.flush(now.Sub(last))
+				last = now
+			}
+		}
+	}()
+	return m
+}
+
+func (m *meter) flush(interval time.Duration) {
+	bytes, chunks := m.bytes.Swap(0), m.chunks.Swap(0)
+	secs := interval.Seconds()
+	m.report(ProxyStats{
+		Node:         m.node,
+		Direction:    m.direction,
+		Interval:     interval,
+		Bytes:        bytes,
+		Chunks:       chunks,
+		TotalBytes:   m.total.Load(),
+		BytesPerSec:  float64(bytes) / secs,
+		ChunksPerSec: float64(chunks) / secs,
+		IOWait:       time.Duration(m.ioWait.Swap(0)),
+		ChanWait:     time.Duration(m.chanWait.Swap(0)),
+	})
+}
+
+func (m *meter) stop() {
+	if m != nil {
+		close(m.done)
+		m.wg.Wait()
+	}
+}
+
+// Saves calling time.Now when there's no meter
+func (m *meter) now() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+func (m *meter) add(bytes int64) {
+	if m != nil {
+		m.bytes.Add(bytes)
+		m.chunks.Add(1)
+		m.total.Add(bytes)
+	}
+}
+
+func (m *meter) ioWaited(since time.Time) {
+	if m != nil {
+		m.ioWait.Add(int64(time.Since(since)))
+	}
+}
+
+func (m *meter) chanWaited(since time.Time) {
+	if m != nil {
+		m.chanWait.Add(int64(time.Since(since)))
+	}
+}
@@ -0,0 +1,399 @@
+/*
+	Higher level interfaces for fetching rows: iterators,
+	sql.Rows style scanning and paging cursors
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"database/sql"
+	"fmt"
+	"iter"
+	"reflect"
+)
+
+// Query executes the query (taking the same optional args as FetchChan)
+// and returns an iterator over the resulting rows:
+//
+//	for row, err := range conn.Query("SELECT * FROM t WHERE c = ?", binds) {
+//	    if err != nil {
+//	        ...
+//	    }
+//	}
+//
+// Breaking out of the loop early closes the server-side result set.
+// An error fetching the rows part way through is yielded after the
+// rows fetched before it.
+func (c *Conn) Query(sql string, args ...interface{}) iter.Seq2[[]interface{}, error] {
+	return func(yield func([]interface{}, error) bool) {
+		done := make(chan struct{})
+		ch, rs, err := c.fetch(sql, args, done)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer stopFetch(done, ch)
+
+		for row := range ch {
+			if !yield(row, nil) {
+				return
+			}
+		}
+		if rs.fetchErr != nil {
+			yield(nil, rs.fetchErr)
+		}
+	}
+}
+
+// QueryStruct is like Query but each row is decoded into a T which must be
+// a struct. Columns are matched to fields via an `exasol:"column_name"` tag
+// or else by case-insensitive name (ignoring underscores so that a USER_ID
+// column matches a UserID field). Unmatched columns are ignored.
+// This is a function rather than a method because Go methods can't be generic.
+func QueryStruct[T any](c *Conn, sql string, args ...interface{}) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		done := make(chan struct{})
+		ch, rs, err := c.fetch(sql, args, done)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer stopFetch(done, ch)
+
+		fields, err := structFieldMap(reflect.TypeOf(zero), rs.Columns)
+		if err != nil {
+			yield(zero, c.errorf("Unable to QueryStruct: %s", err))
+			return
+		}
+
+		for row := range ch {
+			var ret T
+			rv := reflect.ValueOf(&ret).Elem()
+			for i, val := range row {
+				if i >= len(fields) || fields[i] == nil {
+					continue
+				}
+				err = convertAssign(rv.FieldByIndex(fields[i]), val, c.timeLoc())
+				if err != nil {
+					err = c.errorf("Unable to QueryStruct column %s: %s", rs.Columns[i].Name, err)
+					break
+				}
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(ret, nil) {
+				return
+			}
+		}
+		if rs.fetchErr != nil {
+			yield(zero, rs.fetchErr)
+		}
+	}
+}
+
+// Cursor keeps a server-side result set open so that arbitrary pages
+// of it can be fetched (e.g. by a web UI) without re-running the query.
+// Other statements can still be run on the connection while it is open.
+type Cursor struct {
+	conn   *Conn
+	rs     *resultSet
+	opts   FetchOpts
+	closed bool
+}
+
+// OpenCursor executes the query (taking the same optional args as FetchChan)
+// and returns a Cursor for paging through the results.
+// The Cursor must be closed when you're done with it.
+func (c *Conn) OpenCursor(sql string, args ...interface{}) (*Cursor, error) {
+	rs, opts, err := c.query(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{conn: c, rs: rs, opts: opts}, nil
+}
+
+// Returns the total number of rows in the result set
+func (cur *Cursor) NumRows() uint64 { return cur.rs.NumRows }
+
+// Returns the column names
+func (cur *Cursor) Columns() []string {
+	names := make([]string, len(cur.rs.Columns))
+	for i, col := range cur.rs.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Returns the column names and data types
+func (cur *Cursor) ColumnTypes() []Column { return exportColumns(cur.rs.Columns) }
+
+// Page returns up to limit rows starting at offset (zero-based). Its first
+// fetch request is sized for the page (unless FetchOpts.Prefetch is set)
+// rather than being for MaxFetchBytes.
+func (cur *Cursor) Page(offset, limit uint64) ([][]interface{}, error) {
+	if cur.closed {
+		return nil, cur.conn.error("Cursor is closed")
+	}
+	end := offset + limit
+	if end > cur.rs.NumRows {
+		end = cur.rs.NumRows
+	}
+	var rows [][]interface{}
+
+	// The first rows may have been sent along with the query results
+	pos := offset
+	if cur.rs.Data != nil && len(cur.rs.Data) > 0 {
+		for ; pos < end && pos < uint64(len(cur.rs.Data[0])); pos++ {
+			rows = append(rows, cur.row(cur.rs.Data, int(pos)))
+		}
+	}
+
+	// Rather than MaxFetchBytes the first request is sized for the page
+	// (unless there's a Prefetch) and doubles if that wasn't enough
+	maxBytes := cur.opts.MaxFetchBytes
+	if maxBytes <= 0 || maxBytes > maxFetchBytes {
+		maxBytes = maxFetchBytes
+	}
+	numBytes := cur.opts.Prefetch
+	if numBytes <= 0 {
+		numBytes = max(int(min(end-pos, maxFetchBytes))*pageBytesPerValue*len(cur.rs.Columns), minPageBytes)
+	}
+	numBytes = min(numBytes, maxBytes)
+	for pos < end && cur.rs.ResultSetHandle != 0 {
+		fetchRes := &fetchRes{numbers: cur.conn.wantNumbers(cur.opts)}
+		err := cur.conn.send(&fetchReq{
+			Command:         "fetch",
+			ResultSetHandle: cur.rs.ResultSetHandle,
+			StartPosition:   pos,
+			NumBytes:        numBytes,
+		}, fetchRes)
+		if err != nil {
+			return nil, cur.conn.errorf("Unable to fetch page: %s", err)
+		}
+		data := fetchRes.ResponseData.Data
+		cur.conn.decodeData(data, cur.rs.Columns)
+		if fetchRes.ResponseData.NumRows == 0 || len(data) == 0 {
+			break
+		}
+		for i := 0; i < len(data[0]) && pos < end; i++ {
+			rows = append(rows, cur.row(data, i))
+			pos++
+		}
+		numBytes = min(numBytes*2, maxBytes)
+	}
+	return rows, nil
+}
+
+// Close closes the server-side result set.
+// It is safe to call multiple times.
+func (cur *Cursor) Close() error {
+	if cur.closed || cur.rs.ResultSetHandle == 0 {
+		cur.closed = true
+		return nil
+	}
+	cur.closed = true
+	err := cur.conn.closeResultSet(cur.rs.ResultSetHandle)
+	if err != nil {
+		return cur.conn.errorf("Unable to close cursor: %s", err)
+	}
+	return nil
+}
+
+// FetchPage returns up to limit rows of the query's results starting
+// at offset (zero-based). It takes the same optional args as FetchChan.
+// Use a Cursor instead if you are going to be fetching multiple pages.
+func (c *Conn) FetchPage(sql string, offset, limit uint64, args ...interface{}) ([][]interface{}, error) {
+	cur, err := c.OpenCursor(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close()
+	return cur.Page(offset, limit)
+}
+
+/*--- Private Routines ---*/
+
+// For sizing a Cursor page's fetch requests
+const (
+	pageBytesPerValue = 64
+	minPageBytes      = 64 * 1024
+)
+
+func exportColumns(cols []column) []Column {
+	ret := make([]Column, len(cols))
+	for i, col := range cols {
+		ret[i] = Column{Name: col.Name, DataType: col.DataType}
+	}
+	return ret
+}
+
+// Extracts a row from columnar data
+func (cur *Cursor) row(data [][]interface{}, i int) []interface{} {
+	row := make([]interface{}, len(data))
+	for col := range data {
+		row[col] = data[col][i]
+	}
+	return row
+}
+
+// Stops a fetch started with a done chan and waits
+// for the server-side result set to be closed.
+func stopFetch(done chan struct{}, ch <-chan []interface{}) {
+	close(done)
+	for range ch {
+	}
+}
+
+// Column describes a result set column
+type Column struct {
+	Name     string
+	DataType DataType
+}
+
+// ResultRows provides a database/sql style interface for iterating
+// over query results. See FetchRows.
+type ResultRows struct {
+	conn    *Conn
+	rs      *resultSet
+	columns []column
+	ch      <-chan []interface{}
+	done    chan struct{}
+	row     []interface{}
+	nulls   []bool
+	closed  bool
+}
+
+// FetchRows executes the query (taking the same optional args as FetchChan)
+// and returns a ResultRows which works much like sql.Rows:
+//
+//	rows, err := conn.FetchRows("SELECT id, name FROM t")
+//	defer rows.Close()
+//	for rows.Next() {
+//	    var id int64
+//	    var name string
+//	    err = rows.Scan(&id, &name)
+//	}
+func (c *Conn) FetchRows(sql string, args ...interface{}) (*ResultRows, error) {
+	done := make(chan struct{})
+	ch, rs, err := c.fetch(sql, args, done)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultRows{
+		conn:    c,
+		rs:      rs,
+		columns: rs.Columns,
+		ch:      ch,
+		done:    done,
+	}, nil
+}
+
+// Returns the column names
+func (r *ResultRows) Columns() []string {
+	names := make([]string, len(r.columns))
+	for i, col := range r.columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Returns the column names and data types
+func (r *ResultRows) ColumnTypes() []Column { return exportColumns(r.columns) }
+
+// Next advances to the next row returning false when there are no more
+// (at which point the ResultRows is closed automatically) or upon an
+// error fetching them (see Err).
+func (r *ResultRows) Next() bool {
+	if r.closed {
+		return false
+	}
+	row, ok := <-r.ch
+	if !ok {
+		r.Close()
+		return false
+	}
+	r.row = row
+	if r.nulls == nil {
+		r.nulls = make([]bool, len(row))
+	}
+	for i, v := range row {
+		r.nulls[i] = v == nil
+	}
+	return true
+}
+
+// Nulls returns which of the current row's columns were SQL NULL.
+// Unlike the scanned values this is unambiguous even after they've
+// been converted into zero values (e.g. into an int64 or string).
+// The returned slice is reused by Next so copy it if you want to retain it.
+func (r *ResultRows) Nulls() []bool {
+	if r.row == nil {
+		return nil
+	}
+	return r.nulls
+}
+
+// Returns whether the current row's ith column was SQL NULL
+func (r *ResultRows) IsNull(i int) bool {
+	return r.row != nil && i >= 0 && i < len(r.nulls) && r.nulls[i]
+}
+
+// Scan copies the current row's values into dest which must be pointers.
+// Values are converted into *int64, *float64, *string, *bool, *time.Time etc
+// as necessary. Destinations implementing sql.Scanner are also supported
+// as is *interface{} which receives the raw value.
+func (r *ResultRows) Scan(dest ...interface{}) error {
+	if r.row == nil {
+		return r.conn.error("Scan called without calling Next")
+	}
+	if len(dest) != len(r.row) {
+		return r.conn.errorf("Scan expected %d destination arguments, not %d", len(r.row), len(dest))
+	}
+	for i, d := range dest {
+		var err error
+		if scanner, ok := d.(sql.Scanner); ok {
+			err = scanner.Scan(r.row[i])
+		} else if v := reflect.ValueOf(d); v.Kind() != reflect.Ptr || v.IsNil() {
+			err = fmt.Errorf("destination is not a non-nil pointer")
+		} else {
+			err = convertAssign(v.Elem(), r.row[i], r.conn.timeLoc())
+		}
+		if err != nil {
+			return r.conn.errorf("Unable to Scan column %s: %s", r.columns[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error, if any, that stopped Next part way through the
+// rows. As with sql.Rows it should be checked once Next returns false.
+func (r *ResultRows) Err() error {
+	if !r.closed {
+		return nil
+	}
+	return r.rs.fetchErr
+}
+
+// Close stops the fetching and closes the server-side result set.
+// It is safe to call multiple times.
+func (r *ResultRows) Close() {
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.row = nil
+	stopFetch(r.done, r.ch)
+}
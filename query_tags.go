@@ -0,0 +1,81 @@
+/*
+	Tagging statements with comments for correlating them in the
+	statistics and audit views (see ConnConf.QueryTags)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// SetQueryContext sets the context passed to ConnConf.QueryTagsFunc for
+// subsequent statements e.g. the context of the request a service is
+// handling when it borrows the connection. Pass nil to clear it.
+func (c *Conn) SetQueryContext(ctx context.Context) {
+	if ctx == nil {
+		c.queryCtx.Store(nil)
+	} else {
+		c.queryCtx.Store(&ctx)
+	}
+}
+
+/*--- Private Routines ---*/
+
+// Prepends the ConnConf.QueryTags and QueryTagsFunc's tags as a comment
+func (c *Conn) tagSQL(sql string) string {
+	if c.Conf.QueryTags == nil && c.Conf.QueryTagsFunc == nil {
+		return sql
+	}
+	tags := map[string]string{}
+	for k, v := range c.Conf.QueryTags {
+		tags[k] = v
+	}
+	if c.Conf.QueryTagsFunc != nil {
+		ctx := context.Background()
+		if p := c.queryCtx.Load(); p != nil {
+			ctx = *p
+		}
+		for k, v := range c.Conf.QueryTagsFunc(ctx) {
+			tags[k] = v
+		}
+	}
+	comment := formatQueryTags(tags)
+	if comment == "" {
+		return sql
+	}
+	return comment + " " + sql
+}
+
+// Formats the tags (sorted by key) as a comment e.g. /* a=1, b=2 */
+// Anything that would end the comment early is defanged.
+func formatQueryTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	clean := strings.NewReplacer("*/", "* /", "/*", "/ *", "\n", " ", "\r", " ")
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = clean.Replace(k) + "=" + clean.Replace(tags[k])
+	}
+	return "/* " + strings.Join(pairs, ", ") + " */"
+}
@@ -0,0 +1,41 @@
+package exasol
+
+import (
+	"context"
+	"strings"
+)
+
+type traceKey struct{}
+
+func (s *testSuite) TestQueryTags() {
+	conf := s.connConf()
+	conf.QueryTags = map[string]string{"job": "nightly", "team": "etl"}
+	conf.QueryTagsFunc = func(ctx context.Context) map[string]string {
+		if id, ok := ctx.Value(traceKey{}).(string); ok {
+			return map[string]string{"trace_id": id, "team": "override"}
+		}
+		return nil
+	}
+	exa, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer exa.Disconnect()
+
+	currentSQL := func() string {
+		sql, err := exa.FetchOne("SELECT sql_text FROM exa_all_sessions WHERE session_id = CURRENT_SESSION")
+		s.NoError(err)
+		str, _ := sql.(string)
+		return str
+	}
+	s.True(strings.HasPrefix(currentSQL(), "/* job=nightly, team=etl */ SELECT"), currentSQL())
+
+	exa.SetQueryContext(context.WithValue(context.Background(), traceKey{}, "abc*/123"))
+	s.True(strings.HasPrefix(currentSQL(), "/* job=nightly, team=override, trace_id=abc* /123 */ SELECT"))
+	exa.SetQueryContext(nil)
+	s.True(strings.HasPrefix(currentSQL(), "/* job=nightly, team=etl */ SELECT"))
+
+	s.Equal("", formatQueryTags(nil))
+	s.Equal("/* a=1, b=x y */", formatQueryTags(map[string]string{"b": "x\ny", "a": "1"}))
+	s.Equal("SELECT 1", s.exaConn.tagSQL("SELECT 1"), "No tags by default")
+}
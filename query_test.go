@@ -0,0 +1,295 @@
+package exasol
+
+import (
+	"database/sql"
+	"time"
+)
+
+func (s *testSuite) TestQuery() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val CHAR(1) )")
+	exa.Execute("INSERT INTO foo SELECT level, 'a' FROM dual CONNECT BY level <= 2500")
+
+	var got [][]interface{}
+	for row, err := range exa.Query("SELECT * FROM foo WHERE id < ? ORDER BY id", []interface{}{3}, s.schema) {
+		s.NoError(err)
+		got = append(got, row)
+	}
+	expect := [][]interface{}{
+		{float64(1), "a"},
+		{float64(2), "a"},
+	}
+	s.Equal(expect, got)
+
+	// Breaking out early should leave the connection usable
+	count := 0
+	for _, err := range exa.Query("SELECT * FROM foo ORDER BY id", nil, s.schema) {
+		s.NoError(err)
+		count++
+		if count == 10 {
+			break
+		}
+	}
+	s.Equal(10, count)
+	val, err := exa.FetchOne("SELECT COUNT(*) FROM foo", nil, s.schema)
+	s.NoError(err)
+	s.Equal(float64(2500), val)
+
+	exa.Conf.SuppressError = true
+	for row, err := range exa.Query("ASDF") {
+		s.Nil(row)
+		if s.Error(err) {
+			s.Contains(err.Error(), "syntax error")
+		}
+	}
+}
+
+func (s *testSuite) TestQueryStruct() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( user_id INT, val CHAR(1), other VARCHAR(10) )")
+	exa.Execute("INSERT INTO foo VALUES (1,'a','x'),(2,NULL,'y')")
+
+	type foo struct {
+		UserID int64
+		Value  *string `exasol:"val"`
+		Skip   string  `exasol:"-"`
+	}
+	var got []foo
+	for row, err := range QueryStruct[foo](exa, "SELECT * FROM foo ORDER BY user_id", nil, s.schema) {
+		s.NoError(err)
+		got = append(got, row)
+	}
+	a := "a"
+	s.Equal([]foo{{UserID: 1, Value: &a}, {UserID: 2}}, got)
+
+	exa.Conf.SuppressError = true
+	for _, err := range QueryStruct[int](exa, "SELECT * FROM foo", nil, s.schema) {
+		if s.Error(err) {
+			s.Contains(err.Error(), "not a struct")
+		}
+	}
+}
+
+func (s *testSuite) TestFetchRows() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT, val VARCHAR(10), ok BOOLEAN, d DATE, ts TIMESTAMP )")
+	exa.Execute(`INSERT INTO foo VALUES
+		(1, 'a', TRUE, '2020-01-31', '2020-01-31 12:34:56.789'),
+		(2, NULL, FALSE, NULL, NULL)`)
+
+	rows, err := exa.FetchRows("SELECT * FROM foo ORDER BY id", nil, s.schema)
+	if !s.NoError(err) {
+		return
+	}
+	defer rows.Close()
+	s.Equal([]string{"ID", "VAL", "OK", "D", "TS"}, rows.Columns())
+	cols := rows.ColumnTypes()
+	s.Equal("DECIMAL", cols[0].DataType.Type)
+	s.Equal("VARCHAR", cols[1].DataType.Type)
+	s.Equal("BOOLEAN", cols[2].DataType.Type)
+
+	var id int64
+	var val sql.NullString
+	var ok bool
+	var d, ts time.Time
+	s.True(rows.Next())
+	s.NoError(rows.Scan(&id, &val, &ok, &d, &ts))
+	s.Equal(int64(1), id)
+	s.Equal(sql.NullString{String: "a", Valid: true}, val)
+	s.True(ok)
+	s.Equal(time.Date(2020, 1, 31, 0, 0, 0, 0, time.Local), d, "Local without TimestampUTC")
+	s.Equal(time.Date(2020, 1, 31, 12, 34, 56, 789000000, time.Local), ts, "Local without TimestampUTC")
+
+	s.Equal([]bool{false, false, false, false, false}, rows.Nulls())
+
+	var raw interface{}
+	s.True(rows.Next())
+	s.NoError(rows.Scan(&id, &val, &ok, &raw, &ts))
+	s.Equal([]bool{false, true, false, true, true}, rows.Nulls())
+	s.True(rows.IsNull(1))
+	s.False(rows.IsNull(0))
+	s.Equal(int64(2), id)
+	s.False(val.Valid)
+	s.Nil(raw)
+	s.True(ts.IsZero())
+
+	exa.Conf.SuppressError = true
+	err = rows.Scan(&id)
+	if s.Error(err) {
+		s.Contains(err.Error(), "expected 5 destination arguments")
+	}
+
+	s.False(rows.Next())
+	s.False(rows.Next(), "Still done")
+}
+
+func (s *testSuite) TestCursor() {
+	exa := s.exaConn
+	exa.Execute("CREATE TABLE foo ( id INT )")
+	exa.Execute("INSERT INTO foo SELECT level FROM dual CONNECT BY level <= 2500")
+
+	cur, err := exa.OpenCursor("SELECT id FROM foo ORDER BY id", nil, s.schema)
+	if !s.NoError(err) {
+		return
+	}
+	s.Equal(uint64(2500), cur.NumRows())
+	s.Equal([]string{"ID"}, cur.Columns())
+
+	page, err := cur.Page(0, 2)
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(1)}, {float64(2)}}, page)
+
+	// Other statements can run while the cursor is open
+	_, err = exa.Execute("SELECT 1")
+	s.NoError(err)
+
+	page, err = cur.Page(2000, 3)
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(2001)}, {float64(2002)}, {float64(2003)}}, page)
+
+	page, err = cur.Page(2499, 10)
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(2500)}}, page, "Last page is short")
+
+	page, err = cur.Page(3000, 10)
+	s.NoError(err)
+	s.Empty(page)
+
+	s.NoError(cur.Close())
+	s.NoError(cur.Close(), "Can close twice")
+	exa.Conf.SuppressError = true
+	_, err = cur.Page(0, 1)
+	s.Error(err)
+
+	page, err = exa.FetchPage("SELECT id FROM foo ORDER BY id", 10, 2, nil, s.schema)
+	s.NoError(err)
+	s.Equal([][]interface{}{{float64(11)}, {float64(12)}}, page)
+}
+
+// Fails every fetch with a server error
+type fetchErrWSHandler struct{ testWSHandler }
+
+func (wsh *fetchErrWSHandler) ReadJSON(resp interface{}) error {
+	switch r := resp.(type) {
+	case *fetchRes:
+		r.Status = "error"
+		r.Exception = &exception{Text: "fetch failed", Sqlcode: "00000"}
+	case *execRes:
+		r.Status = "ok"
+		r.ResponseData = &execData{NumResults: 1, Results: []result{{
+			ResultType: resultSetType,
+			ResultSet:  &resultSet{ResultSetHandle: 1, NumColumns: 1, NumRows: 10, Columns: []column{{Name: "A"}}},
+		}}}
+	case *response:
+		r.Status = "ok"
+	}
+	return nil
+}
+
+func (s *testSuite) TestFetchError() {
+	c := &Conn{
+		Conf: ConnConf{SuppressError: true},
+		wsh:  &fetchErrWSHandler{},
+		log:  newDefaultLogger(),
+	}
+	rs := &resultSet{ResultSetHandle: 1, NumRows: 10}
+	ch := make(chan []interface{}, 10)
+	err := c.resultsToChan(rs, ch, FetchOpts{}, nil)
+	s.EqualError(err, "Unable to Fetch: Server Error: fetch failed")
+	s.ErrorAs(err, new(*ServerError))
+	s.Equal(err, rs.fetchErr)
+	_, open := <-ch
+	s.False(open)
+
+	rows := &ResultRows{conn: c, rs: rs, ch: ch, done: make(chan struct{})}
+	s.False(rows.Next())
+	s.Equal(err, rows.Err())
+}
+
+// Fails fetches asking for more than limit bytes as being too large
+type tooLargeWSHandler struct {
+	testWSHandler
+	limit    int
+	numBytes []int
+}
+
+func (wsh *tooLargeWSHandler) WriteJSON(req interface{}) error {
+	if r, ok := req.(*fetchReq); ok {
+		wsh.numBytes = append(wsh.numBytes, r.NumBytes)
+	}
+	return nil
+}
+
+func (wsh *tooLargeWSHandler) ReadJSON(resp interface{}) error {
+	switch r := resp.(type) {
+	case *fetchRes:
+		if wsh.numBytes[len(wsh.numBytes)-1] > wsh.limit {
+			return errResponseTooLarge
+		}
+		r.Status = "ok"
+		r.ResponseData = &fetchData{NumRows: 1, Data: [][]interface{}{{"a"}}}
+	case *response:
+		r.Status = "ok"
+	}
+	return nil
+}
+
+func (s *testSuite) TestFetchTooLarge() {
+	wsh := &tooLargeWSHandler{limit: 200 * 1024}
+	c := &Conn{Conf: ConnConf{SuppressError: true}, wsh: wsh, log: newDefaultLogger()}
+	rs := &resultSet{ResultSetHandle: 1, NumRows: 2, Columns: []column{{Name: "A"}}}
+	ch := make(chan []interface{}, 10)
+	s.NoError(c.resultsToChan(rs, ch, FetchOpts{MaxFetchBytes: 1024 * 1024}, nil))
+	s.Len(ch, 2)
+	s.Equal([]int{1024 * 1024, 512 * 1024, 256 * 1024, 128 * 1024, 128 * 1024}, wsh.numBytes,
+		"Retried in smaller chunks from then on")
+
+	wsh.limit, wsh.numBytes = 1024, nil
+	rs = &resultSet{ResultSetHandle: 1, NumRows: 2, Columns: []column{{Name: "A"}}}
+	err := c.resultsToChan(rs, make(chan []interface{}, 10), FetchOpts{MaxFetchBytes: 128 * 1024}, nil)
+	s.ErrorAs(err, new(*ResponseTooLargeError), "Still too large at the minimum")
+	s.Equal([]int{128 * 1024, minFetchBytes}, wsh.numBytes)
+}
+
+// Returns a row per fetch recording the bytes requested
+type pageWSHandler struct {
+	testWSHandler
+	numBytes []int
+}
+
+func (wsh *pageWSHandler) WriteJSON(req interface{}) error {
+	if r, ok := req.(*fetchReq); ok {
+		wsh.numBytes = append(wsh.numBytes, r.NumBytes)
+	}
+	return nil
+}
+
+func (wsh *pageWSHandler) ReadJSON(resp interface{}) error {
+	r := resp.(*fetchRes)
+	r.Status = "ok"
+	r.ResponseData = &fetchData{NumRows: 1, Data: [][]interface{}{{"a"}, {"b"}}}
+	return nil
+}
+
+func (s *testSuite) TestCursorPageSize() {
+	wsh := &pageWSHandler{}
+	c := &Conn{wsh: wsh, log: newDefaultLogger()}
+	cols := []column{{Name: "A"}, {Name: "B"}}
+	cur := &Cursor{conn: c, rs: &resultSet{ResultSetHandle: 1, NumRows: 1e6, Columns: cols}}
+
+	rows, err := cur.Page(0, 3)
+	s.NoError(err)
+	s.Len(rows, 3)
+	s.Equal([]int{minPageBytes, 2 * minPageBytes, 4 * minPageBytes}, wsh.numBytes)
+
+	wsh.numBytes = nil
+	_, err = cur.Page(0, 1000)
+	s.NoError(err)
+	s.Equal(1000*2*pageBytesPerValue, wsh.numBytes[0])
+
+	wsh.numBytes = nil
+	cur.opts = FetchOpts{Prefetch: 100, MaxFetchBytes: 150}
+	_, err = cur.Page(0, 2)
+	s.NoError(err)
+	s.Equal([]int{100, 150}, wsh.numBytes)
+}
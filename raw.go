@@ -0,0 +1,99 @@
+/*
+	Low level access to the websocket API for commands
+	that this library doesn't wrap (yet)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// RawCommand sends req (anything that marshals to a JSON object with a
+// "command" key) and unmarshals the whole response into resp (if non-nil).
+// See https://github.com/exasol/websocket-api for the available commands.
+//
+// The optional 3rd arg is either an *Attributes or a map[string]interface{}
+// (for sending false/zero values) which is merged into the request's
+// "attributes". A response with a non-ok status is returned as a
+// *ServerError. As with the other methods you should Lock the Conn
+// if it is shared.
+func (c *Conn) RawCommand(req interface{}, resp interface{}, args ...interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return c.errorf("Unable to marshal raw command: %s", err)
+	}
+	request := map[string]interface{}{}
+	err = json.Unmarshal(b, &request)
+	if err != nil {
+		return c.errorf("Raw command must be a JSON object: %s", err)
+	}
+	if _, ok := request["command"].(string); !ok {
+		return c.error("Raw command must have a \"command\" key")
+	}
+
+	if len(args) > 0 && args[0] != nil {
+		var attrs map[string]interface{}
+		switch a := args[0].(type) {
+		case *Attributes, map[string]interface{}:
+			b, _ := json.Marshal(a)
+			json.Unmarshal(b, &attrs)
+		default:
+			return c.error("RawCommand's 3rd param (attributes) must be *Attributes or map[string]interface{}")
+		}
+		existing, _ := request["attributes"].(map[string]interface{})
+		if existing == nil {
+			existing = map[string]interface{}{}
+		}
+		for k, v := range attrs {
+			existing[k] = v
+		}
+		request["attributes"] = existing
+	}
+
+	c.log.Debug("Raw command: ", string(b))
+	res := &rawResponse{}
+	err = c.send(request, res)
+	if err != nil {
+		var serverErr *ServerError
+		if errors.As(err, &serverErr) {
+			if !c.Conf.SuppressError {
+				c.log.Error(serverErr)
+			}
+			return serverErr
+		}
+		return c.errorf("Unable to send raw command: %s", err)
+	}
+	if resp != nil {
+		err = json.Unmarshal(res.raw, resp)
+		if err != nil {
+			return c.errorf("Unable to unmarshal raw response: %s", err)
+		}
+	}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+// Holds onto the raw JSON while still exposing
+// the status/exception for error handling
+type rawResponse struct {
+	response
+	raw []byte
+}
+
+func (r *rawResponse) UnmarshalJSON(b []byte) error {
+	r.raw = append(r.raw[:0], b...)
+	return json.Unmarshal(b, &r.response)
+}
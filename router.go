@@ -0,0 +1,228 @@
+/*
+	Routing tenants to their schemas (and optionally their own connections)
+	for multi-tenant applications that shard tenants by schema
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+var (
+	ErrUnknownTenant = errors.New("Unknown tenant")
+	ErrQuotaExceeded = errors.New("Quota exceeded")
+)
+
+// Router maps tenant keys to ScopedConns. It's safe for concurrent use
+// since the ScopedConns it returns lock their connection (see Conn.Lock)
+// around each statement (including fetching its rows in the background)
+// so they mustn't be used while holding that lock.
+type Router struct {
+	conn    *Conn
+	conf    RouterConf
+	mux     sync.RWMutex
+	tenants map[string]*tenant
+}
+
+type RouterConf struct {
+	// If set this is called before each of a tenant's statements with its
+	// statistics so far. Returning an error rejects the statement
+	// (wrapped in an ErrQuotaExceeded). It's called with the tenant's
+	// statistics locked (so that concurrent statements can't all slip
+	// under the quota) hence it must return quickly and not use the Router.
+	Quota func(key string, stats TenantStats) error
+	// If set this is called after each of a tenant's statements (while
+	// its connection is still locked)
+	OnStatement func(key string, duration time.Duration, err error)
+}
+
+// Tenant is where a tenant's statements are routed
+type Tenant struct {
+	Schema string
+	Conn   *Conn // Defaults to the Router's connection
+}
+
+// TenantStats are a tenant's statement statistics since it was added
+// (or since ResetStats)
+type TenantStats struct {
+	Statements int64 // Including those that failed or are still running
+	Errors     int64
+	Rejected   int64 // By RouterConf.Quota (not included in Statements)
+	Duration   time.Duration
+	LastUsed   time.Time
+}
+
+// NewRouter returns a Router whose tenants default to using the conn
+func NewRouter(conn *Conn, conf RouterConf) *Router {
+	return &Router{conn: conn, conf: conf, tenants: map[string]*tenant{}}
+}
+
+// AddTenant adds (or replaces) the tenant with the key. A replaced
+// tenant's statistics are kept.
+func (r *Router) AddTenant(key string, t Tenant) error {
+	if key == "" || t.Schema == "" {
+		return fmt.Errorf("You must pass in a key and schema to AddTenant")
+	}
+	if t.Conn == nil {
+		t.Conn = r.conn
+	}
+	if t.Conn == nil {
+		return fmt.Errorf("Tenant %s has no connection (nor does the Router)", key)
+	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if old, ok := r.tenants[key]; ok {
+		old.mux.Lock()
+		defer old.mux.Unlock()
+		old.Tenant = t
+		return nil
+	}
+	r.tenants[key] = &tenant{Tenant: t, key: key, router: r}
+	return nil
+}
+
+// RemoveTenant removes the tenant. ScopedConns already returned by For
+// continue to work.
+func (r *Router) RemoveTenant(key string) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	delete(r.tenants, key)
+}
+
+// Tenants returns the tenants' keys in order
+func (r *Router) Tenants() []string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return sortedKeys(r.tenants)
+}
+
+// For returns a ScopedConn for the tenant's schema whose statements are
+// subject to the RouterConf.Quota and are included in the tenant's stats.
+func (r *Router) For(key string) (*ScopedConn, error) {
+	t, err := r.tenant(key)
+	if err != nil {
+		return nil, err
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return &ScopedConn{conn: t.Conn, schema: t.Schema, tenant: t}, nil
+}
+
+// Stats returns the tenant's statistics
+func (r *Router) Stats(key string) (TenantStats, error) {
+	t, err := r.tenant(key)
+	if err != nil {
+		return TenantStats{}, err
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	return t.stats, nil
+}
+
+// AllStats returns every tenant's statistics keyed by tenant
+func (r *Router) AllStats() map[string]TenantStats {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	all := make(map[string]TenantStats, len(r.tenants))
+	for key, t := range r.tenants {
+		t.mux.Lock()
+		all[key] = t.stats
+		t.mux.Unlock()
+	}
+	return all
+}
+
+// ResetStats zeroes the tenant's statistics e.g. at the start of a quota period
+func (r *Router) ResetStats(key string) error {
+	t, err := r.tenant(key)
+	if err != nil {
+		return err
+	}
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.stats = TenantStats{}
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+type tenant struct {
+	Tenant
+	key    string
+	router *Router
+	mux    sync.Mutex
+	stats  TenantStats
+}
+
+func (r *Router) tenant(key string) (*tenant, error) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	t, ok := r.tenants[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, key)
+	}
+	return t, nil
+}
+
+// Runs the statement subject to the quota, recording its statistics.
+// The lock isn't held while it's running so that the quota and stats
+// of a tenant spread over several connections don't serialize them.
+func (t *tenant) run(stmt func() error) error {
+	start, err := t.begin()
+	if err != nil {
+		return err
+	}
+	err = stmt()
+	t.end(start, err)
+	return err
+}
+
+// Checks the quota and counts the statement returning when it started.
+// It's counted before it runs so that concurrent statements see it when
+// checking the quota.
+func (t *tenant) begin() (time.Time, error) {
+	conf := t.router.conf
+	start := time.Now()
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if conf.Quota != nil {
+		if err := conf.Quota(t.key, t.stats); err != nil {
+			t.stats.Rejected++
+			return start, fmt.Errorf("%w for tenant %s: %w", ErrQuotaExceeded, t.key, err)
+		}
+	}
+	t.stats.Statements++
+	t.stats.LastUsed = start
+	return start, nil
+}
+
+// Records the outcome of a statement begun at start
+func (t *tenant) end(start time.Time, err error) {
+	duration := time.Since(start)
+	t.mux.Lock()
+	if err != nil {
+		t.stats.Errors++
+	}
+	t.stats.Duration += duration
+	t.mux.Unlock()
+
+	if conf := t.router.conf; conf.OnStatement != nil {
+		conf.OnStatement(t.key, duration, err)
+	}
+}
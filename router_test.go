@@ -0,0 +1,216 @@
+package exasol
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+func (s *testSuite) TestRouterTenants() {
+	r := NewRouter(s.exaConn, RouterConf{})
+	s.EqualError(r.AddTenant("", Tenant{Schema: "x"}), "You must pass in a key and schema to AddTenant")
+	s.EqualError(
+		NewRouter(nil, RouterConf{}).AddTenant("a", Tenant{Schema: "x"}),
+		"Tenant a has no connection (nor does the Router)",
+	)
+	s.NoError(r.AddTenant("b", Tenant{Schema: "SB"}))
+	s.NoError(r.AddTenant("a", Tenant{Schema: "SA"}))
+	s.Equal([]string{"a", "b"}, r.Tenants())
+
+	sc, err := r.For("a")
+	s.NoError(err)
+	s.Equal("SA", sc.Schema())
+	s.Equal(s.exaConn, sc.Conn())
+
+	_, err = r.For("c")
+	s.ErrorIs(err, ErrUnknownTenant)
+	_, err = r.Stats("c")
+	s.ErrorIs(err, ErrUnknownTenant)
+
+	r.RemoveTenant("b")
+	s.Equal([]string{"a"}, r.Tenants())
+}
+
+func (s *testSuite) TestRouterQuota() {
+	var seen []string
+	r := NewRouter(s.exaConn, RouterConf{
+		Quota: func(key string, stats TenantStats) error {
+			if stats.Statements >= 2 {
+				return errors.New("2 statements max")
+			}
+			return nil
+		},
+		OnStatement: func(key string, d time.Duration, err error) { seen = append(seen, key) },
+	})
+	s.NoError(r.AddTenant("a", Tenant{Schema: "SA"}))
+	sc, _ := r.For("a")
+
+	s.NoError(sc.run(func() error { return nil }))
+	s.EqualError(sc.run(func() error { return errors.New("oops") }), "oops")
+	err := sc.run(func() error { return nil })
+	s.ErrorIs(err, ErrQuotaExceeded)
+	s.EqualError(err, "Quota exceeded for tenant a: 2 statements max")
+
+	stats, err := r.Stats("a")
+	s.NoError(err)
+	s.Equal(int64(2), stats.Statements)
+	s.Equal(int64(1), stats.Errors)
+	s.Equal(int64(1), stats.Rejected)
+	s.False(stats.LastUsed.IsZero())
+	s.Equal([]string{"a", "a"}, seen)
+	s.Equal(map[string]TenantStats{"a": stats}, r.AllStats())
+
+	// Replacing a tenant keeps its stats whereas resetting them lifts the quota
+	s.NoError(r.AddTenant("a", Tenant{Schema: "SA2"}))
+	stats, _ = r.Stats("a")
+	s.Equal(int64(2), stats.Statements)
+	s.NoError(r.ResetStats("a"))
+	sc, _ = r.For("a")
+	s.Equal("SA2", sc.Schema())
+	s.NoError(sc.run(func() error { return nil }))
+}
+
+func (s *testSuite) TestRouterConcurrentQuota() {
+	r := NewRouter(&Conn{}, RouterConf{
+		Quota: func(key string, stats TenantStats) error {
+			if stats.Statements >= 1 {
+				return errors.New("1 statement max")
+			}
+			return nil
+		},
+	})
+	s.NoError(r.AddTenant("a", Tenant{Schema: "SA"}))
+	s.NoError(r.AddTenant("b", Tenant{Schema: "SB", Conn: &Conn{}}))
+	a, _ := r.For("a")
+	b, _ := r.For("b")
+
+	running, finish := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- a.run(func() error {
+			close(running)
+			<-finish
+			return nil
+		})
+	}()
+	<-running
+	s.ErrorIs(a.tenant.run(func() error { return nil }), ErrQuotaExceeded, "Counted while running")
+
+	// Another tenant's connection isn't held up
+	s.NoError(b.run(func() error { return nil }))
+
+	s.False(a.Conn().TryLock(), "Connection locked while running")
+	close(finish)
+	s.NoError(<-done)
+	s.True(a.Conn().TryLock(), "Unlocked afterwards")
+	a.Conn().Unlock()
+
+	stats, _ := r.Stats("a")
+	s.Equal(int64(1), stats.Statements)
+	s.Equal(int64(1), stats.Rejected)
+}
+
+// Answers executes with a 3 row result set (or a row count for
+// INSERTs) and fetches slowly a row at a time, noting whether
+// requests were interleaved with one another's responses
+type routedWSHandler struct {
+	testWSHandler
+	mux         sync.Mutex
+	pending     []interface{}
+	commands    []string
+	interleaved bool
+}
+
+func (wsh *routedWSHandler) WriteJSON(req interface{}) error {
+	wsh.mux.Lock()
+	defer wsh.mux.Unlock()
+	if len(wsh.pending) > 0 {
+		wsh.interleaved = true
+	}
+	wsh.pending = append(wsh.pending, req)
+	switch r := req.(type) {
+	case *execReq:
+		wsh.commands = append(wsh.commands, r.SqlText)
+	case *fetchReq:
+		wsh.commands = append(wsh.commands, r.Command)
+	}
+	return nil
+}
+
+func (wsh *routedWSHandler) ReadJSON(resp interface{}) error {
+	wsh.mux.Lock()
+	req := wsh.pending[0]
+	wsh.pending = wsh.pending[1:]
+	wsh.mux.Unlock()
+	switch r := resp.(type) {
+	case *execRes:
+		r.Status = "ok"
+		res := result{ResultType: rowCountType, RowCount: 1}
+		if req.(*execReq).SqlText == "SELECT" {
+			res = result{ResultType: resultSetType, ResultSet: &resultSet{
+				ResultSetHandle: 1, NumColumns: 1, NumRows: 3, Columns: []column{{Name: "A"}},
+			}}
+		}
+		r.ResponseData = &execData{NumResults: 1, Results: []result{res}}
+	case *fetchRes:
+		time.Sleep(10 * time.Millisecond)
+		r.Status = "ok"
+		r.ResponseData = &fetchData{NumRows: 1, Data: [][]interface{}{{"a"}}}
+	case *response:
+		r.Status = "ok"
+	}
+	return nil
+}
+
+func (s *testSuite) TestRouterFetchChanLocks() {
+	wsh := &routedWSHandler{}
+	c := &Conn{wsh: wsh, log: newDefaultLogger()}
+	r := NewRouter(c, RouterConf{})
+	s.NoError(r.AddTenant("a", Tenant{Schema: "SA"}))
+	s.NoError(r.AddTenant("b", Tenant{Schema: "SB"}))
+	a, _ := r.For("a")
+	b, _ := r.For("b")
+
+	ch, err := a.FetchChan("SELECT")
+	if !s.NoError(err) {
+		return
+	}
+	executed := make(chan error)
+	go func() {
+		_, err := b.Execute("INSERT")
+		executed <- err
+	}()
+	rows := 0
+	for range ch {
+		rows++
+	}
+	s.Equal(3, rows)
+	s.NoError(<-executed)
+
+	s.False(wsh.interleaved, "Requests weren't interleaved")
+	s.Equal("INSERT", wsh.commands[len(wsh.commands)-1], "Waited for the fetches")
+	statsA, _ := r.Stats("a")
+	s.Equal(int64(1), statsA.Statements)
+	s.Positive(statsA.Duration, "Including the fetches")
+}
+
+func (s *testSuite) TestRouter() {
+	s.execute("CREATE TABLE foo (id INT)")
+	r := NewRouter(s.exaConn, RouterConf{})
+	s.NoError(r.AddTenant("t1", Tenant{Schema: s.schema}))
+	sc, err := r.For("t1")
+	s.NoError(err)
+
+	_, err = sc.Execute("INSERT INTO foo VALUES (?)", []interface{}{1})
+	s.NoError(err)
+	n, err := sc.Count("SELECT COUNT(*) FROM foo")
+	s.NoError(err)
+	s.Equal(int64(1), n)
+	_, err = sc.FetchSlice("SELECT * FROM nope")
+	s.Error(err)
+
+	stats, _ := r.Stats("t1")
+	s.Equal(int64(3), stats.Statements)
+	s.Equal(int64(1), stats.Errors)
+	s.Positive(stats.Duration)
+}
@@ -0,0 +1,119 @@
+/*
+	Previewing and sampling tables e.g. for data catalogs and debugging
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// TableSample is the rows returned by Preview or Sample along with their
+// columns. The values are typed according to their columns (regardless of
+// ConnConf.TypedInts etc.): DECIMALs with a scale of 0 and a precision
+// <= 18 are int64s, other DECIMALs json.Numbers (so they're exact),
+// DOUBLEs float64s, DATEs and TIMESTAMPs time.Times (assuming the
+// session's default formats), BOOLEANs bools, everything else strings
+// and NULLs nil.
+type TableSample struct {
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// Preview returns (up to) the first n rows of the table in no particular
+// order. The schema and table are quoted as per QuoteIdent but anything
+// already quoted is re-quoted so that the names can't inject SQL.
+func (c *Conn) Preview(schema, table string, n int) (*TableSample, error) {
+	if schema == "" || table == "" {
+		return nil, fmt.Errorf("You must pass in a schema and table to Preview")
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("Preview's n can't be negative")
+	}
+	return c.fetchSample(fmt.Sprintf(
+		"SELECT * FROM %s LIMIT %d", c.exactTableName(schema, table), n,
+	))
+}
+
+// Sample returns a random sample of roughly percent (0-100) of the
+// table's rows. Each row is included independently so the size of the
+// sample varies from call to call.
+func (c *Conn) Sample(schema, table string, percent float64) (*TableSample, error) {
+	if schema == "" || table == "" {
+		return nil, fmt.Errorf("You must pass in a schema and table to Sample")
+	}
+	if !(percent > 0 && percent <= 100) {
+		return nil, fmt.Errorf("Sample's percent must be above 0 and at most 100")
+	}
+	sql := "SELECT * FROM " + c.exactTableName(schema, table)
+	if percent < 100 {
+		sql += fmt.Sprintf(" WHERE RANDOM() < %g", percent/100)
+	}
+	return c.fetchSample(sql)
+}
+
+/*--- Private Routines ---*/
+
+// Returns the schema.table quoted exactly as it's stored
+func (c *Conn) exactTableName(schema, table string) string {
+	return quoteExact(c.storedName(schema)) + "." + quoteExact(c.storedName(table))
+}
+
+func (c *Conn) fetchSample(sql string) (*TableSample, error) {
+	opts := c.Conf.FetchOpts
+	opts.keepNumbers = true
+	opts.RowPool = nil // The rows are retained
+	ch, rs, err := c.fetch(sql, []interface{}{nil, nil, opts}, nil)
+	if err != nil {
+		return nil, err
+	}
+	sample := &TableSample{Columns: exportColumns(rs.Columns), Rows: [][]interface{}{}}
+	for row := range ch {
+		for i, val := range row {
+			row[i] = typedValue(val, sample.Columns[i].DataType, c.timeLoc())
+		}
+		sample.Rows = append(sample.Rows, row)
+	}
+	if rs.fetchErr != nil {
+		return nil, rs.fetchErr
+	}
+	return sample, nil
+}
+
+// Converts the raw value to the Go type matching the column's
+func typedValue(val interface{}, dt DataType, loc *time.Location) interface{} {
+	switch v := val.(type) {
+	case json.Number:
+		switch {
+		case dt.Type == "DOUBLE":
+			f, _ := v.Float64()
+			return f
+		case dt.Type == "DECIMAL" && dt.Scale == 0 && dt.Precision <= 18:
+			if n, err := v.Int64(); err == nil {
+				return n
+			}
+		}
+	case string:
+		switch dt.Type {
+		case "DATE", "TIMESTAMP", "TIMESTAMP WITH LOCAL TIME ZONE":
+			if t, err := parseTime(v, loc); err == nil {
+				return t
+			}
+		}
+	}
+	return val
+}
@@ -0,0 +1,59 @@
+package exasol
+
+import (
+	"encoding/json"
+	"time"
+)
+
+func (s *testSuite) TestTypedValue() {
+	dec := func(p, sc int) DataType { return DataType{Type: "DECIMAL", Precision: p, Scale: sc} }
+	s.Equal(int64(42), typedValue(json.Number("42"), dec(18, 0), time.UTC))
+	s.Equal(json.Number("123456789012345678901"), typedValue(json.Number("123456789012345678901"), dec(36, 0), time.UTC))
+	s.Equal(json.Number("1.50"), typedValue(json.Number("1.50"), dec(10, 2), time.UTC))
+	s.Equal(1.5, typedValue(json.Number("1.5"), DataType{Type: "DOUBLE"}, time.UTC))
+	s.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC), typedValue("2020-01-02", DataType{Type: "DATE"}, time.UTC))
+	est := time.FixedZone("EST", -5*60*60)
+	s.Equal(
+		time.Date(2020, 1, 2, 3, 4, 5, 6e6, est),
+		typedValue("2020-01-02 03:04:05.006000", DataType{Type: "TIMESTAMP"}, est),
+	)
+	s.Equal("2020-01-02", typedValue("2020-01-02", DataType{Type: "VARCHAR"}, time.UTC))
+	s.Equal(true, typedValue(true, DataType{Type: "BOOLEAN"}, time.UTC))
+	s.Nil(typedValue(nil, dec(18, 0), time.UTC))
+}
+
+func (s *testSuite) TestPreviewAndSample() {
+	s.execute(`CREATE TABLE "odd ""name""" (id DECIMAL(18,0), amt DECIMAL(10,2), d DATE, ok BOOLEAN)`)
+	s.execute(`INSERT INTO "odd ""name""" SELECT i, i / 4, ADD_DAYS(DATE '2020-01-01', i), MOD(i, 2) = 0
+		FROM VALUES BETWEEN 1 AND 100 AS v(i)`)
+
+	p, err := s.exaConn.Preview(s.schema, `"odd ""name"""`, 3)
+	s.NoError(err)
+	if s.Len(p.Rows, 3) && s.Len(p.Columns, 4) {
+		s.Equal("AMT", p.Columns[1].Name)
+		s.IsType(int64(0), p.Rows[0][0])
+		s.IsType(json.Number(""), p.Rows[0][1])
+		s.IsType(time.Time{}, p.Rows[0][2])
+		s.IsType(true, p.Rows[0][3])
+	}
+
+	p, err = s.exaConn.Preview(s.schema, `"odd ""name"""`, 0)
+	s.NoError(err)
+	s.Empty(p.Rows)
+	s.Len(p.Columns, 4)
+
+	all, err := s.exaConn.Sample(s.schema, `"odd ""name"""`, 100)
+	s.NoError(err)
+	s.Len(all.Rows, 100)
+	some, err := s.exaConn.Sample(s.schema, `"odd ""name"""`, 50)
+	s.NoError(err)
+	s.Less(len(some.Rows), 100)
+
+	// Quoting can't be escaped
+	_, err = s.exaConn.Preview(s.schema, `"x"; DROP TABLE foo; --"`, 1)
+	s.Error(err)
+	_, err = s.exaConn.Sample(s.schema, "foo", 0)
+	s.EqualError(err, "Sample's percent must be above 0 and at most 100")
+	_, err = s.exaConn.Preview(s.schema, "foo", -1)
+	s.EqualError(err, "Preview's n can't be negative")
+}
@@ -0,0 +1,134 @@
+/*
+	A priority aware lock for coordinating use of a Conn
+	that is shared across multiple Go routines
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type Priority int
+
+const (
+	PriorityBatch       Priority = iota // Bulk jobs that can wait
+	PriorityNormal                      // What Lock uses
+	PriorityInteractive                 // Lookups where latency matters
+	numPriorities
+)
+
+// Waiters are woken highest priority first and in
+// arrival order within a priority. The zero value is unlocked.
+type scheduler struct {
+	mux     sync.Mutex
+	held    bool
+	waiters [numPriorities][]chan struct{}
+}
+
+// LockPriority is like Lock but if the handle is in use the caller is
+// queued behind only those waiting at the same or a higher priority.
+// So an interactive lookup sharing a connection with a batch job
+// waits for the current statement rather than for the whole queue.
+func (c *Conn) LockPriority(p Priority) { c.sched.lock(p); c.guardLocked() }
+
+// TryLock gets the lock on the handle if it's free
+// and returns false rather than waiting if it isn't
+func (c *Conn) TryLock() bool {
+	if !c.sched.tryLock() {
+		return false
+	}
+	c.guardLocked()
+	return true
+}
+
+// LockContext is like Lock but gives up waiting once ctx is done returning
+// its cause (e.g. context.DeadlineExceeded) so that callers can report
+// contention rather than blocking forever e.g. during shutdown.
+func (c *Conn) LockContext(ctx context.Context) error {
+	if err := c.sched.lockContext(ctx, PriorityNormal); err != nil {
+		return err
+	}
+	c.guardLocked()
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+func (s *scheduler) lock(p Priority) {
+	s.lockContext(context.Background(), p)
+}
+
+func (s *scheduler) lockContext(ctx context.Context, p Priority) error {
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+	if p < PriorityBatch {
+		p = PriorityBatch
+	} else if p >= numPriorities {
+		p = numPriorities - 1
+	}
+	s.mux.Lock()
+	if !s.held {
+		s.held = true
+		s.mux.Unlock()
+		return nil
+	}
+	ch := make(chan struct{})
+	s.waiters[p] = append(s.waiters[p], ch)
+	s.mux.Unlock()
+
+	select {
+	case <-ch: // The lock is handed over directly by unlock
+		return nil
+	case <-ctx.Done():
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	i := slices.Index(s.waiters[p], ch)
+	if i < 0 {
+		return nil // It was handed over just as ctx was done
+	}
+	s.waiters[p] = slices.Delete(s.waiters[p], i, i+1)
+	return context.Cause(ctx)
+}
+
+// Returns false rather than waiting if it's already locked
+func (s *scheduler) tryLock() bool {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.held {
+		return false
+	}
+	s.held = true
+	return true
+}
+
+func (s *scheduler) unlock() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if !s.held {
+		panic("exasol: unlock of unlocked Conn")
+	}
+	for p := numPriorities - 1; p >= PriorityBatch; p-- {
+		if len(s.waiters[p]) > 0 {
+			ch := s.waiters[p][0]
+			s.waiters[p] = s.waiters[p][1:]
+			close(ch)
+			return
+		}
+	}
+	s.held = false
+}
@@ -0,0 +1,366 @@
+/*
+	Comparing a schema's tables, columns and constraints between two
+	databases (e.g. staging and production) and generating the DDL
+	to bring one in line with the other
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// ChangeType is the kind of a schema Change. The Changes returned by
+// DiffSchemas are ordered by their type (in the order below) so that
+// e.g. constraints are dropped before the columns they're on.
+type ChangeType int
+
+const (
+	ChangeCreateSchema ChangeType = iota
+	ChangeDropConstraint
+	ChangeDropTable
+	ChangeCreateTable
+	ChangeAddColumn
+	ChangeModifyColumn
+	ChangeDropColumn
+	ChangeAddConstraint
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeCreateSchema:
+		return "CREATE SCHEMA"
+	case ChangeDropConstraint:
+		return "DROP CONSTRAINT"
+	case ChangeDropTable:
+		return "DROP TABLE"
+	case ChangeCreateTable:
+		return "CREATE TABLE"
+	case ChangeAddColumn:
+		return "ADD COLUMN"
+	case ChangeModifyColumn:
+		return "MODIFY COLUMN"
+	case ChangeDropColumn:
+		return "DROP COLUMN"
+	case ChangeAddConstraint:
+		return "ADD CONSTRAINT"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(t))
+}
+
+// Change is a DDL statement that DiffSchemas found to be needed
+type Change struct {
+	Type   ChangeType
+	Table  string // The table changed (empty for ChangeCreateSchema)
+	Object string // The column or constraint changed (if any)
+	SQL    string
+}
+
+// DiffSchemas compares the schema's tables (their columns' types,
+// defaults and nullability along with their primary and foreign keys) in
+// connA's database to those in connB's and returns the changes that would
+// make connB's match, in the order they should be run against connB.
+//
+// Constraints are compared by their definitions rather than their names
+// since those are generated unless given explicitly. Columns are matched
+// by name so renames appear as a drop plus an add. Tables and columns only
+// in connB's schema are dropped so filter out ChangeDropTable and
+// ChangeDropColumn changes if that's not wanted. Views, scripts etc. are
+// not compared.
+func DiffSchemas(connA, connB *Conn, schema string) ([]Change, error) {
+	name := connA.storedName(schema)
+	a, err := connA.schemaDef(name)
+	if err != nil {
+		return nil, err
+	}
+	if !a.exists {
+		return nil, fmt.Errorf("Unable to find the schema %s", name)
+	}
+	b, err := connB.schemaDef(name)
+	if err != nil {
+		return nil, err
+	}
+	return diffSchemaDefs(a, b), nil
+}
+
+/*--- Private Routines ---*/
+
+type schemaDef struct {
+	name   string
+	exists bool
+	tables map[string]*tableDef
+}
+
+type tableDef struct {
+	name    string
+	columns []*columnDef
+	keys    []*keyDef
+}
+
+type columnDef struct {
+	Table    string `exasol:"column_table"`
+	Name     string `exasol:"column_name"`
+	Type     string `exasol:"column_type"`
+	Nullable bool   `exasol:"column_is_nullable"`
+	Default  string `exasol:"column_default"`
+}
+
+// A primary or foreign key
+type keyDef struct {
+	Table     string `exasol:"constraint_table"`
+	Type      string `exasol:"constraint_type"`
+	Name      string `exasol:"constraint_name"`
+	Enabled   bool   `exasol:"constraint_enabled"`
+	Column    string `exasol:"column_name"`
+	RefSchema string `exasol:"referenced_schema"`
+	RefTable  string `exasol:"referenced_table"`
+	RefColumn string `exasol:"referenced_column"`
+
+	columns    []string // Those of all the key's rows
+	refColumns []string
+}
+
+// Reads the schema's definition from the system tables
+func (c *Conn) schemaDef(schema string) (*schemaDef, error) {
+	def := &schemaDef{name: schema, tables: map[string]*tableDef{}}
+	exists, err := c.Exists("SELECT 1 FROM exa_schemas WHERE schema_name = ?", []interface{}{schema})
+	if err != nil || !exists {
+		return def, err
+	}
+	def.exists = true
+
+	tables, err := c.FetchColumn(
+		"SELECT table_name FROM exa_all_tables WHERE table_schema = ?", []interface{}{schema},
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		name, _ := t.(string)
+		def.tables[name] = &tableDef{name: name}
+	}
+
+	columns, err := collect(QueryStruct[columnDef](c, `
+		SELECT column_table, column_name, column_type, column_is_nullable, column_default
+		FROM exa_all_columns
+		WHERE column_schema = ? AND column_object_type = 'TABLE'
+		ORDER BY column_table, column_ordinal_position
+	`, []interface{}{schema}))
+	if err != nil {
+		return nil, err
+	}
+	for i := range columns {
+		if t := def.tables[columns[i].Table]; t != nil {
+			t.columns = append(t.columns, &columns[i])
+		}
+	}
+
+	keys, err := collect(QueryStruct[keyDef](c, `
+		SELECT c.constraint_table, c.constraint_type, c.constraint_name,
+			c.constraint_enabled, cc.column_name, cc.referenced_schema,
+			cc.referenced_table, cc.referenced_column
+		FROM exa_all_constraints c
+		JOIN exa_all_constraint_columns cc ON
+			cc.constraint_schema = c.constraint_schema AND
+			cc.constraint_table = c.constraint_table AND
+			cc.constraint_name = c.constraint_name
+		WHERE c.constraint_schema = ?
+			AND c.constraint_type IN ('PRIMARY KEY', 'FOREIGN KEY')
+		ORDER BY c.constraint_table, c.constraint_name, cc.ordinal_position
+	`, []interface{}{schema}))
+	if err != nil {
+		return nil, err
+	}
+	var last *keyDef
+	for i := range keys {
+		k := &keys[i]
+		if last == nil || last.Table != k.Table || last.Name != k.Name {
+			if t := def.tables[k.Table]; t != nil {
+				t.keys = append(t.keys, k)
+			}
+			last = k
+		}
+		last.columns = append(last.columns, k.Column)
+		if k.RefColumn != "" {
+			last.refColumns = append(last.refColumns, k.RefColumn)
+		}
+	}
+	return def, nil
+}
+
+// Returns the changes needed to turn b into a
+func diffSchemaDefs(a, b *schemaDef) []Change {
+	var changes []Change
+	schema := quoteExact(a.name)
+	if !b.exists {
+		changes = append(changes, Change{
+			Type: ChangeCreateSchema,
+			SQL:  "CREATE SCHEMA " + schema,
+		})
+	}
+
+	// Foreign keys are dropped before the primary keys they reference
+	for _, keyType := range []string{"FOREIGN KEY", "PRIMARY KEY"} {
+		for _, name := range sortedKeys(b.tables) {
+			at := a.tables[name]
+			for _, k := range b.tables[name].keys {
+				if k.Type == keyType && (at == nil || at.key(k) == nil) {
+					changes = append(changes, Change{
+						Type: ChangeDropConstraint, Table: name, Object: k.Name,
+						SQL: fmt.Sprintf("ALTER TABLE %s.%s DROP CONSTRAINT %s",
+							schema, quoteExact(name), quoteExact(k.Name)),
+					})
+				}
+			}
+		}
+	}
+	for _, name := range sortedKeys(b.tables) {
+		if a.tables[name] == nil {
+			changes = append(changes, Change{
+				Type: ChangeDropTable, Table: name,
+				SQL: fmt.Sprintf("DROP TABLE %s.%s CASCADE CONSTRAINTS", schema, quoteExact(name)),
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(a.tables) {
+		at := a.tables[name]
+		bt := b.tables[name]
+		table := schema + "." + quoteExact(name)
+		if bt == nil {
+			cols := make([]string, len(at.columns))
+			for i, col := range at.columns {
+				cols[i] = col.sql()
+			}
+			changes = append(changes, Change{
+				Type: ChangeCreateTable, Table: name,
+				SQL: fmt.Sprintf("CREATE TABLE %s (\n\t%s\n)", table, strings.Join(cols, ",\n\t")),
+			})
+		} else {
+			for _, col := range at.columns {
+				bc := bt.column(col.Name)
+				if bc == nil {
+					changes = append(changes, Change{
+						Type: ChangeAddColumn, Table: name, Object: col.Name,
+						SQL: fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, col.sql()),
+					})
+				} else if *bc != *col {
+					sql := col.sql()
+					if col.Default == "" && bc.Default != "" {
+						sql += " DEFAULT NULL"
+					}
+					if col.Nullable {
+						sql += " NULL"
+					}
+					changes = append(changes, Change{
+						Type: ChangeModifyColumn, Table: name, Object: col.Name,
+						SQL: fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", table, sql),
+					})
+				}
+			}
+			for _, col := range bt.columns {
+				if at.column(col.Name) == nil {
+					changes = append(changes, Change{
+						Type: ChangeDropColumn, Table: name, Object: col.Name,
+						SQL: fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, quoteExact(col.Name)),
+					})
+				}
+			}
+		}
+	}
+
+	// Primary keys are added before the foreign keys referencing them
+	for _, keyType := range []string{"PRIMARY KEY", "FOREIGN KEY"} {
+		for _, name := range sortedKeys(a.tables) {
+			bt := b.tables[name]
+			for _, k := range a.tables[name].keys {
+				if k.Type == keyType && (bt == nil || bt.key(k) == nil) {
+					changes = append(changes, Change{
+						Type: ChangeAddConstraint, Table: name, Object: k.Name,
+						SQL: fmt.Sprintf("ALTER TABLE %s.%s ADD %s", schema, quoteExact(name), k.sql()),
+					})
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(changes, func(x, y Change) int {
+		return int(x.Type) - int(y.Type)
+	})
+	return changes
+}
+
+func (t *tableDef) column(name string) *columnDef {
+	for _, col := range t.columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// Returns the table's key with the same definition (if any)
+func (t *tableDef) key(k *keyDef) *keyDef {
+	for _, tk := range t.keys {
+		if tk.Type == k.Type && tk.Enabled == k.Enabled &&
+			tk.RefSchema == k.RefSchema && tk.RefTable == k.RefTable &&
+			slices.Equal(tk.columns, k.columns) &&
+			slices.Equal(tk.refColumns, k.refColumns) {
+			return tk
+		}
+	}
+	return nil
+}
+
+// The column's definition for CREATE TABLE or ADD/MODIFY COLUMN
+func (col *columnDef) sql() string {
+	sql := quoteExact(col.Name) + " " + col.Type
+	if col.Default != "" {
+		sql += " DEFAULT " + col.Default
+	}
+	if !col.Nullable {
+		sql += " NOT NULL"
+	}
+	return sql
+}
+
+// The key's definition for ALTER TABLE ... ADD
+func (k *keyDef) sql() string {
+	sql := ""
+	// Generated names would clash if reused so let Exasol generate new ones
+	if !strings.HasPrefix(k.Name, "SYS_") {
+		sql = "CONSTRAINT " + quoteExact(k.Name) + " "
+	}
+	sql += k.Type + " (" + quoteList(k.columns, quoteExact) + ")"
+	if k.Type == "FOREIGN KEY" {
+		sql += fmt.Sprintf(" REFERENCES %s.%s (%s)",
+			quoteExact(k.RefSchema), quoteExact(k.RefTable), quoteList(k.refColumns, quoteExact))
+	}
+	if !k.Enabled {
+		sql += " DISABLE"
+	}
+	return sql
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
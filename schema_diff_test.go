@@ -0,0 +1,91 @@
+package exasol
+
+func (s *testSuite) TestDiffSchemaDefs() {
+	id := &columnDef{Name: "ID", Type: "DECIMAL(18,0)"}
+	name := &columnDef{Name: "NAME", Type: "VARCHAR(50) UTF8", Nullable: true}
+	pk := &keyDef{Type: "PRIMARY KEY", Name: "SYS_1", Enabled: true, columns: []string{"ID"}}
+	fk := &keyDef{
+		Type: "FOREIGN KEY", Name: "ORDERS_FK", Enabled: true, columns: []string{"CUSTOMER_ID"},
+		RefSchema: "S", RefTable: "CUSTOMERS", refColumns: []string{"ID"},
+	}
+	a := &schemaDef{name: "S", exists: true, tables: map[string]*tableDef{
+		"CUSTOMERS": {columns: []*columnDef{
+			id, {Name: "NAME", Type: "VARCHAR(100) UTF8", Nullable: true, Default: "'?'"},
+		}, keys: []*keyDef{pk}},
+		"ORDERS": {columns: []*columnDef{
+			id, {Name: "CUSTOMER_ID", Type: "DECIMAL(18,0)", Nullable: true},
+		}, keys: []*keyDef{pk, fk}},
+	}}
+
+	s.Equal([]Change{
+		{Type: ChangeCreateSchema, SQL: `CREATE SCHEMA "S"`},
+		{Type: ChangeCreateTable, Table: "CUSTOMERS", SQL: `CREATE TABLE "S"."CUSTOMERS" (
+	"ID" DECIMAL(18,0) NOT NULL,
+	"NAME" VARCHAR(100) UTF8 DEFAULT '?'
+)`},
+		{Type: ChangeCreateTable, Table: "ORDERS", SQL: `CREATE TABLE "S"."ORDERS" (
+	"ID" DECIMAL(18,0) NOT NULL,
+	"CUSTOMER_ID" DECIMAL(18,0)
+)`},
+		{Type: ChangeAddConstraint, Table: "CUSTOMERS", Object: "SYS_1",
+			SQL: `ALTER TABLE "S"."CUSTOMERS" ADD PRIMARY KEY ("ID")`},
+		{Type: ChangeAddConstraint, Table: "ORDERS", Object: "SYS_1",
+			SQL: `ALTER TABLE "S"."ORDERS" ADD PRIMARY KEY ("ID")`},
+		{Type: ChangeAddConstraint, Table: "ORDERS", Object: "ORDERS_FK",
+			SQL: `ALTER TABLE "S"."ORDERS" ADD CONSTRAINT "ORDERS_FK" FOREIGN KEY ("CUSTOMER_ID") REFERENCES "S"."CUSTOMERS" ("ID")`},
+	}, diffSchemaDefs(a, &schemaDef{}))
+
+	s.Empty(diffSchemaDefs(a, a))
+
+	// The PK has a different generated name but the same definition
+	pk2 := *pk
+	pk2.Name = "SYS_2"
+	disabledFK := *fk
+	disabledFK.Enabled = false
+	b := &schemaDef{name: "S", exists: true, tables: map[string]*tableDef{
+		"CUSTOMERS": {columns: []*columnDef{id, name, {Name: "OLD", Type: "DATE"}}, keys: []*keyDef{&pk2}},
+		"ORDERS":    {columns: []*columnDef{id}, keys: []*keyDef{&disabledFK}},
+		"UNUSED":    {columns: []*columnDef{id}},
+	}}
+	s.Equal([]Change{
+		{Type: ChangeDropConstraint, Table: "ORDERS", Object: "ORDERS_FK",
+			SQL: `ALTER TABLE "S"."ORDERS" DROP CONSTRAINT "ORDERS_FK"`},
+		{Type: ChangeDropTable, Table: "UNUSED",
+			SQL: `DROP TABLE "S"."UNUSED" CASCADE CONSTRAINTS`},
+		{Type: ChangeAddColumn, Table: "ORDERS", Object: "CUSTOMER_ID",
+			SQL: `ALTER TABLE "S"."ORDERS" ADD COLUMN "CUSTOMER_ID" DECIMAL(18,0)`},
+		{Type: ChangeModifyColumn, Table: "CUSTOMERS", Object: "NAME",
+			SQL: `ALTER TABLE "S"."CUSTOMERS" MODIFY COLUMN "NAME" VARCHAR(100) UTF8 DEFAULT '?' NULL`},
+		{Type: ChangeDropColumn, Table: "CUSTOMERS", Object: "OLD",
+			SQL: `ALTER TABLE "S"."CUSTOMERS" DROP COLUMN "OLD"`},
+		{Type: ChangeAddConstraint, Table: "ORDERS", Object: "SYS_1",
+			SQL: `ALTER TABLE "S"."ORDERS" ADD PRIMARY KEY ("ID")`},
+		{Type: ChangeAddConstraint, Table: "ORDERS", Object: "ORDERS_FK",
+			SQL: `ALTER TABLE "S"."ORDERS" ADD CONSTRAINT "ORDERS_FK" FOREIGN KEY ("CUSTOMER_ID") REFERENCES "S"."CUSTOMERS" ("ID")`},
+	}, diffSchemaDefs(a, b))
+	s.Equal("DROP COLUMN", ChangeDropColumn.String())
+}
+
+func (s *testSuite) TestDiffSchemas() {
+	s.execute("CREATE TABLE customers (id INT PRIMARY KEY, name VARCHAR(10) DEFAULT 'x')")
+	s.execute("CREATE TABLE orders (id INT, customer_id INT REFERENCES customers (id))")
+
+	changes, err := DiffSchemas(s.exaConn, s.exaConn, s.schema)
+	s.NoError(err)
+	s.Empty(changes, "Identical")
+
+	a, err := s.exaConn.schemaDef("TEST")
+	if s.NoError(err) && s.Contains(a.tables, "ORDERS") {
+		s.Len(a.tables["CUSTOMERS"].columns, 2)
+		if s.Len(a.tables["ORDERS"].keys, 1) {
+			fk := a.tables["ORDERS"].keys[0]
+			s.Equal("FOREIGN KEY", fk.Type)
+			s.Equal([]string{"CUSTOMER_ID"}, fk.columns)
+			s.Equal([]string{"ID"}, fk.refColumns)
+		}
+		s.Len(diffSchemaDefs(a, &schemaDef{}), 5)
+	}
+
+	_, err = DiffSchemas(s.exaConn, s.exaConn, "no_such_schema")
+	s.EqualError(err, "Unable to find the schema NO_SUCH_SCHEMA")
+}
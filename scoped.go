@@ -0,0 +1,217 @@
+/*
+	Scoping a connection's statements to a schema without opening it
+	e.g. for multi-tenant code where each tenant has its own schema
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "bytes"
+
+/*--- Public Interface ---*/
+
+// ScopedConn runs its statements against a specific schema (as per
+// Execute's default schema arg) so that they can use non-schema-qualified
+// identifiers without an OPEN SCHEMA. It's a lightweight wrapper so it's
+// fine to create one per request. The schema isn't checked until a
+// statement is run. As with Execute's schema arg, the schema is sent as
+// the session's current schema so it stays open afterwards i.e. the
+// Conn's own non-schema-qualified statements then resolve against it.
+type ScopedConn struct {
+	conn   *Conn
+	schema string
+	tenant *tenant // Set if it's via a Router
+}
+
+// WithSchema returns a ScopedConn whose statements target the schema
+func (c *Conn) WithSchema(schema string) *ScopedConn {
+	return &ScopedConn{conn: c, schema: schema}
+}
+
+// Conn returns the underlying connection
+func (s *ScopedConn) Conn() *Conn { return s.conn }
+
+// Schema returns the schema the statements target
+func (s *ScopedConn) Schema() string { return s.schema }
+
+// Execute is Conn.Execute without the schema arg i.e. the optional args
+// are the binds, colDefs and isColumnar
+func (s *ScopedConn) Execute(sql string, args ...interface{}) (n int64, err error) {
+	err = s.run(func() error {
+		n, err = s.conn.Execute(sql, s.args(args)...)
+		return err
+	})
+	return n, err
+}
+
+// ExecuteResult is Conn.ExecuteResult without the schema arg
+func (s *ScopedConn) ExecuteResult(sql string, args ...interface{}) (res *ExecResult, err error) {
+	err = s.run(func() error {
+		res, err = s.conn.ExecuteResult(sql, s.args(args)...)
+		return err
+	})
+	return res, err
+}
+
+// Validate is Conn.Validate against the schema
+func (s *ScopedConn) Validate(sql string) error {
+	return s.run(func() error { return s.conn.Validate(sql, s.schema) })
+}
+
+// FetchChan is Conn.FetchChan without the schema arg i.e. the optional
+// args are the binds and FetchOpts. If it's via a Router the connection
+// stays locked until the rows have all been fetched so the chan must be
+// drained.
+func (s *ScopedConn) FetchChan(sql string, args ...interface{}) (<-chan []interface{}, error) {
+	finish, err := s.start()
+	if err != nil {
+		return nil, err
+	}
+	rs, opts, err := s.conn.query(sql, s.args(args))
+	if err != nil {
+		finish(err)
+		return nil, err
+	}
+	ch := make(chan []interface{}, 1000)
+	go func() {
+		err := s.conn.resultsToChan(rs, ch, opts, nil)
+		finish(err)
+		if err != nil {
+			panic(err) // As per Conn.FetchChan
+		}
+	}()
+	return ch, nil
+}
+
+// FetchSlice is Conn.FetchSlice without the schema arg
+func (s *ScopedConn) FetchSlice(sql string, args ...interface{}) (res [][]interface{}, err error) {
+	err = s.run(func() error {
+		res, err = s.conn.FetchSlice(sql, s.args(args)...)
+		return err
+	})
+	return res, err
+}
+
+// FetchOne is Conn.FetchOne without the schema arg
+func (s *ScopedConn) FetchOne(sql string, args ...interface{}) (val interface{}, err error) {
+	err = s.run(func() error {
+		val, err = s.conn.FetchOne(sql, s.args(args)...)
+		return err
+	})
+	return val, err
+}
+
+// FetchColumn is Conn.FetchColumn without the schema arg
+func (s *ScopedConn) FetchColumn(sql string, args ...interface{}) (res []interface{}, err error) {
+	err = s.run(func() error {
+		res, err = s.conn.FetchColumn(sql, s.args(args)...)
+		return err
+	})
+	return res, err
+}
+
+// Exists is Conn.Exists without the schema arg
+func (s *ScopedConn) Exists(sql string, args ...interface{}) (exists bool, err error) {
+	err = s.run(func() error {
+		exists, err = s.conn.Exists(sql, s.args(args)...)
+		return err
+	})
+	return exists, err
+}
+
+// Count is Conn.Count without the schema arg
+func (s *ScopedConn) Count(sql string, args ...interface{}) (n int64, err error) {
+	err = s.run(func() error {
+		n, err = s.conn.Count(sql, s.args(args)...)
+		return err
+	})
+	return n, err
+}
+
+// BulkInsert is Conn.BulkInsert into the schema's table
+func (s *ScopedConn) BulkInsert(table string, data *bytes.Buffer) error {
+	return s.run(func() error { return s.conn.BulkInsert(s.schema, table, data) })
+}
+
+// BulkSelect is Conn.BulkSelect from the schema's table
+func (s *ScopedConn) BulkSelect(table string, data *bytes.Buffer, opts ...ExportOpts) error {
+	return s.run(func() error { return s.conn.BulkSelect(s.schema, table, data, opts...) })
+}
+
+// StreamInsert is Conn.StreamInsert into the schema's table
+func (s *ScopedConn) StreamInsert(table string, data <-chan []byte) error {
+	return s.run(func() error { return s.conn.StreamInsert(s.schema, table, data) })
+}
+
+// StreamSelect is Conn.StreamSelect from the schema's table. If it's
+// via a Router the connection stays locked until the export has finished
+// (i.e. Data has been drained or Close called) which is when it's added
+// to the tenant's statistics. Exports started by Resume aren't routed.
+func (s *ScopedConn) StreamSelect(table string, opts ...ExportOpts) *Rows {
+	finish, err := s.start()
+	if err != nil {
+		return s.conn.failedRows(err)
+	}
+	rows := s.conn.StreamSelect(s.schema, table, opts...)
+	go func() {
+		rows.wg.Wait()
+		finish(rows.Error)
+	}()
+	return rows
+}
+
+/*--- Private Routines ---*/
+
+// Runs the statement via the tenant's quota and statistics, if any. Those
+// from a Router lock the connection since the tenants are shared.
+func (s *ScopedConn) run(stmt func() error) error {
+	if s.tenant == nil {
+		return stmt()
+	}
+	s.conn.Lock()
+	defer s.conn.Unlock()
+	return s.tenant.run(stmt)
+}
+
+// Like run but for statements that carry on in the background (e.g.
+// fetching their rows) so they call finish once they're done, which is
+// when a Router's lock on the connection is released
+func (s *ScopedConn) start() (finish func(error), err error) {
+	if s.tenant == nil {
+		return func(error) {}, nil
+	}
+	s.conn.Lock()
+	start, err := s.tenant.begin()
+	if err != nil {
+		s.conn.Unlock()
+		return nil, err
+	}
+	return func(err error) {
+		s.tenant.end(start, err)
+		s.conn.Unlock()
+	}, nil
+}
+
+// Inserts the schema as the 2nd optional arg
+func (s *ScopedConn) args(args []interface{}) []interface{} {
+	scoped := make([]interface{}, 0, len(args)+1)
+	if len(args) > 0 {
+		scoped = append(scoped, args[0])
+	} else {
+		scoped = append(scoped, nil)
+	}
+	scoped = append(scoped, s.schema)
+	if len(args) > 1 {
+		scoped = append(scoped, args[1:]...)
+	}
+	return scoped
+}
@@ -0,0 +1,80 @@
+package exasol
+
+import "bytes"
+
+func (s *testSuite) TestScopedArgs() {
+	sc := s.exaConn.WithSchema("tenant")
+	s.Equal("tenant", sc.Schema())
+	s.Equal(s.exaConn, sc.Conn())
+	s.Equal([]interface{}{nil, "tenant"}, sc.args(nil))
+	s.Equal([]interface{}{[]interface{}{1}, "tenant"}, sc.args([]interface{}{[]interface{}{1}}))
+	s.Equal(
+		[]interface{}{nil, "tenant", nil, true},
+		sc.args([]interface{}{nil, nil, true}),
+	)
+}
+
+func (s *testSuite) TestWithSchema() {
+	s.execute("CREATE TABLE foo (id INT, name VARCHAR(10))")
+	sc := s.exaConn.WithSchema(s.schema)
+	s.exaConn.Execute("OPEN SCHEMA sys")
+
+	_, err := sc.Execute("INSERT INTO foo VALUES (?, ?)", [][]interface{}{{1, 2}, {"a", "b"}}, nil, true)
+	s.NoError(err)
+	n, err := sc.Count("SELECT COUNT(*) FROM foo WHERE id > ?", []interface{}{0})
+	s.NoError(err)
+	s.Equal(int64(2), n)
+	s.NoError(sc.Validate("SELECT name FROM foo"))
+
+	s.NoError(sc.BulkInsert("foo", bytes.NewBufferString("3,c\n")))
+	names, err := sc.FetchColumn("SELECT name FROM foo ORDER BY id")
+	s.NoError(err)
+	s.Equal([]interface{}{"a", "b", "c"}, names)
+
+	s.EqualError(
+		s.exaConn.WithSchema("").BulkInsert("foo", &bytes.Buffer{}),
+		"You must pass in a schema and table to BulkInsert",
+	)
+}
+
+// Answers each createPreparedStatement with a new handle
+type prepWSHandler struct {
+	testWSHandler
+	schemas []string
+}
+
+func (wsh *prepWSHandler) WriteJSON(req interface{}) error {
+	if r, ok := req.(*createPrepStmtReq); ok {
+		wsh.schemas = append(wsh.schemas, r.Attributes.CurrentSchema)
+	}
+	return nil
+}
+
+func (wsh *prepWSHandler) ReadJSON(resp interface{}) error {
+	if r, ok := resp.(*createPrepStmtRes); ok {
+		r.Status = "ok"
+		r.ResponseData = &createPrepStmtData{StatementHandle: len(wsh.schemas)}
+	}
+	return nil
+}
+
+func (s *testSuite) TestScopedPrepStmtCache() {
+	wsh := &prepWSHandler{}
+	c := &Conn{
+		Conf:          ConnConf{CachePrepStmts: true},
+		wsh:           wsh,
+		log:           newDefaultLogger(),
+		Stats:         map[string]int{},
+		prepStmtCache: map[prepStmtKey]*prepStmt{},
+	}
+	sql := "INSERT INTO foo VALUES (?)"
+	a, err := c.getPrepStmt("a", sql)
+	s.NoError(err)
+	b, err := c.getPrepStmt("b", sql)
+	s.NoError(err)
+	again, err := c.getPrepStmt("a", sql)
+	s.NoError(err)
+	s.NotEqual(a.sth, b.sth, "Cached per schema")
+	s.Equal(a.sth, again.sth)
+	s.Equal([]string{"a", "b"}, wsh.schemas)
+}
@@ -0,0 +1,177 @@
+/*
+	Running scripts of several semicolon separated statements
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// ScriptResult is the outcome of one of ExecuteScript's statements
+type ScriptResult struct {
+	SQL          string
+	RowsAffected int64           // For statements not returning a result set
+	Columns      []Column        // For those returning one (nil otherwise)
+	Rows         [][]interface{} // The result set's rows (fetched in full)
+	Duration     time.Duration   // Including fetching the rows
+}
+
+// ExecuteScript splits the script into its statements (see SplitStatements)
+// and executes them in turn stopping at the first that fails. The results
+// of those that ran are returned either way. Result sets are fetched in
+// full (decoded as per ConnConf.FetchOpts etc.) so use FetchChan for
+// large ones.
+func (c *Conn) ExecuteScript(script string) ([]*ScriptResult, error) {
+	var results []*ScriptResult
+	for i, sql := range SplitStatements(script) {
+		res, err := c.executeScriptStatement(sql)
+		if err != nil {
+			return results, c.errorf("Unable to execute statement %d of the script: %w", i+1, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// SplitStatements splits the script on the semicolons that aren't in string
+// literals, quoted identifiers or comments. The comments before and after
+// each statement are dropped as are statements consisting solely of them.
+// As in EXAplus a CREATE SCRIPT or CREATE FUNCTION statement's body may
+// contain semicolons so it's instead terminated by a line consisting
+// solely of a slash (which isn't part of the statement).
+func SplitStatements(script string) []string {
+	stmts, _ := scanStatements(script)
+	return stmts
+}
+
+// StatementComplete returns whether the script ends with a terminated
+// statement (or is empty) rather than part way through one (or through a
+// string literal or comment). It's useful for reading statements a line
+// at a time.
+func StatementComplete(script string) bool {
+	_, complete := scanStatements(script)
+	return complete
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) executeScriptStatement(sql string) (*ScriptResult, error) {
+	res := &ScriptResult{SQL: sql}
+	start := time.Now()
+	resp, err := c.execute(sql, nil, "", nil, false, c.wantNumbers(c.Conf.FetchOpts))
+	if err != nil {
+		return nil, err
+	}
+	if resp.ResponseData != nil && len(resp.ResponseData.Results) > 0 {
+		result := resp.ResponseData.Results[0]
+		if result.ResultType == resultSetType {
+			rs := result.ResultSet
+			opts := c.Conf.FetchOpts
+			c.decodeData(rs.Data, rs.Columns)
+			res.Columns = exportColumns(rs.Columns)
+			res.Rows = [][]interface{}{}
+			ch := make(chan []interface{}, 1000)
+			go c.resultsToChan(rs, ch, opts, nil)
+			for row := range ch {
+				res.Rows = append(res.Rows, row)
+			}
+			if rs.fetchErr != nil {
+				return nil, rs.fetchErr
+			}
+		} else {
+			res.RowsAffected = result.RowCount
+		}
+	}
+	res.Duration = time.Since(start)
+	return res, nil
+}
+
+// Statements whose bodies are terminated by a slash on a line of its own
+var scriptHeaderRE = regexp.MustCompile(`(?i)^CREATE\s+(?:OR\s+REPLACE\s+)?` +
+	`(?:(?:\w+\s+)?(?:SCALAR|SET|ADAPTER)\s+|(?:LUA|PYTHON3?|JAVA|R)\s+)?(?:SCRIPT|FUNCTION)\s`)
+var scriptEndRE = regexp.MustCompile(`(?m)^[ \t]*/[ \t]*\r?$`)
+
+// Returns the script's statements and whether the last was terminated
+func scanStatements(script string) (stmts []string, complete bool) {
+	first, last := -1, 0 // The statement's first and last+1 bytes of code
+	code := func(i, end int) {
+		if first < 0 {
+			first = i
+		}
+		last = min(end, len(script))
+	}
+	add := func() {
+		if first >= 0 {
+			stmts = append(stmts, script[first:last])
+		}
+		first = -1
+	}
+
+	complete = true
+	for i := 0; i < len(script); i++ {
+		switch ch := script[i]; {
+		case ch == '\'' || ch == '"':
+			start := i
+			// A doubled quote is an escaped one so needs no special handling:
+			// the literal just ends and immediately starts again
+			if end := strings.IndexByte(script[i+1:], ch); end >= 0 {
+				i += end + 1
+			} else {
+				i = len(script)
+				complete = false
+			}
+			code(start, i+1)
+		case ch == '-' && strings.HasPrefix(script[i:], "--"):
+			if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(script)
+			}
+		case ch == '/' && strings.HasPrefix(script[i:], "/*"):
+			if end := strings.Index(script[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(script)
+				complete = false
+			}
+		case ch == ';':
+			add()
+		case first < 0 && (ch == 'C' || ch == 'c') && scriptHeaderRE.MatchString(script[i:]):
+			// The body isn't scanned since it's in another language whose
+			// quotes and comments (e.g. Python's #) may not be SQL's
+			body := script[i:]
+			end := scriptEndRE.FindStringIndex(body)
+			if end != nil {
+				body = body[:end[0]]
+			}
+			code(i, i+len(strings.TrimRight(body, " \t\r\n")))
+			if end == nil {
+				i = len(script)
+				complete = false
+			} else {
+				add()
+				i += end[1] - 1
+			}
+		case ch != ' ' && ch != '\t' && ch != '\n' && ch != '\r':
+			code(i, i+1)
+		}
+	}
+	complete = complete && first < 0
+	add()
+	return stmts, complete
+}
@@ -0,0 +1,70 @@
+package exasol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitStatements(t *testing.T) {
+	assert.Equal(t, []string{
+		`SELECT 'a;b', "c;""d" FROM t`,
+		`SELECT 'it''s' /* ; */ FROM dual`,
+		"DELETE FROM t",
+	}, SplitStatements(`
+		SELECT 'a;b', "c;""d" FROM t;
+		-- say hi;
+		SELECT 'it''s' /* ; */ FROM dual;;
+		DELETE FROM t
+		-- trailing;
+		/* comment */
+	`))
+	assert.Empty(t, SplitStatements("  ; -- nothing\n"))
+	assert.Equal(t, []string{"SELECT 'unterminated;"}, SplitStatements("SELECT 'unterminated;"))
+
+	script := "CREATE OR REPLACE PYTHON3 SCALAR SCRIPT s.f(a INT) RETURNS INT AS\n" +
+		"# it's fine; really\n" +
+		"def run(ctx):\n" +
+		"    return ctx.a"
+	fn := "CREATE FUNCTION f(a INT) RETURN INT IS\n" +
+		"BEGIN\n" +
+		"    RETURN a;\n" +
+		"END f;"
+	assert.Equal(t, []string{script, fn, "SELECT f(1)", "CREATE TABLE script (a INT)"},
+		SplitStatements(script+"\n/\n"+fn+"\n  /  \nSELECT f(1); CREATE TABLE script (a INT);"),
+		"Script bodies end with a slash")
+	assert.Equal(t, []string{"CREATE LUA SCRIPT s AS\nexit();"},
+		SplitStatements("CREATE LUA SCRIPT s AS\nexit();\n"))
+
+	assert.True(t, StatementComplete(""))
+	assert.True(t, StatementComplete("SELECT 1; -- done"))
+	assert.False(t, StatementComplete("SELECT 1; SELECT"))
+	assert.False(t, StatementComplete("SELECT 'a;\n"))
+	assert.False(t, StatementComplete("SELECT 1 /* ;"))
+	assert.False(t, StatementComplete("CREATE SCRIPT s AS\nexit();\n"), "Awaiting the slash")
+	assert.True(t, StatementComplete("CREATE SCRIPT s AS\nexit();\n/\n"))
+}
+
+func (s *testSuite) TestExecuteScript() {
+	s.execute("CREATE TABLE foo (a INT)")
+	results, err := s.exaConn.ExecuteScript(`
+		INSERT INTO foo VALUES (1), (2);
+		SELECT a FROM foo ORDER BY a;
+		DELETE FROM foo WHERE a = 1;
+		SELECT * FROM foo WHERE FALSE
+	`)
+	s.NoError(err)
+	if s.Len(results, 4) {
+		s.Equal(int64(2), results[0].RowsAffected)
+		s.Nil(results[0].Columns)
+		s.Equal("A", results[1].Columns[0].Name)
+		s.Equal([][]interface{}{{float64(1)}, {float64(2)}}, results[1].Rows)
+		s.Equal(int64(1), results[2].RowsAffected)
+		s.Empty(results[3].Rows)
+		s.NotNil(results[3].Rows)
+	}
+
+	results, err = s.exaConn.ExecuteScript("DELETE FROM foo; SELECT * FROM nope; DELETE FROM foo")
+	s.ErrorContains(err, "Unable to execute statement 2 of the script")
+	s.Len(results, 1)
+}
@@ -0,0 +1,140 @@
+/*
+	Support for reaching Exasol through an SSH jump host
+	for both the websocket and the bulk proxy connections
+	and for tuning their TCP sockets
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"golang.org/x/crypto/ssh"
+)
+
+type SSHConfig struct {
+	Host       string // The jump host as "host:port"
+	User       string
+	Password   string // Either a Password and/or a PrivateKey is required
+	PrivateKey []byte // PEM encoded
+	// Verifies the jump host's key. This is required.
+	// Use ssh.InsecureIgnoreHostKey() if you really must skip verification.
+	HostKeyCallback ssh.HostKeyCallback
+}
+
+/*--- Private Routines ---*/
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Opens the SSH connection (if ConnConf.SSH is set)
+// that all subsequent connections are dialed through
+func (c *Conn) sshConnect() error {
+	cfg := c.Conf.SSH
+	if cfg == nil {
+		return nil
+	}
+	if c.Conf.WSHandler != nil {
+		return fmt.Errorf("SSH tunneling isn't supported with a custom WSHandler")
+	}
+	if cfg.HostKeyCallback == nil {
+		return fmt.Errorf("SSHConfig.HostKeyCallback is required")
+	}
+	var auth []ssh.AuthMethod
+	if len(cfg.PrivateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("Unable to parse SSH private key: %s", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+
+	client, err := ssh.Dial("tcp", cfg.Host, &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: cfg.HostKeyCallback,
+		Timeout:         c.Conf.ConnectTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("Unable to connect to SSH host %s: %s", cfg.Host, err)
+	}
+	c.log.Debugf("Tunneling through SSH host %s", cfg.Host)
+	c.ssh = client
+	return nil
+}
+
+func (c *Conn) closeSSH() {
+	if c.ssh != nil {
+		c.ssh.Close()
+	}
+}
+
+// Returns how connections to Exasol should be dialed. Both the websocket
+// and the bulk proxies are dialed with it so that SSH, SocketOpts and
+// ConnectTimeout apply to them alike.
+func (c *Conn) dialer() dialFunc {
+	var dial dialFunc
+	if c.ssh != nil {
+		dial = c.ssh.DialContext
+	} else {
+		opts := c.Conf.Socket
+		d := net.Dialer{KeepAlive: opts.KeepAlive}
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := d.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			if err = opts.apply(conn); err != nil {
+				conn.Close()
+				return nil, fmt.Errorf("Unable to set socket options: %s", err)
+			}
+			return conn, nil
+		}
+	}
+	if timeout := c.Conf.ConnectTimeout; timeout > 0 {
+		dialNoTimeout := dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return dialNoTimeout(ctx, network, addr)
+		}
+	}
+	return dial
+}
+
+func (opts SocketOpts) apply(conn net.Conn) error {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil
+	}
+	if opts.DisableNoDelay {
+		if err := tcp.SetNoDelay(false); err != nil {
+			return err
+		}
+	}
+	if opts.ReadBuffer > 0 {
+		if err := tcp.SetReadBuffer(opts.ReadBuffer); err != nil {
+			return err
+		}
+	}
+	if opts.WriteBuffer > 0 {
+		if err := tcp.SetWriteBuffer(opts.WriteBuffer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+/*
+	Binding slices of structs to Execute's placeholders
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+/*--- Private Routines ---*/
+
+// Whether the binds passed to Execute are a slice of structs (or struct pointers)
+func isStructBinds(binds interface{}) bool {
+	t := reflect.TypeOf(binds)
+	if t == nil || t.Kind() != reflect.Slice {
+		return false
+	}
+	t = t.Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// Converts a slice of structs into row binds. If the statement uses
+// :name placeholders they're replaced with ? and each is bound to the
+// field that QueryStruct would decode a column of that name into.
+// Otherwise the struct's fields are bound in the order they're declared
+// (skipping unexported fields and those tagged `exasol:"-"`).
+// Pointer fields are dereferenced with nil pointers being bound as NULLs.
+func structBinds(sql string, binds interface{}) (string, [][]interface{}, error) {
+	rv := reflect.ValueOf(binds)
+	t := rv.Type().Elem()
+	isPtr := t.Kind() == reflect.Ptr
+	if isPtr {
+		t = t.Elem()
+	}
+
+	sql, names, err := namedPlaceholders(sql)
+	if err != nil {
+		return "", nil, err
+	}
+	var fields [][]int
+	if names == nil {
+		for _, f := range reflect.VisibleFields(t) {
+			if f.IsExported() && !f.Anonymous && f.Tag.Get("exasol") != "-" {
+				fields = append(fields, f.Index)
+			}
+		}
+	} else {
+		cols := make([]column, len(names))
+		for i, n := range names {
+			cols[i].Name = n
+		}
+		fields, err = structFieldMap(t, cols)
+		if err != nil {
+			return "", nil, err
+		}
+		for i, f := range fields {
+			if f == nil {
+				return "", nil, fmt.Errorf("%v has no field for :%s", t, names[i])
+			}
+		}
+	}
+
+	rows := make([][]interface{}, rv.Len())
+	for i := range rows {
+		sv := rv.Index(i)
+		if isPtr {
+			if sv.IsNil() {
+				return "", nil, fmt.Errorf("Struct bind %d is nil", i+1)
+			}
+			sv = sv.Elem()
+		}
+		row := make([]interface{}, len(fields))
+		for j, idx := range fields {
+			fv, err := sv.FieldByIndexErr(idx)
+			if err != nil {
+				continue // Nil embedded struct pointer
+			}
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Ptr {
+				continue
+			}
+			row[j] = fv.Interface()
+		}
+		rows[i] = row
+	}
+	return sql, rows, nil
+}
+
+// Replaces any :name placeholders (outside of string literals, quoted
+// identifiers and comments) with ? returning the names in order.
+// Returns nil names if there were none. Mixing them with ? is an error.
+func namedPlaceholders(sql string) (string, []string, error) {
+	var out strings.Builder
+	var names []string
+	positional := false
+	isNameChar := func(b byte, first bool) bool {
+		return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' ||
+			!first && b >= '0' && b <= '9'
+	}
+	for i := 0; i < len(sql); i++ {
+		b := sql[i]
+		skipTo, from := "", i+1
+		switch {
+		case b == '\'' || b == '"':
+			skipTo = string(b)
+		case b == '-' && strings.HasPrefix(sql[i:], "--"):
+			skipTo, from = "\n", i+2
+		case b == '/' && strings.HasPrefix(sql[i:], "/*"):
+			skipTo, from = "*/", i+2
+		case b == '?':
+			positional = true
+		case b == ':' && i+1 < len(sql) && isNameChar(sql[i+1], true):
+			j := i + 2
+			for j < len(sql) && isNameChar(sql[j], false) {
+				j++
+			}
+			names = append(names, sql[i+1:j])
+			out.WriteByte('?')
+			i = j - 1
+			continue
+		}
+		if skipTo != "" {
+			// Doubled quotes just look like two adjacent literals
+			end := strings.Index(sql[from:], skipTo)
+			if end < 0 {
+				end = len(sql)
+			} else {
+				end += from + len(skipTo)
+			}
+			out.WriteString(sql[i:end])
+			i = end - 1
+			continue
+		}
+		out.WriteByte(b)
+	}
+	if names != nil && positional {
+		return "", nil, fmt.Errorf("Can't mix ? and :name placeholders")
+	}
+	return out.String(), names, nil
+}
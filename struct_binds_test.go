@@ -0,0 +1,83 @@
+package exasol
+
+import "time"
+
+type bindAudit struct {
+	At time.Time
+}
+
+type bindRow struct {
+	*bindAudit
+	ID      int64
+	Name    *string `exasol:"full_name"`
+	private int
+	Skip    string `exasol:"-"`
+}
+
+func (s *testSuite) TestStructBinds() {
+	exa := s.exaConn
+	s.execute("CREATE TABLE " + s.qschema + ".foo ( id INT, full_name VARCHAR(20), at TIMESTAMP )")
+
+	bob := "bob"
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := []bindRow{
+		{bindAudit: &bindAudit{at}, ID: 1, Name: &bob},
+		{ID: 2}, // NULL name and (nil embedded) timestamp
+	}
+	_, err := exa.Execute(
+		"INSERT INTO foo VALUES (?, ?, ?)",
+		[]struct {
+			ID   int64
+			Name *string
+			At   *time.Time
+		}{{1, &bob, &at}, {2, nil, nil}},
+		s.schema,
+	)
+	s.NoError(err, "In field order")
+
+	_, err = exa.Execute(
+		"INSERT INTO foo (at, id, full_name) VALUES (:at, :id + 10, :full_name)",
+		rows, s.schema,
+	)
+	s.NoError(err, "By name")
+	_, err = exa.Execute(
+		"INSERT INTO foo (id, full_name) VALUES (:id + 20, :FULL_NAME)",
+		[]*bindRow{&rows[0]}, s.schema,
+	)
+	s.NoError(err, "Pointers")
+
+	s.Equal([][]interface{}{
+		{float64(1), "bob", "2020-01-02 03:04:05"},
+		{float64(2), nil, nil},
+		{float64(11), "bob", "2020-01-02 03:04:05"},
+		{float64(12), nil, nil},
+		{float64(21), "bob", nil},
+	}, s.fetch(
+		"SELECT id, full_name, TO_CHAR(at, 'YYYY-MM-DD HH24:MI:SS') FROM "+s.qschema+".foo ORDER BY id",
+	))
+
+	_, err = exa.Execute("INSERT INTO foo VALUES (:id, :nope, NULL)", rows, s.schema)
+	s.ErrorContains(err, "no field for :nope")
+	_, err = exa.Execute("INSERT INTO foo VALUES (:id, ?, NULL)", rows, s.schema)
+	s.ErrorContains(err, "Can't mix")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?)", []*bindRow{nil}, s.schema)
+	s.ErrorContains(err, "Struct bind 1 is nil")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?)", rows, s.schema, nil, true)
+	s.ErrorContains(err, "can't be columnar")
+	_, err = exa.Execute("INSERT INTO foo VALUES (?)", []int{1}, s.schema)
+	s.ErrorContains(err, "slice of structs")
+
+	for sql, want := range map[string]string{
+		"SELECT :a, ':b', \":c\" FROM t WHERE x = :a_1":   "SELECT ?, ':b', \":c\" FROM t WHERE x = ?",
+		"SELECT 'it''s :x' -- :y\n, :z /* :w */":          "SELECT 'it''s :x' -- :y\n, ? /* :w */",
+		"SELECT TO_CHAR(x, 'HH:MI') FROM t WHERE y = :y;": "SELECT TO_CHAR(x, 'HH:MI') FROM t WHERE y = ?;",
+	} {
+		got, _, err := namedPlaceholders(sql)
+		s.NoError(err)
+		s.Equal(want, got)
+	}
+	_, names, _ := namedPlaceholders("SELECT :a, ':b', :a_1 -- :c")
+	s.Equal([]string{"a", "a_1"}, names)
+	_, names, _ = namedPlaceholders("SELECT ? FROM t /* :a */")
+	s.Nil(names)
+}
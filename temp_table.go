@@ -0,0 +1,115 @@
+/*
+	Helpers for uniquely named scratch tables that are cleaned up
+	upon Disconnect (Exasol has no session temporary tables)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// The names of the tables from CreateTempTable(Like) start with this so
+// that any left behind by a client that died can be found and dropped
+const TempTablePrefix = "TMP__"
+
+// CreateTempTable creates a uniquely named table in the schema with the
+// column definitions (e.g. "id INT, name VARCHAR(100)") and returns its
+// name. It's a regular table that is dropped upon Disconnect unless it
+// has been dropped already via DropTempTable.
+func (c *Conn) CreateTempTable(schema, columns string) (string, error) {
+	if strings.TrimSpace(columns) == "" {
+		return "", fmt.Errorf("You must pass in the columns to CreateTempTable")
+	}
+	name := c.tempTableName("")
+	return name, c.createTempTable(schema, name, "( "+columns+" )")
+}
+
+// CreateTempTableLike is like CreateTempTable but the table
+// has the same columns as the given table in the schema
+func (c *Conn) CreateTempTableLike(schema, table string) (string, error) {
+	if table == "" {
+		return "", fmt.Errorf("You must pass in a table to CreateTempTableLike")
+	}
+	name := c.tempTableName(table)
+	like := "LIKE " + c.QuoteIdent(schema) + "." + c.QuoteIdent(table)
+	return name, c.createTempTable(schema, name, like)
+}
+
+// DropTempTable drops a table from CreateTempTable(Like)
+func (c *Conn) DropTempTable(schema, name string) error {
+	qualified := c.QuoteIdent(schema) + "." + c.QuoteIdent(name)
+	_, err := c.Execute("DROP TABLE IF EXISTS " + qualified)
+	if err != nil {
+		return err
+	}
+	c.tempMux.Lock()
+	delete(c.tempTables, qualified)
+	c.tempMux.Unlock()
+	return nil
+}
+
+/*--- Private Routines ---*/
+
+// Names are unique across sessions by including the session id
+func (c *Conn) tempTableName(like string) string {
+	if like != "" {
+		like = strings.ToUpper(like) + "_"
+	}
+	return fmt.Sprintf("%s%s%d_%d", TempTablePrefix, like, c.SessionID, c.tempSeq.Add(1))
+}
+
+func (c *Conn) createTempTable(schema, name, def string) error {
+	if schema == "" {
+		return fmt.Errorf("You must pass in a schema for the temp table")
+	}
+	qualified := c.QuoteIdent(schema) + "." + c.QuoteIdent(name)
+	_, err := c.Execute("CREATE TABLE " + qualified + " " + def)
+	if err != nil {
+		return err
+	}
+	c.tempMux.Lock()
+	if c.tempTables == nil {
+		c.tempTables = map[string]bool{}
+	}
+	c.tempTables[qualified] = true
+	c.tempMux.Unlock()
+	return nil
+}
+
+// Drops any temp tables still around. Any open transaction is rolled
+// back first (as it would be by disconnecting anyway) so that only
+// the drops get committed. When disconnecting in the background (see
+// expireConn and Retire) this is only run once tryDisconnect has claimed
+// the idle connection so that its statements can't interleave with any
+// of its users' (which fail with the cause instead).
+func (c *Conn) dropTempTables() {
+	c.tempMux.Lock()
+	tables := c.tempTables
+	c.tempTables = nil
+	c.tempMux.Unlock()
+	if len(tables) == 0 {
+		return
+	}
+	c.Rollback()
+	for table := range tables {
+		_, err := c.Execute("DROP TABLE IF EXISTS " + table)
+		if err != nil {
+			c.log.Warning("Unable to drop temp table: ", err)
+		}
+	}
+	c.Commit()
+}
@@ -0,0 +1,50 @@
+package exasol
+
+import (
+	"bytes"
+	"strings"
+)
+
+func (s *testSuite) TestTempTables() {
+	c, err := Connect(s.connConf())
+	if !s.NoError(err) {
+		return
+	}
+	c.Execute("CREATE TABLE " + s.qschema + ".foo ( id INT, val VARCHAR(10) )")
+
+	t1, err := c.CreateTempTable(s.schema, "id INT, name VARCHAR(100)")
+	s.NoError(err)
+	t2, err := c.CreateTempTableLike(s.schema, "foo")
+	s.NoError(err)
+	s.True(strings.HasPrefix(t1, TempTablePrefix))
+	s.True(strings.HasPrefix(t2, TempTablePrefix+"FOO_"))
+	s.NotEqual(t1, t2)
+
+	tableExists := func(name string) bool {
+		exists, err := s.exaConn.Exists(
+			"SELECT * FROM exa_all_tables WHERE table_schema = 'TEST' AND table_name = ?",
+			[]interface{}{name},
+		)
+		s.NoError(err)
+		return exists
+	}
+	s.True(tableExists(t1))
+	s.NoError(c.BulkInsert(s.schema, t2, bytes.NewBufferString("1,a\n")), "Usable like any table")
+	got, _ := c.FetchSlice("SELECT * FROM " + s.qschema + "." + t2)
+	s.Equal([][]interface{}{{float64(1), "a"}}, got)
+
+	s.NoError(c.DropTempTable(s.schema, t1))
+	s.False(tableExists(t1))
+
+	// Cleaned up upon Disconnect even with autocommit off
+	c.DisableAutoCommit()
+	t3, err := c.CreateTempTable(s.schema, "id INT")
+	s.NoError(err)
+	c.Commit()
+	c.Disconnect()
+	s.False(tableExists(t2))
+	s.False(tableExists(t3))
+
+	_, err = s.exaConn.CreateTempTable(s.schema, " ")
+	s.Error(err)
+}
@@ -0,0 +1,77 @@
+/*
+	Helpers for verifying Exasol's TLS certificate e.g. when the cluster
+	is fronted by a load balancer whose certificate names a hostname
+	rather than the node IPs
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+/*--- Public Interface ---*/
+
+// RootCAsFromPEM returns a pool of the PEM encoded CA certificates
+// (for use as ConnConf.TLSRootCAs)
+func RootCAsFromPEM(pems ...[]byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	for i, pem := range pems {
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificates found in PEM %d", i)
+		}
+	}
+	return pool, nil
+}
+
+// RootCAsFromFiles is like RootCAsFromPEM but reads the PEMs from files
+func RootCAsFromFiles(paths ...string) (*x509.CertPool, error) {
+	pems := make([][]byte, len(paths))
+	for i, path := range paths {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read CA file: %s", err)
+		}
+		pems[i] = pem
+	}
+	pool, err := RootCAsFromPEM(pems...)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to load CA files: %s", err)
+	}
+	return pool, nil
+}
+
+/*--- Private Routines ---*/
+
+// Returns the TLSConfig with the TLSServerName/TLSRootCAs applied. It's
+// cloned rather than modified as callers may share it between ConnConfs.
+func (cc ConnConf) tlsConfig() *tls.Config {
+	cfg := cc.TLSConfig
+	if cfg == nil {
+		cfg = &tls.Config{}
+	}
+	if cc.TLSServerName == "" && cc.TLSRootCAs == nil {
+		return cfg
+	}
+	cfg = cfg.Clone()
+	if cc.TLSServerName != "" {
+		cfg.ServerName = cc.TLSServerName
+	}
+	if cc.TLSRootCAs != nil {
+		cfg.RootCAs = cc.TLSRootCAs
+	}
+	return cfg
+}
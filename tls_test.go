@@ -0,0 +1,60 @@
+package exasol
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func (s *testSuite) TestTLSServerName() {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		DNSNames:              []string{"exasol.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	s.Require().NoError(err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+
+	pool, err := RootCAsFromPEM(certPEM)
+	s.NoError(err)
+	_, err = RootCAsFromPEM([]byte("junk"))
+	s.EqualError(err, "No certificates found in PEM 0")
+
+	path := filepath.Join(s.T().TempDir(), "ca.pem")
+	s.NoError(os.WriteFile(path, certPEM, 0600))
+	fromFile, err := RootCAsFromFiles(path)
+	s.NoError(err)
+	s.True(pool.Equal(fromFile))
+	_, err = RootCAsFromFiles(path + ".missing")
+	s.ErrorContains(err, "Unable to read CA file")
+
+	cert, _ := x509.ParseCertificate(der)
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: "exasol.example.com", Roots: pool})
+	s.NoError(err, "The pool verifies the load balancer's certificate")
+
+	base := &tls.Config{MinVersion: tls.VersionTLS12}
+	conf := ConnConf{TLSConfig: base}
+	s.Same(base, conf.tlsConfig(), "Untouched without overrides")
+	s.NotNil(ConnConf{}.tlsConfig())
+
+	conf.TLSServerName = "exasol.example.com"
+	conf.TLSRootCAs = pool
+	cfg := conf.tlsConfig()
+	s.Equal("exasol.example.com", cfg.ServerName)
+	s.Same(pool, cfg.RootCAs)
+	s.Equal(uint16(tls.VersionTLS12), cfg.MinVersion)
+	s.Empty(base.ServerName, "The shared config isn't modified")
+}
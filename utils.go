@@ -15,11 +15,17 @@
 package exasol
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 var keywordLock sync.RWMutex
@@ -48,20 +54,24 @@ func (c *Conn) QuoteIdent(ident string, args ...interface{}) string {
 		return ident
 	}
 
-	if keywords == nil {
+	keywordLock.RLock()
+	kw := keywords
+	keywordLock.RUnlock()
+	if kw == nil {
 		keywordLock.Lock()
 		if keywords == nil {
-			kw := map[string]bool{}
+			m := map[string]bool{}
 			sql := "SELECT LOWER(keyword) FROM sys.exa_sql_keywords WHERE reserved"
 			kwRes, _ := c.FetchChan(sql)
 			for col := range kwRes {
-				kw[col[0].(string)] = true
+				m[col[0].(string)] = true
 			}
-			keywords = kw
+			keywords = m
 		}
+		kw = keywords
 		keywordLock.Unlock()
 	}
-	_, isKeyword := keywords[strings.ToLower(ident)]
+	_, isKeyword := kw[strings.ToLower(ident)]
 	if isKeyword {
 		if lowerKeywords {
 			return fmt.Sprintf(`[%s]`, strings.ToLower(ident))
@@ -100,6 +110,84 @@ func Transpose(matrix [][]interface{}) [][]interface{} {
 	return ret
 }
 
+type StatementKind int
+
+const (
+	StmtUnknown StatementKind = iota
+	StmtSelect
+	StmtDML         // INSERT, UPDATE, DELETE, MERGE, TRUNCATE
+	StmtDDL         // CREATE, ALTER, DROP, RENAME, COMMENT, GRANT, REVOKE
+	StmtImport      // IMPORT
+	StmtExport      // EXPORT
+	StmtScript      // EXECUTE SCRIPT
+	StmtTransaction // COMMIT, ROLLBACK
+	StmtSession     // Session/system commands e.g. OPEN SCHEMA, ALTER SESSION, KILL
+)
+
+func (k StatementKind) String() string {
+	switch k {
+	case StmtSelect:
+		return "SELECT"
+	case StmtDML:
+		return "DML"
+	case StmtDDL:
+		return "DDL"
+	case StmtImport:
+		return "IMPORT"
+	case StmtExport:
+		return "EXPORT"
+	case StmtScript:
+		return "SCRIPT"
+	case StmtTransaction:
+		return "TRANSACTION"
+	case StmtSession:
+		return "SESSION"
+	}
+	return "UNKNOWN"
+}
+
+var leadingCommentsRE = regexp.MustCompile(`^(\s+|--[^\n]*(\n|$)|/\*(?s:.*?)\*/|\()*`)
+var leadingWordsRE = regexp.MustCompile(`^([A-Za-z_]+)(\s+([A-Za-z_]+))?`)
+
+// ClassifyStatement returns what kind of statement the SQL is
+// based on its leading keyword(s). Leading comments are ignored.
+func ClassifyStatement(sql string) StatementKind {
+	sql = leadingCommentsRE.ReplaceAllString(sql, "")
+	words := leadingWordsRE.FindStringSubmatch(sql)
+	if words == nil {
+		return StmtUnknown
+	}
+	first := strings.ToUpper(words[1])
+	second := strings.ToUpper(words[3])
+
+	switch first {
+	case "SELECT", "WITH", "VALUES", "DESC", "DESCRIBE":
+		return StmtSelect
+	case "INSERT", "UPDATE", "DELETE", "MERGE", "TRUNCATE":
+		return StmtDML
+	case "IMPORT":
+		return StmtImport
+	case "EXPORT":
+		return StmtExport
+	case "EXECUTE":
+		if second == "SCRIPT" {
+			return StmtScript
+		}
+	case "COMMIT", "ROLLBACK":
+		return StmtTransaction
+	case "OPEN", "CLOSE", "FLUSH", "KILL", "RECOMPRESS", "REORGANIZE", "PRELOAD":
+		return StmtSession
+	case "ALTER":
+		if second == "SESSION" {
+			return StmtSession
+		}
+		return StmtDDL
+	case "CREATE", "DROP", "RENAME", "COMMENT", "GRANT", "REVOKE":
+		return StmtDDL
+	}
+	return StmtUnknown
+}
+
 /*--- Private Routines ---*/
 
 func (c *Conn) error(text string) error {
@@ -118,13 +206,260 @@ func (c *Conn) errorf(format string, args ...interface{}) error {
 	return err
 }
 
-func transposeToChan(ch chan<- []interface{}, matrix [][]interface{}) {
+// Returns false if it was stopped early via done
+func transposeToChan(
+	ch chan<- []interface{},
+	matrix [][]interface{},
+	pool *sync.Pool,
+	done <-chan struct{},
+) bool {
 	// matrix is columnar ... this transposes it to rowular
 	for row := range matrix[0] {
-		ret := make([]interface{}, len(matrix))
+		var ret []interface{}
+		if pool != nil {
+			ret, _ = pool.Get().([]interface{})
+		}
+		if cap(ret) < len(matrix) {
+			ret = make([]interface{}, len(matrix))
+		} else {
+			ret = ret[:len(matrix)]
+		}
 		for col := range matrix {
 			ret[col] = matrix[col][row]
 		}
-		ch <- ret
+		select {
+		case ch <- ret:
+		case <-done:
+			return false
+		}
+	}
+	return true
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+var redactSQLRE = regexp.MustCompile(`(?i)((?:IDENTIFIED\s+BY|REPLACE)\s+)("(?:[^"]|"")*"|'(?:[^']|'')*')`)
+
+// Redacts passwords (e.g. from CREATE/ALTER USER) so that the SQL can be logged
+func redactSQL(sql string) string {
+	return redactSQLRE.ReplaceAllString(sql, "$1<redacted>")
+}
+
+// Strips whitespace and any trailing semicolon so the
+// statement can be embedded within another statement
+func trimStmt(sql string) string {
+	return strings.TrimRight(strings.TrimSpace(sql), "; \t\r\n")
+}
+
+// Maps each column to the index of the struct field it should be decoded
+// into (or nil if there is no such field). See QueryStruct for the rules.
+func structFieldMap(t reflect.Type, cols []column) ([][]int, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%v is not a struct", t)
+	}
+	normalize := func(name string) string {
+		return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+	}
+	byTag := map[string][]int{}
+	byName := map[string][]int{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		tag := f.Tag.Get("exasol")
+		if tag == "-" {
+			continue
+		} else if tag != "" {
+			byTag[strings.ToLower(tag)] = f.Index
+		} else {
+			byName[normalize(f.Name)] = f.Index
+		}
+	}
+
+	fields := make([][]int, len(cols))
+	for i, col := range cols {
+		if idx, ok := byTag[strings.ToLower(col.Name)]; ok {
+			fields[i] = idx
+		} else if idx, ok := byName[normalize(col.Name)]; ok {
+			fields[i] = idx
+		}
+	}
+	return fields, nil
+}
+
+// Assigns a value as decoded from the websocket API's JSON to dest,
+// converting it as necessary. NULLs result in dest's zero value.
+// DATEs and TIMESTAMPs are parsed in loc (see Conn.timeLoc).
+func convertAssign(dest reflect.Value, src interface{}, loc *time.Location) error {
+	if src == nil {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dest.Type()) {
+		dest.Set(sv)
+		return nil
+	}
+
+	switch dk := dest.Kind(); {
+	case dest.Type() == timeType:
+		if s, ok := src.(string); ok {
+			t, err := parseTime(s, loc)
+			if err != nil {
+				return err
+			}
+			dest.Set(reflect.ValueOf(t))
+			return nil
+		}
+	case dk == reflect.Ptr:
+		v := reflect.New(dest.Type().Elem())
+		if err := convertAssign(v.Elem(), src, loc); err != nil {
+			return err
+		}
+		dest.Set(v)
+		return nil
+	case dk == reflect.String:
+		switch s := src.(type) {
+		case float64:
+			dest.SetString(strconv.FormatFloat(s, 'f', -1, 64))
+			return nil
+		case int64:
+			dest.SetString(strconv.FormatInt(s, 10))
+			return nil
+		case json.Number:
+			dest.SetString(s.String())
+			return nil
+		case bool:
+			dest.SetString(strconv.FormatBool(s))
+			return nil
+		}
+	case isNumericKind(dk):
+		switch s := src.(type) {
+		case float64, int64:
+			return setNumber(dest, s)
+		case string:
+			// Large DECIMALs are returned as strings
+			return setNumericString(dest, s)
+		case json.Number:
+			return setNumericString(dest, s.String())
+		}
+	case dk == reflect.Bool:
+		if s, ok := src.(string); ok {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return fmt.Errorf("cannot convert %q to %s", s, dest.Type())
+			}
+			dest.SetBool(b)
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot convert %T to %s", src, dest.Type())
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Exasol returns DATEs and TIMESTAMPs as strings in these formats
+// (without a time zone so it's up to the caller to say which it's in)
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	} {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot convert %q to time.Time", s)
+}
+
+// Unlike reflect.Value.Convert this errors rather than truncating
+// fractions or wrapping values that don't fit (e.g. 300 into a uint8)
+func setNumber(dest reflect.Value, src interface{}) error {
+	var f float64
+	var i int64
+	isInt := false
+	switch s := src.(type) {
+	case int64:
+		i, f, isInt = s, float64(s), true
+	case float64:
+		f = s
+	}
+	fail := fmt.Errorf("cannot convert %v to %s", src, dest.Type())
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isInt {
+			// 1<<63 isn't representable but is the first float64 that overflows
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= 1<<63 {
+				return fail
+			}
+			i = int64(f)
+		}
+		if dest.OverflowInt(i) {
+			return fail
+		}
+		dest.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if isInt {
+			if i < 0 {
+				return fail
+			}
+			u = uint64(i)
+		} else {
+			if f != math.Trunc(f) || f < 0 || f >= 1<<64 {
+				return fail
+			}
+			u = uint64(f)
+		}
+		if dest.OverflowUint(u) {
+			return fail
+		}
+		dest.SetUint(u)
+	default:
+		if dest.OverflowFloat(f) {
+			return fail
+		}
+		dest.SetFloat(f)
+	}
+	return nil
+}
+
+func setNumericString(dest reflect.Value, s string) error {
+	var err error
+	switch dest.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, dest.Type().Bits())
+		dest.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(s, 10, dest.Type().Bits())
+		dest.SetUint(u)
+	default:
+		var f float64
+		f, err = strconv.ParseFloat(s, dest.Type().Bits())
+		dest.SetFloat(f)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot convert %q to %s", s, dest.Type())
 	}
+	return nil
 }
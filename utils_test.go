@@ -1,5 +1,12 @@
 package exasol
 
+import (
+	"context"
+	"net"
+	"reflect"
+	"time"
+)
+
 func (s *testSuite) TestQuoteIdent() {
 	exa := s.exaConn
 	s.Equal("[test]", exa.QuoteIdent("[test]"), "Already quoted")
@@ -15,8 +22,110 @@ func (s *testSuite) TestQuoteStr() {
 	s.Equal("my''str", QuoteStr("my'str"))
 }
 
+func (s *testSuite) TestConvertAssignNumbers() {
+	assign := func(dest interface{}, src interface{}) error {
+		return convertAssign(reflect.ValueOf(dest).Elem(), src, time.UTC)
+	}
+	var i int
+	var u8 uint8
+	var i8 int8
+	var f32 float32
+	s.NoError(assign(&i, float64(42)))
+	s.Equal(42, i)
+	s.NoError(assign(&u8, int64(255)))
+	s.Equal(uint8(255), u8)
+	s.NoError(assign(&f32, 1.5))
+	s.Equal(float32(1.5), f32)
+
+	s.EqualError(assign(&i, 1.7), "cannot convert 1.7 to int", "Not truncated")
+	s.EqualError(assign(&u8, int64(300)), "cannot convert 300 to uint8", "Not wrapped")
+	s.EqualError(assign(&u8, float64(-1)), "cannot convert -1 to uint8")
+	s.EqualError(assign(&i8, float64(128)), "cannot convert 128 to int8")
+	s.EqualError(assign(&i, 1e20), "cannot convert 1e+20 to int")
+	s.EqualError(assign(&f32, 1e40), "cannot convert 1e+40 to float32")
+}
+
 func (s *testSuite) TestTranspose() {
 	data := [][]interface{}{{1, "a"}, {2, "b"}, {3, "c"}}
 	expect := [][]interface{}{{1, 2, 3}, {"a", "b", "c"}}
 	s.Equal(expect, Transpose(data))
 }
+
+func (s *testSuite) TestClassifyStatement() {
+	tests := map[string]StatementKind{
+		"SELECT 1":                            StmtSelect,
+		"(SELECT 1) UNION (SELECT 2)":         StmtSelect,
+		"insert into foo values (1)":          StmtDML,
+		"MERGE INTO foo USING bar ON 1=1":     StmtDML,
+		"CREATE TABLE foo (id INT)":           StmtDDL,
+		"ALTER TABLE foo ADD COLUMN x INT":    StmtDDL,
+		"ALTER SESSION SET TIME_ZONE = 'UTC'": StmtSession,
+		"OPEN SCHEMA test":                    StmtSession,
+		"IMPORT INTO foo FROM CSV AT '%s'":    StmtImport,
+		"EXPORT foo INTO CSV AT '%s'":         StmtExport,
+		"EXECUTE SCRIPT my_script()":          StmtScript,
+		"COMMIT":                              StmtTransaction,
+		"rollback;":                           StmtTransaction,
+		"ASDF":                                StmtUnknown,
+		"":                                    StmtUnknown,
+	}
+	for sql, kind := range tests {
+		s.Equal(kind, ClassifyStatement(sql), sql)
+	}
+	s.Equal(StmtSelect, ClassifyStatement(`
+		-- A comment
+		/* and
+		   another */
+		WITH x AS (SELECT 1) SELECT * FROM x
+	`), "Leading comments")
+	s.Equal("DML", StmtDML.String())
+}
+
+func (s *testSuite) TestCompareVersions() {
+	s.Equal(0, CompareVersions("7.1", "7.1.0"))
+	s.Equal(-1, CompareVersions("7.1.9", "7.10"))
+	s.Equal(1, CompareVersions("8.0.0-rc1", "7.1.25"))
+	s.Equal(-1, CompareVersions("", "6"))
+}
+
+func (s *testSuite) TestCapabilities() {
+	exa := s.exaConn
+	s.True(exa.ProtocolAtLeast(1))
+	s.False(exa.ProtocolAtLeast(99))
+	s.True(exa.ReleaseAtLeast("6.0"))
+	s.Equal(exa.Metadata.MaxVarcharLength, exa.MaxVarcharLength())
+	s.Greater(exa.MaxIdentifierLength(), uint64(0))
+	s.Equal(exa.ReleaseAtLeast("7"), exa.SupportsHashtype())
+
+	s.False((&Conn{}).ReleaseAtLeast("1"), "No metadata")
+}
+
+func (s *testSuite) TestRefreshMetadata() {
+	exa := s.exaConn
+	info, err := exa.RefreshMetadata()
+	if s.NoError(err) {
+		s.Greater(info.NumNodes, 0)
+		s.NotEmpty(info.ReleaseVersion)
+		s.NotNil(info.Attributes)
+		s.Same(info, exa.ServerInfo, "Cached on the Conn")
+	}
+}
+
+func (s *testSuite) TestNodes() {
+	nodes, err := s.exaConn.Nodes()
+	if s.NoError(err) && s.NotEmpty(nodes) {
+		s.Equal(s.exaConn.Conf.Port, nodes[0].Port)
+		s.NotEmpty(nodes[0].Host)
+	}
+	s.Equal([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}, expandHostRange("10.0.0.1..3"))
+	s.Equal([]string{"myhost"}, expandHostRange("myhost"))
+
+	c := &Conn{host: "localhost", ctx: context.Background()}
+	ip, err := c.hostIP()
+	if s.NoError(err) {
+		s.NotNil(net.ParseIP(ip), "Hostnames are resolved")
+	}
+	c.host = "10.0.0.2"
+	ip, _ = c.hostIP()
+	s.Equal("10.0.0.2", ip)
+}
@@ -0,0 +1,99 @@
+/*
+	Sanity checks of the shape of the server's responses
+	(see ConnConf.StrictResponses)
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import "fmt"
+
+// ProtocolError is returned when the server's response
+// doesn't have the shape that the websocket API specifies
+type ProtocolError struct {
+	Command string
+	Problem string
+}
+
+func (e *ProtocolError) Error() string {
+	return fmt.Sprintf("Unexpected response to %s: %s", e.Command, e.Problem)
+}
+
+/*--- Private Routines ---*/
+
+// Problems that would make the response unusable are always returned.
+// Other inconsistencies are only returned with ConnConf.StrictResponses
+// and otherwise just logged so that newer servers that stretch the
+// protocol a bit don't break us.
+func (c *Conn) validateExecData(command string, d *execData) error {
+	if d == nil {
+		return &ProtocolError{command, "missing responseData"}
+	}
+	var problems []string
+	if int(d.NumResults) != len(d.Results) {
+		problems = append(problems, fmt.Sprintf(
+			"numResults is %d but got %d results", d.NumResults, len(d.Results),
+		))
+	}
+	for i, r := range d.Results {
+		switch r.ResultType {
+		case rowCountType:
+		case resultSetType:
+			if r.ResultSet == nil {
+				return &ProtocolError{command, fmt.Sprintf("result %d is missing its resultSet", i+1)}
+			}
+			problems = append(problems, validateResultSet(i, r.ResultSet)...)
+		default:
+			problems = append(problems, fmt.Sprintf("result %d has unknown resultType %q", i+1, r.ResultType))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	if c.Conf.StrictResponses {
+		return &ProtocolError{command, problems[0]}
+	}
+	for _, p := range problems {
+		c.log.Warningf("Unexpected response to %s: %s", command, p)
+	}
+	return nil
+}
+
+func validateResultSet(i int, rs *resultSet) (problems []string) {
+	if rs.NumColumns != len(rs.Columns) {
+		problems = append(problems, fmt.Sprintf(
+			"result %d has numColumns %d but %d columns", i+1, rs.NumColumns, len(rs.Columns),
+		))
+	}
+	rowsInMessage := 0
+	if len(rs.Data) > 0 {
+		if len(rs.Data) != rs.NumColumns {
+			problems = append(problems, fmt.Sprintf(
+				"result %d has data for %d columns but numColumns is %d", i+1, len(rs.Data), rs.NumColumns,
+			))
+		}
+		rowsInMessage = len(rs.Data[0])
+		for col, colData := range rs.Data {
+			if len(colData) != rowsInMessage {
+				problems = append(problems, fmt.Sprintf(
+					"result %d column %d has %d rows but column 1 has %d", i+1, col+1, len(colData), rowsInMessage,
+				))
+			}
+		}
+	}
+	if rs.ResultSetHandle == 0 && uint64(rowsInMessage) < rs.NumRows {
+		problems = append(problems, fmt.Sprintf(
+			"result %d has %d of %d rows but no resultSetHandle", i+1, rowsInMessage, rs.NumRows,
+		))
+	}
+	return problems
+}
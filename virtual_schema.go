@@ -0,0 +1,144 @@
+/*
+	Helpers for managing virtual schemas
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// VirtualSchema is a row of EXA_ALL_VIRTUAL_SCHEMAS
+type VirtualSchema struct {
+	SchemaName          string
+	SchemaOwner         string
+	AdapterScriptSchema string
+	AdapterScriptName   string
+	LastRefresh         *time.Time
+	LastRefreshBy       *string
+}
+
+// CreateVirtualSchema creates a virtual schema using the adapter script
+// (given as schema.script) with the given adapter properties
+func (c *Conn) CreateVirtualSchema(name, adapterScript string, props map[string]string) error {
+	sql := fmt.Sprintf(
+		"CREATE VIRTUAL SCHEMA %s USING %s", c.QuoteIdent(name), c.quoteObject(adapterScript),
+	)
+	if len(props) > 0 {
+		with, err := c.adapterProps(props)
+		if err != nil {
+			return err
+		}
+		sql += " WITH " + with
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// RefreshVirtualSchema refreshes the metadata of the given
+// tables or, if none are given, of the whole virtual schema
+func (c *Conn) RefreshVirtualSchema(name string, tables ...string) error {
+	sql := "ALTER VIRTUAL SCHEMA " + c.QuoteIdent(name) + " REFRESH"
+	if len(tables) > 0 {
+		quoted := make([]string, len(tables))
+		for i, t := range tables {
+			quoted[i] = c.QuoteIdent(t)
+		}
+		sql += " TABLES " + strings.Join(quoted, ", ")
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// SetVirtualSchemaProperties changes adapter properties.
+// An empty value removes the property.
+func (c *Conn) SetVirtualSchemaProperties(name string, props map[string]string) error {
+	if len(props) == 0 {
+		return c.error("SetVirtualSchemaProperties requires at least one property")
+	}
+	set, err := c.adapterProps(props)
+	if err != nil {
+		return err
+	}
+	_, err = c.Execute("ALTER VIRTUAL SCHEMA " + c.QuoteIdent(name) + " SET " + set)
+	return err
+}
+
+// DropVirtualSchema drops a virtual schema. With cascade it's
+// dropped even if there are views etc. that depend on it.
+func (c *Conn) DropVirtualSchema(name string, cascade bool) error {
+	sql := "DROP VIRTUAL SCHEMA IF EXISTS " + c.QuoteIdent(name)
+	if cascade {
+		sql += " CASCADE"
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// VirtualSchemas lists the virtual schemas the user has access to
+func (c *Conn) VirtualSchemas() ([]VirtualSchema, error) {
+	return collect(QueryStruct[VirtualSchema](c, `
+		SELECT schema_name, schema_owner, adapter_script_schema,
+			adapter_script_name, last_refresh, last_refresh_by
+		FROM exa_all_virtual_schemas ORDER BY schema_name
+	`))
+}
+
+// VirtualSchemaProperties returns the adapter properties of a virtual
+// schema. The name is as stored i.e. upper-cased unless created quoted.
+func (c *Conn) VirtualSchemaProperties(name string) (map[string]string, error) {
+	rows, err := c.FetchSlice(`
+		SELECT property_name, property_value
+		FROM exa_all_virtual_schema_properties
+		WHERE schema_name = ?
+	`, []interface{}{name})
+	if err != nil {
+		return nil, err
+	}
+	props := make(map[string]string, len(rows))
+	for _, row := range rows {
+		key, _ := row[0].(string)
+		val, _ := row[1].(string)
+		props[key] = val
+	}
+	return props, nil
+}
+
+/*--- Private Routines ---*/
+
+var adapterPropRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Formats the properties as KEY = 'value' pairs in a stable order
+func (c *Conn) adapterProps(props map[string]string) (string, error) {
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		if !adapterPropRE.MatchString(k) {
+			return "", c.errorf("Invalid adapter property name: %q", k)
+		}
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.ToUpper(keys[i]) < strings.ToUpper(keys[j])
+	})
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s = '%s'", strings.ToUpper(k), QuoteStr(props[k]))
+	}
+	return strings.Join(pairs, " "), nil
+}
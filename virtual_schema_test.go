@@ -0,0 +1,28 @@
+package exasol
+
+func (s *testSuite) TestVirtualSchemas() {
+	exa := s.exaConn
+	props, err := exa.adapterProps(map[string]string{
+		"connection_name": "MY_CONN", "SCHEMA_NAME": "it's",
+	})
+	s.NoError(err)
+	s.Equal(`CONNECTION_NAME = 'MY_CONN' SCHEMA_NAME = 'it''s'`, props)
+
+	exa.Conf.SuppressError = true
+	_, err = exa.adapterProps(map[string]string{"BAD NAME": "x"})
+	s.Error(err)
+	s.Error(exa.SetVirtualSchemaProperties("vs", nil))
+
+	// Creating one requires an adapter script (and usually a
+	// connection) so just check that the read side works
+	schemas, err := exa.VirtualSchemas()
+	s.NoError(err)
+	for _, vs := range schemas {
+		_, err = exa.VirtualSchemaProperties(vs.SchemaName)
+		s.NoError(err)
+	}
+	props2, err := exa.VirtualSchemaProperties("NOT_A_SCHEMA")
+	s.NoError(err)
+	s.Empty(props2)
+	s.NoError(exa.DropVirtualSchema("not_a_schema", true), "IF EXISTS")
+}
@@ -0,0 +1,85 @@
+/*
+	Warming up a connection by preparing its critical statements ahead of
+	time e.g. after a deployment or failover, before traffic arrives
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+/*--- Public Interface ---*/
+
+// WarmUpResult is the outcome of preparing one of WarmUp's statements
+type WarmUpResult struct {
+	SQL      string
+	Duration time.Duration
+	Err      error
+}
+
+// WarmUp prepares each of the statements (without executing them) so
+// that Exasol has compiled them and, if ConnConf.CachePrepStmts is set,
+// their handles are cached for when they're executed with binds. Exasol
+// has no EXPLAIN so preparing is as close as it gets to warming its
+// plan caches. As with Validate the optional schema allows the
+// statements to use non-schema-qualified identifiers.
+//
+// All the statements are tried even if some fail. The error returned
+// then says how many did with each result's Err saying why.
+func (c *Conn) WarmUp(statements []string, schema ...string) ([]WarmUpResult, error) {
+	var s string
+	if len(schema) > 0 {
+		s = schema[0]
+	}
+	results := make([]WarmUpResult, len(statements))
+	failed := 0
+	for i, sql := range statements {
+		start := time.Now()
+		err := c.warmUp(s, sql)
+		results[i] = WarmUpResult{SQL: sql, Duration: time.Since(start), Err: err}
+		if err != nil {
+			c.log.Warning("Unable to warm up statement:", err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("Unable to warm up %d of the %d statements", failed, len(statements))
+	}
+	return results, nil
+}
+
+// WarmUpFile is WarmUp for the statements in the file (see SplitStatements)
+func (c *Conn) WarmUpFile(path string, schema ...string) ([]WarmUpResult, error) {
+	script, err := os.ReadFile(path)
+	if err != nil {
+		return nil, c.errorf("Unable to read warm-up statements: %s", err)
+	}
+	return c.WarmUp(SplitStatements(string(script)), schema...)
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) warmUp(schema, sql string) error {
+	if c.Conf.CachePrepStmts {
+		_, err := c.getPrepStmt(schema, sql)
+		return err
+	}
+	ps, err := c.createPrepStmt(schema, sql)
+	if err != nil {
+		return err
+	}
+	return c.closePrepStmt(ps.sth)
+}
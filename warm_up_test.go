@@ -0,0 +1,45 @@
+package exasol
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func (s *testSuite) TestWarmUp() {
+	s.execute("CREATE TABLE foo (id INT, name VARCHAR(10))")
+	conf := s.connConf()
+	conf.CachePrepStmts = true
+	exa, err := Connect(conf)
+	if !s.NoError(err) {
+		return
+	}
+	defer exa.Disconnect()
+
+	results, err := exa.WarmUp([]string{
+		"SELECT name FROM foo WHERE id = ?",
+		"INSERT INTO foo VALUES (?, ?)",
+		"SELECT * FROM nope",
+	}, s.schema)
+	s.EqualError(err, "Unable to warm up 1 of the 3 statements")
+	if s.Len(results, 3) {
+		s.NoError(results[0].Err)
+		s.NoError(results[1].Err)
+		s.Error(results[2].Err)
+		s.Equal("INSERT INTO foo VALUES (?, ?)", results[1].SQL)
+	}
+	s.Equal(2, exa.GetStats()["StmtCacheLen"])
+
+	// Executing them hits the cache
+	_, err = exa.Execute("INSERT INTO foo VALUES (?, ?)", []interface{}{1, "a"}, s.schema)
+	s.NoError(err)
+	s.Equal(2, exa.GetStats()["StmtCacheMiss"])
+
+	path := filepath.Join(s.T().TempDir(), "warm.sql")
+	s.NoError(os.WriteFile(path, []byte("SELECT 1;\n-- comment\nSELECT id FROM foo;"), 0600))
+	results, err = s.exaConn.WarmUpFile(path, s.schema)
+	s.NoError(err)
+	s.Len(results, 2)
+
+	_, err = s.exaConn.WarmUpFile(filepath.Join(s.T().TempDir(), "missing.sql"))
+	s.ErrorContains(err, "Unable to read warm-up statements")
+}
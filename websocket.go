@@ -13,46 +13,201 @@
 package exasol
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"math/rand"
+	"net"
 	"net/url"
 	"reflect"
 	"regexp"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 )
 
+// Sets up c.wsh. This is the one place websocket handlers are created,
+// connected and wrapped so the options apply whichever way we connect.
 func (c *Conn) wsConnect() (err error) {
-	host := c.Conf.Host
-
-	isIPRange := regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)\.(\d+)\.\.(\d+)$`)
-	if isIPRange.MatchString(host) {
-		// This is an IP range so choose a node at random to connect to.
-		// If that connection fails try another one.
-		ipRange := isIPRange.FindStringSubmatch(host)
-		fromN, _ := strconv.ParseInt(ipRange[4], 10, 32)
-		toN, _ := strconv.ParseInt(ipRange[5], 10, 32)
-		ips := []string{}
-		for i := fromN; i <= toN; i++ {
-			ips = append(ips, fmt.Sprintf("%s.%s.%s.%d", ipRange[1], ipRange[2], ipRange[3], i))
+	if c.wsh == nil {
+		c.wsh = newDefaultWSHandler(c.dialer())
+	}
+	if _, ok := c.wsh.(FrameWSHandler); !ok && (c.Conf.Compressor != nil || c.Conf.MaxResponseSize > 0) {
+		return fmt.Errorf("The WSHandler must implement FrameWSHandler to use a Compressor or MaxResponseSize")
+	}
+	defer func() {
+		if err == nil && c.Conf.Compressor != nil {
+			c.wsh = &compressWSHandler{FrameWSHandler: c.wsh.(FrameWSHandler), comp: c.Conf.Compressor}
+		}
+		if err == nil && c.Conf.MaxResponseSize > 0 {
+			c.wsh = &limitWSHandler{FrameWSHandler: c.wsh.(FrameWSHandler), limit: c.Conf.MaxResponseSize}
+		}
+		if err == nil && c.Conf.WireLog != nil {
+			c.wsh = &tapWSHandler{WSHandler: c.wsh, w: c.Conf.WireLog}
+		}
+	}()
+
+	ips := expandHostRange(c.Conf.Host)
+	if len(ips) > 1 {
+		// This is an IP range so choose a node to connect to.
+		// If that connection fails try the others.
+		ips = c.orderHosts(ips)
+	}
+	if len(ips) == 1 {
+		err = c.wsConnectHost(c.wsh, ips[0])
+		if err == nil {
+			c.host = ips[0]
+		}
+		return err
+	}
+	// Custom handlers can only be connected once at a time
+	if c.Conf.ParallelConnect && c.Conf.WSHandler == nil {
+		return c.wsConnectParallel(ips)
+	}
+
+	// Report why every host failed, not just the last one tried
+	var errs []error
+	for _, ip := range ips {
+		err = c.wsConnectHost(c.wsh, ip)
+		if err == nil {
+			c.host = ip
+			return nil
+		}
+		c.log.Debugf("Unable to connect to %s: %s", ip, err)
+		errs = append(errs, fmt.Errorf("%s: %w", ip, err))
+	}
+	return hostsError(errs)
+}
+
+// Connects to all the hosts at once keeping whichever connects first
+func (c *Conn) wsConnectParallel(ips []string) error {
+	type attempt struct {
+		host string
+		wsh  WSHandler
+		err  error
+	}
+	attempts := make(chan attempt, len(ips))
+	for _, ip := range ips {
+		go func(ip string) {
+			wsh := newDefaultWSHandler(c.dialer())
+			err := c.wsConnectHost(wsh, ip)
+			if err != nil {
+				c.log.Debugf("Unable to connect to %s: %s", ip, err)
+				err = fmt.Errorf("%s: %w", ip, err)
+			}
+			attempts <- attempt{ip, wsh, err}
+		}(ip)
+	}
+
+	var errs []error
+	for range ips {
+		a := <-attempts
+		if a.err == nil {
+			c.wsh = a.wsh
+			c.host = a.host
+			// Close the losers as they come in
+			go func(remaining int) {
+				for ; remaining > 0; remaining-- {
+					if a := <-attempts; a.err == nil {
+						a.wsh.Close()
+					}
+				}
+			}(len(ips) - len(errs) - 1)
+			return nil
 		}
+		errs = append(errs, a.err)
+	}
+	return hostsError(errs)
+}
+
+func hostsError(errs []error) error {
+	return fmt.Errorf("all %d hosts failed:\n%w", len(errs), errors.Join(errs...))
+}
+
+type HostSelection int
+
+const (
+	HostRandom     HostSelection = iota // Shuffle the hosts upon every connect
+	HostRoundRobin                      // Start at the host after the one the last connect (to the same range) started at
+	HostSticky                          // Always start at the same host for a given client name, tags and username
+	HostLocal                           // Start at a host with an IP of this machine (i.e. co-located) else random
+)
+
+var roundRobinMux sync.Mutex
+var roundRobinNext = map[string]int{}
+
+// Orders the hosts in the sequence they should be tried per ConnConf.HostSelection
+func (c *Conn) orderHosts(ips []string) []string {
+	start := -1
+	if c.Conf.PreferredHost != "" {
+		start = slices.Index(ips, c.Conf.PreferredHost)
+	} else if c.Conf.HostSelection == HostLocal {
+		start = slices.IndexFunc(ips, isLocalIP)
+	}
+	if start >= 0 {
+		// Fail over to the others at random
+		rest := slices.Delete(slices.Clone(ips), start, start+1)
+		rand.Shuffle(len(rest), func(i, j int) { rest[i], rest[j] = rest[j], rest[i] })
+		return append([]string{ips[start]}, rest...)
+	}
+
+	start = 0
+	switch c.Conf.HostSelection {
+	case HostRoundRobin:
+		roundRobinMux.Lock()
+		start = roundRobinNext[c.Conf.Host] % len(ips)
+		roundRobinNext[c.Conf.Host] = start + 1
+		roundRobinMux.Unlock()
+	case HostSticky:
+		h := fnv.New32a()
+		h.Write([]byte(c.clientName() + "\x00" + c.Conf.Username))
+		start = int(h.Sum32() % uint32(len(ips)))
+	default:
 		rand.Seed(time.Now().UnixNano())
 		rand.Shuffle(len(ips), func(i, j int) { ips[i], ips[j] = ips[j], ips[i] })
+		return ips
+	}
+	// Fail over to the subsequent hosts in order
+	ordered := make([]string, 0, len(ips))
+	ordered = append(ordered, ips[start:]...)
+	return append(ordered, ips[:start]...)
+}
 
-		for _, ip := range ips {
-			err = c.wsConnectHost(ip)
-			if err == nil {
-				break
-			}
+// Returns whether the IP is assigned to one of this machine's interfaces
+func isLocalIP(ip string) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.String() == ip {
+			return true
 		}
-	} else {
-		err = c.wsConnectHost(host)
 	}
+	return false
+}
+
+var isIPRange = regexp.MustCompile(`^(\d+)\.(\d+)\.(\d+)\.(\d+)\.\.(\d+)$`)
 
-	return err
+// Expands an IP range like 10.0.0.1..4 into the individual
+// IPs. Anything else is returned as the sole host.
+func expandHostRange(host string) []string {
+	if !isIPRange.MatchString(host) {
+		return []string{host}
+	}
+	ipRange := isIPRange.FindStringSubmatch(host)
+	fromN, _ := strconv.ParseInt(ipRange[4], 10, 32)
+	toN, _ := strconv.ParseInt(ipRange[5], 10, 32)
+	ips := []string{}
+	for i := fromN; i <= toN; i++ {
+		ips = append(ips, fmt.Sprintf("%s.%s.%s.%d", ipRange[1], ipRange[2], ipRange[3], i))
+	}
+	return ips
 }
 
-func (c *Conn) wsConnectHost(host string) error {
+func (c *Conn) wsConnectHost(wsh WSHandler, host string) error {
 	uri := fmt.Sprintf("%s:%d", host, c.Conf.Port)
 	scheme := "ws"
 	if c.Conf.TLSConfig != nil {
@@ -64,7 +219,29 @@ func (c *Conn) wsConnectHost(host string) error {
 	}
 	c.log.Debugf("Connecting to %s", u.String())
 
-	return c.wsh.Connect(u, c.Conf.TLSConfig, c.Conf.ConnectTimeout)
+	return wsh.Connect(u, c.Conf.TLSConfig, c.Conf.ConnectTimeout)
+}
+
+// ServerError is an exception returned by Exasol
+type ServerError struct {
+	Text    string
+	SQLCode string
+}
+
+func (e *ServerError) Error() string { return "Server Error: " + e.Text }
+
+// ResponseTooLargeError is returned when a response exceeds
+// ConnConf.MaxResponseSize. The connection remains usable.
+type ResponseTooLargeError struct {
+	Command string // The request's command e.g. fetch
+	Limit   int64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return fmt.Sprintf(
+		"The response to %s exceeded the MaxResponseSize of %d bytes"+
+			" (try lowering FetchOpts.MaxFetchBytes)", e.Command, e.Limit,
+	)
 }
 
 // Request and Response are pointers to structs representing the API JSON.
@@ -79,26 +256,55 @@ func (c *Conn) send(request, response interface{}) error {
 }
 
 func (c *Conn) asyncSend(request interface{}) (func(interface{}) error, error) {
+	if c.ctx != nil && c.ctx.Err() != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConnClosed, context.Cause(c.ctx))
+	}
+	c.guardSend()
+	c.idleMux.Lock()
+	if c.expiry != nil {
+		if id, _ := currentGoroutine(); id != c.expirer {
+			c.idleMux.Unlock()
+			return nil, fmt.Errorf("%w: %w", ErrConnClosed, c.expiry)
+		}
+	}
+	c.inFlight.Add(1)
+	c.idleMux.Unlock()
+	c.lastUsed.Store(time.Now().UnixNano())
 	err := c.wsh.WriteJSON(request)
 	if err != nil {
-		return nil, c.errorf("WebSocket API Error sending: %s", err)
+		c.inFlight.Add(-1)
+		err = c.errorf("WebSocket API Error sending: %s", err)
+		c.broken(err)
+		return nil, err
 	}
 
 	return func(response interface{}) error {
 		err = c.wsh.ReadJSON(response)
-		if err != nil {
+		c.lastUsed.Store(time.Now().UnixNano())
+		c.inFlight.Add(-1)
+		if errors.Is(err, errResponseTooLarge) {
+			command := reflect.Indirect(reflect.ValueOf(request)).FieldByName("Command")
+			return &ResponseTooLargeError{Command: command.String(), Limit: c.Conf.MaxResponseSize}
+		} else if err != nil {
 			if regexp.MustCompile(`abnormal closure`).
 				MatchString(err.Error()) {
-				return fmt.Errorf("Server terminated statement")
+				err = fmt.Errorf("Server terminated statement")
+			} else {
+				err = fmt.Errorf("WebSocket API Error recving: %s", err)
 			}
-			return fmt.Errorf("WebSocket API Error recving: %s", err)
+			c.broken(err)
+			return err
 		}
+		c.trackTx(request, response)
 		r := reflect.Indirect(reflect.ValueOf(response))
 		status := r.FieldByName("Status").String()
 		if status != "ok" {
-			err := reflect.Indirect(r.FieldByName("Exception")).
-				FieldByName("Text").String()
-			return fmt.Errorf("Server Error: %s", err)
+			serverErr := &ServerError{}
+			if e, ok := r.FieldByName("Exception").Interface().(*exception); ok && e != nil {
+				serverErr.Text = e.Text
+				serverErr.SQLCode = e.Sqlcode
+			}
+			return serverErr
 		}
 		return nil
 	}, nil
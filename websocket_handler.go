@@ -13,8 +13,16 @@
 package exasol
 
 import (
+	"bytes"
 	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"net/url"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -24,11 +32,12 @@ import (
 // and conforms to the WSHandler interface
 
 type defWSHandler struct {
-	ws *websocket.Conn
+	ws   *websocket.Conn
+	dial dialFunc // Optional e.g. for SSH tunneling
 }
 
-func newDefaultWSHandler() *defWSHandler {
-	return &defWSHandler{}
+func newDefaultWSHandler(dial dialFunc) *defWSHandler {
+	return &defWSHandler{dial: dial}
 }
 
 var defaultDialer = *websocket.DefaultDialer
@@ -39,16 +48,22 @@ func init() {
 }
 
 func (wsh *defWSHandler) Connect(url url.URL, tlsCfg *tls.Config, timeout time.Duration) error {
+	// Copy it so that concurrent Connects don't race on the settings
+	dialer := defaultDialer
 	if timeout != time.Duration(0) {
-		defaultDialer.HandshakeTimeout = timeout
+		dialer.HandshakeTimeout = timeout
+	}
+	dialer.TLSClientConfig = tlsCfg
+	if wsh.dial != nil {
+		// Unlike NetDial this is bounded by the HandshakeTimeout
+		dialer.NetDialContext = wsh.dial
 	}
-	defaultDialer.TLSClientConfig = tlsCfg
 
 	// According to documentation:
 	// > It is safe to call Dialer's methods concurrently.
-	ws, _, err := defaultDialer.Dial(url.String(), nil)
+	ws, resp, err := dialer.Dial(url.String(), nil)
 	if err != nil {
-		return err
+		return newConnectError(url, resp, err)
 	}
 
 	wsh.ws = ws
@@ -56,9 +71,148 @@ func (wsh *defWSHandler) Connect(url url.URL, tlsCfg *tls.Config, timeout time.D
 }
 
 func (wsh *defWSHandler) WriteJSON(req interface{}) error { return wsh.ws.WriteJSON(req) }
-func (wsh *defWSHandler) ReadJSON(resp interface{}) error { return wsh.ws.ReadJSON(resp) }
-func (wsh *defWSHandler) EnableCompression(e bool)        { wsh.ws.EnableWriteCompression(e) }
-func (wsh *defWSHandler) Close() {
-	wsh.ws.Close()
-	wsh.ws = nil
+func (wsh *defWSHandler) ReadJSON(resp interface{}) error {
+	_, r, err := wsh.ws.NextReader()
+	if err != nil {
+		return err
+	}
+	err = decodeJSON(r, resp)
+	if err == io.EOF {
+		// As per websocket.Conn.ReadJSON since a message can't be empty
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+func (wsh *defWSHandler) EnableCompression(e bool) { wsh.ws.EnableWriteCompression(e) }
+
+func (wsh *defWSHandler) NextWriter() (io.WriteCloser, error) {
+	return wsh.ws.NextWriter(websocket.BinaryMessage)
+}
+
+func (wsh *defWSHandler) NextReader() (io.Reader, error) {
+	_, r, err := wsh.ws.NextReader()
+	return r, err
+}
+
+// The ws isn't nil'ed out as Close may be called (upon context
+// cancellation) while another Go routine is reading or writing
+func (wsh *defWSHandler) Close() { wsh.ws.Close() }
+
+// ConnectError is returned when the websocket can't be established. If
+// the server responded to the handshake (e.g. a load balancer redirecting
+// or an auth proxy rejecting it) StatusCode etc. are set. Otherwise Err
+// is the dial or TLS error (e.g. a *tls.CertificateVerificationError).
+type ConnectError struct {
+	URL        string
+	StatusCode int
+	Status     string
+	Header     http.Header
+	Body       string // The start of it
+	Err        error
+}
+
+func (e *ConnectError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("Unable to connect to %s: %s", e.URL, e.Err)
+	}
+	msg := fmt.Sprintf("Unable to connect to %s: %s (HTTP %s)", e.URL, e.Err, e.Status)
+	if loc := e.Header.Get("Location"); loc != "" {
+		msg += " redirected to " + loc
+	}
+	return msg
+}
+
+func (e *ConnectError) Unwrap() error { return e.Err }
+
+const connectErrorBodyMax = 512
+
+func newConnectError(u url.URL, resp *http.Response, err error) *ConnectError {
+	ce := &ConnectError{URL: u.String(), Err: err}
+	if resp != nil {
+		ce.StatusCode = resp.StatusCode
+		ce.Status = resp.Status
+		ce.Header = resp.Header
+		if resp.Body != nil {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, connectErrorBodyMax))
+			resp.Body.Close()
+			ce.Body = string(body)
+		}
+	}
+	return ce
+}
+
+// This wraps another handler teeing the JSON frames to a writer
+// (see ConnConf.WireLog) with any credentials redacted
+
+type tapWSHandler struct {
+	WSHandler
+	w   io.Writer
+	mux sync.Mutex
+}
+
+var redactPasswordRE = regexp.MustCompile(`("password"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+var redactIdentifiedByRE = regexp.MustCompile(`(?i)((?:IDENTIFIED\s+BY|REPLACE)\s+)(\\"(?:[^"\\]|\\[^"]|\\"\\")*\\"|'(?:[^']|'')*')`)
+
+func (wsh *tapWSHandler) WriteJSON(req interface{}) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	wsh.tap("->", b)
+	return wsh.WSHandler.WriteJSON(json.RawMessage(b))
+}
+
+func (wsh *tapWSHandler) ReadJSON(resp interface{}) error {
+	var raw json.RawMessage
+	err := wsh.WSHandler.ReadJSON(&raw)
+	if err != nil {
+		return err
+	}
+	wsh.tap("<-", raw)
+	return decodeJSON(bytes.NewReader(raw), resp)
+}
+
+func (wsh *tapWSHandler) tap(direction string, frame []byte) {
+	frame = redactPasswordRE.ReplaceAll(frame, []byte(`$1"<redacted>"`))
+	frame = redactIdentifiedByRE.ReplaceAll(frame, []byte(`$1<redacted>`))
+	wsh.mux.Lock()
+	defer wsh.mux.Unlock()
+	fmt.Fprintf(wsh.w, "%s %s %s\n", time.Now().Format(time.RFC3339Nano), direction, frame)
+}
+
+// This wraps another handler failing responses larger than the limit
+// (see ConnConf.MaxResponseSize) rather than reading them into memory
+
+type limitWSHandler struct {
+	FrameWSHandler
+	limit int64
+}
+
+var errResponseTooLarge = errors.New("Response too large")
+
+func (wsh *limitWSHandler) ReadJSON(resp interface{}) error {
+	r, err := wsh.NextReader()
+	if err != nil {
+		return err
+	}
+	lr := &io.LimitedReader{R: r, N: wsh.limit + 1}
+	err = decodeJSON(lr, resp)
+	if lr.N == 0 {
+		// Discard the rest of it so that the connection remains usable
+		if _, err = io.Copy(io.Discard, r); err != nil {
+			return err
+		}
+		return errResponseTooLarge
+	}
+	return err
+}
+
+// Decodes the response with its numbers as json.Number if it needs them
+// for exact conversions (see Conn.decodeData) or as float64s otherwise
+func decodeJSON(r io.Reader, resp interface{}) error {
+	dec := json.NewDecoder(r)
+	if n, ok := resp.(interface{ useNumber() bool }); ok && n.useNumber() {
+		dec.UseNumber()
+	}
+	return dec.Decode(resp)
 }
@@ -0,0 +1,180 @@
+/*
+	Helpers for workload management i.e. consumer groups (Exasol 7.0+)
+	and session resource limits
+
+    AUTHOR
+
+	Grant Street Group <developers@grantstreet.com>
+
+	COPYRIGHT AND LICENSE
+
+	This software is Copyright (c) 2019 by Grant Street Group.
+	This is free software, licensed under:
+	    MIT License
+*/
+
+package exasol
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*--- Public Interface ---*/
+
+// ConsumerGroup is a row of EXA_CONSUMER_GROUPS. When creating/altering
+// groups nil fields are left unset. RAM limits are strings like '10G'.
+type ConsumerGroup struct {
+	Name                  string `exasol:"consumer_group_name"`
+	Precedence            *int64
+	CPUWeight             *int64
+	GroupTempDBRAMLimit   *string
+	UserTempDBRAMLimit    *string
+	SessionTempDBRAMLimit *string
+	QueryTimeout          *int64 // Seconds
+	IdleTimeout           *int64 // Seconds
+}
+
+// SessionResources is the workload related info about a session
+// from EXA_ALL_SESSIONS (TEMP_DB_RAM etc. are in MiB)
+type SessionResources struct {
+	UserName        string
+	Status          string
+	ConsumerGroup   *string // Exasol 7.0+
+	Priority        *string // Before Exasol 7.0
+	Nice            *bool
+	Resources       *float64 // Percent of the resources in use
+	TempDBRAM       *float64
+	PersistentDBRAM *float64
+	QueryTimeout    *int64
+}
+
+func (c *Conn) ConsumerGroups() ([]ConsumerGroup, error) {
+	if err := c.requireConsumerGroups(); err != nil {
+		return nil, err
+	}
+	return collect(QueryStruct[ConsumerGroup](c, `
+		SELECT consumer_group_name, precedence, cpu_weight, group_temp_db_ram_limit,
+			user_temp_db_ram_limit, session_temp_db_ram_limit, query_timeout, idle_timeout
+		FROM exa_consumer_groups ORDER BY consumer_group_name
+	`))
+}
+
+func (c *Conn) CreateConsumerGroup(group ConsumerGroup) error {
+	if err := c.requireConsumerGroups(); err != nil {
+		return err
+	}
+	sql := "CREATE CONSUMER GROUP " + c.QuoteIdent(group.Name)
+	if settings := group.settings(); settings != "" {
+		sql += " WITH " + settings
+	}
+	_, err := c.Execute(sql)
+	return err
+}
+
+// AlterConsumerGroup sets the group's non-nil limits
+func (c *Conn) AlterConsumerGroup(group ConsumerGroup) error {
+	if err := c.requireConsumerGroups(); err != nil {
+		return err
+	}
+	settings := group.settings()
+	if settings == "" {
+		return c.error("AlterConsumerGroup requires at least one setting")
+	}
+	_, err := c.Execute("ALTER CONSUMER GROUP " + c.QuoteIdent(group.Name) + " SET " + settings)
+	return err
+}
+
+func (c *Conn) DropConsumerGroup(name string) error {
+	if err := c.requireConsumerGroups(); err != nil {
+		return err
+	}
+	_, err := c.Execute("DROP CONSUMER GROUP " + c.QuoteIdent(name))
+	return err
+}
+
+// SetConsumerGroup sets the consumer group for the current session
+func (c *Conn) SetConsumerGroup(group string) error {
+	if err := c.requireConsumerGroups(); err != nil {
+		return err
+	}
+	_, err := c.Execute("ALTER SESSION SET CONSUMER_GROUP = " + c.QuoteIdent(group))
+	return err
+}
+
+// SetUserConsumerGroup sets the default consumer group for a user's sessions
+func (c *Conn) SetUserConsumerGroup(user, group string) error {
+	if err := c.requireConsumerGroups(); err != nil {
+		return err
+	}
+	_, err := c.Execute(fmt.Sprintf(
+		"ALTER USER %s SET CONSUMER_GROUP = %s", c.QuoteIdent(user), c.QuoteIdent(group),
+	))
+	return err
+}
+
+// SetNice lowers the priority of the current session (relative to
+// the other sessions in its consumer/priority group)
+func (c *Conn) SetNice(nice bool) error {
+	val := "OFF"
+	if nice {
+		val = "ON"
+	}
+	_, err := c.Execute("ALTER SESSION SET NICE = '" + val + "'")
+	return err
+}
+
+// SessionResources returns the workload info for the given
+// session or the current session if sessionID is 0
+func (c *Conn) SessionResources(sessionID uint64) (*SessionResources, error) {
+	where := "session_id = CURRENT_SESSION"
+	var binds []interface{}
+	if sessionID != 0 {
+		// Compared as strings as session ids don't fit in a float64
+		where = "CAST(session_id AS VARCHAR(20)) = ?"
+		binds = []interface{}{fmt.Sprint(sessionID)}
+	}
+	rows, err := collect(QueryStruct[SessionResources](c,
+		"SELECT * FROM exa_all_sessions WHERE "+where, binds,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, c.errorf("Unable to get session resources: %w", ErrNoRows)
+	}
+	return &rows[0], nil
+}
+
+/*--- Private Routines ---*/
+
+func (c *Conn) requireConsumerGroups() error {
+	if !c.ReleaseAtLeast("7.0") {
+		return c.error("Consumer groups require Exasol 7.0 or later")
+	}
+	return nil
+}
+
+func (g ConsumerGroup) settings() string {
+	var s []string
+	add := func(name string, val interface{}) {
+		switch v := val.(type) {
+		case *int64:
+			if v != nil {
+				s = append(s, fmt.Sprintf("%s = %d", name, *v))
+			}
+		case *string:
+			if v != nil {
+				s = append(s, fmt.Sprintf("%s = '%s'", name, QuoteStr(*v)))
+			}
+		}
+	}
+	add("PRECEDENCE", g.Precedence)
+	add("CPU_WEIGHT", g.CPUWeight)
+	add("GROUP_TEMP_DB_RAM_LIMIT", g.GroupTempDBRAMLimit)
+	add("USER_TEMP_DB_RAM_LIMIT", g.UserTempDBRAMLimit)
+	add("SESSION_TEMP_DB_RAM_LIMIT", g.SessionTempDBRAMLimit)
+	add("QUERY_TIMEOUT", g.QueryTimeout)
+	add("IDLE_TIMEOUT", g.IdleTimeout)
+	return strings.Join(s, ", ")
+}
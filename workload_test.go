@@ -0,0 +1,54 @@
+package exasol
+
+func (s *testSuite) TestWorkload() {
+	exa := s.exaConn
+	res, err := exa.SessionResources(0)
+	if s.NoError(err) {
+		s.Equal("SYS", res.UserName)
+	}
+	other, err := exa.SessionResources(exa.SessionID)
+	if s.NoError(err) {
+		s.Equal(res.UserName, other.UserName, "Same session by id")
+	}
+
+	s.NoError(exa.SetNice(true))
+	res, err = exa.SessionResources(0)
+	if s.NoError(err) && s.NotNil(res.Nice) {
+		s.True(*res.Nice)
+	}
+	s.NoError(exa.SetNice(false))
+
+	exa.Conf.SuppressError = true
+	if !exa.ReleaseAtLeast("7.0") {
+		s.Error(exa.SetConsumerGroup("foo"), "Unsupported")
+		return
+	}
+	exa.DropConsumerGroup("test_group")
+	defer exa.DropConsumerGroup("test_group")
+	weight, limit := int64(123), "1G"
+	s.NoError(exa.CreateConsumerGroup(ConsumerGroup{
+		Name: "test_group", CPUWeight: &weight, SessionTempDBRAMLimit: &limit,
+	}))
+	weight = 321
+	s.NoError(exa.AlterConsumerGroup(ConsumerGroup{Name: "test_group", CPUWeight: &weight}))
+	s.Error(exa.AlterConsumerGroup(ConsumerGroup{Name: "test_group"}), "Nothing to set")
+
+	groups, err := exa.ConsumerGroups()
+	s.NoError(err)
+	var found *ConsumerGroup
+	for i := range groups {
+		if groups[i].Name == "TEST_GROUP" {
+			found = &groups[i]
+		}
+	}
+	if s.NotNil(found) {
+		s.Equal(int64(321), *found.CPUWeight)
+	}
+
+	s.NoError(exa.SetConsumerGroup("test_group"))
+	res, err = exa.SessionResources(0)
+	if s.NoError(err) && s.NotNil(res.ConsumerGroup) {
+		s.Equal("TEST_GROUP", *res.ConsumerGroup)
+	}
+	s.NoError(exa.SetConsumerGroup("medium"))
+}